@@ -2,6 +2,7 @@ package main
 
 import (
 	"flag"
+	"io"
 	"os"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/dokzlo13/lightd/internal/app"
+	"github.com/dokzlo13/lightd/internal/buildinfo"
 	"github.com/dokzlo13/lightd/internal/config"
 )
 
@@ -18,6 +20,9 @@ func main() {
 	flag.StringVar(&configPath, "config", "config.yaml", "Path to configuration file")
 	flag.StringVar(&configPath, "c", "config.yaml", "Path to configuration file (shorthand)")
 	resetState := flag.Bool("reset-state", false, "Clear stored desired state (bank scenes) on startup")
+	clearGeocache := flag.Bool("clear-geocache", false, "Clear cached geocoded locations on startup")
+	resetStreamStats := flag.Bool("reset-stream-stats", false, "Reset persisted event stream counters on startup")
+	scriptPath := flag.String("script", "", "Path to Lua script, overriding the config; \"-\" reads it from stdin")
 	flag.Parse()
 
 	// Load configuration
@@ -26,10 +31,30 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
+	// Handle script flag: a path replaces script, "-" replaces both with stdin
+	switch *scriptPath {
+	case "":
+	case "-":
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to read Lua script from stdin")
+		}
+		cfg.Script = ""
+		cfg.ScriptInline = string(source)
+	default:
+		cfg.Script = *scriptPath
+		cfg.ScriptInline = ""
+	}
+
 	// Setup logging
 	setupLogging(cfg.Log.GetLevel(), cfg.Log.UseJSON, cfg.Log.Colors)
 
-	log.Info().Str("config", configPath).Msg("Starting lightd")
+	log.Info().
+		Str("config", configPath).
+		Str("script", cfg.ScriptName()).
+		Str("version", buildinfo.Version).
+		Str("commit", buildinfo.GetCommit()).
+		Msg("Starting lightd")
 
 	// Create application
 	application, err := app.New(cfg)
@@ -45,6 +70,25 @@ func main() {
 		}
 	}
 
+	// Handle clear geocache flag
+	if *clearGeocache {
+		removed, err := application.ClearGeocache()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to clear geocache")
+		} else {
+			log.Info().Int64("removed", removed).Msg("Cleared geocache (--clear-geocache)")
+		}
+	}
+
+	// Handle reset stream stats flag
+	if *resetStreamStats {
+		if err := application.ResetStreamStats(); err != nil {
+			log.Warn().Err(err).Msg("Failed to reset event stream stats")
+		} else {
+			log.Info().Msg("Reset event stream stats (--reset-stream-stats)")
+		}
+	}
+
 	// Create context that cancels on shutdown signal
 	ctx := app.SignalContext()
 
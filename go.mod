@@ -3,17 +3,17 @@ module github.com/dokzlo13/lightd
 go 1.24.0
 
 require (
+	github.com/amimof/huego v1.2.1
 	github.com/google/uuid v1.6.0
 	github.com/mattn/go-sqlite3 v1.14.24
 	github.com/rs/zerolog v1.33.0
 	github.com/yuin/gopher-lua v1.1.1
+	golang.org/x/time v0.14.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/amimof/huego v1.2.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.28.0 // indirect
-	golang.org/x/time v0.14.0 // indirect
 )
@@ -78,20 +78,41 @@ func (c *Context) RunAction(name string, args map[string]any) error {
 
 // --- Convenience methods for common operations ---
 
-// SetPower sets the desired power state for a group
-func (c *Context) SetPower(groupID string, on bool) error {
+// DesiredChange modifies a group's desired state in place
+type DesiredChange func(d *group.Desired)
+
+// WithPower sets the desired power state
+func WithPower(on bool) DesiredChange {
+	return func(d *group.Desired) { d.Power = &on }
+}
+
+// WithScene sets the desired scene
+func WithScene(sceneName string) DesiredChange {
+	return func(d *group.Desired) { d.SceneName = sceneName }
+}
+
+// UpdateDesired applies all changes to a group's desired state in a single
+// store update, so e.g. "scene then power" bumps the version once and
+// triggers one reconcile pass instead of two.
+func (c *Context) UpdateDesired(groupID string, changes ...DesiredChange) error {
 	return c.desired.Update(groupID, func(current group.Desired) group.Desired {
-		current.Power = &on
+		for _, change := range changes {
+			change(&current)
+		}
 		return current
 	})
 }
 
-// SetScene sets the desired scene for a group
+// SetPower sets the desired power state for a group.
+// Use UpdateDesired to combine it with other changes.
+func (c *Context) SetPower(groupID string, on bool) error {
+	return c.UpdateDesired(groupID, WithPower(on))
+}
+
+// SetScene sets the desired scene for a group.
+// Use UpdateDesired to combine it with other changes.
 func (c *Context) SetScene(groupID string, sceneName string) error {
-	return c.desired.Update(groupID, func(current group.Desired) group.Desired {
-		current.SceneName = sceneName
-		return current
-	})
+	return c.UpdateDesired(groupID, WithScene(sceneName))
 }
 
 // GetDesiredState returns the current desired state for a group
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+func newTestContext(t *testing.T) (*Context, *storage.TypedStore[group.Desired]) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"), time.Second)
+	if err != nil {
+		t.Fatalf("storage.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	desired := storage.NewTypedStore[group.Desired](storage.NewStore(db.DB), "group")
+	return NewContext(context.Background(), nil, desired, nil, nil), desired
+}
+
+func TestContext_UpdateDesiredBumpsVersionOnce(t *testing.T) {
+	c, desired := newTestContext(t)
+
+	if err := c.UpdateDesired("1", WithScene("Relax"), WithPower(true)); err != nil {
+		t.Fatalf("UpdateDesired: %v", err)
+	}
+
+	state, version, err := desired.Get("1")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if version != 1 {
+		t.Errorf("version = %d, want 1 after a combined scene and power update", version)
+	}
+	if state.SceneName != "Relax" || state.Power == nil || !*state.Power {
+		t.Errorf("state = %+v, want scene Relax and power on", state)
+	}
+
+	// The single-field helpers go through the same path: one bump each
+	if err := c.SetPower("1", false); err != nil {
+		t.Fatalf("SetPower: %v", err)
+	}
+	if _, version, _ := desired.Get("1"); version != 2 {
+		t.Errorf("version after SetPower = %d, want 2", version)
+	}
+}
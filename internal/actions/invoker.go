@@ -3,28 +3,65 @@ package actions
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/dokzlo13/lightd/internal/events"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
 
+// SourceActionFailed is the invocation source of action_failed handlers.
+// Their own failures are not re-published, so a failing handler cannot loop.
+const SourceActionFailed = "action_failed"
+
+// sourceContextKey is the key used to store the invocation source in Go's context.Context
+type sourceContextKey struct{}
+
+// WithSource returns a copy of ctx carrying the invocation source
+// (e.g. "scheduler", "boot_recovery", "run_closest").
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey{}, source)
+}
+
+// SourceFromContext returns the invocation source stored in ctx, or "" if none.
+func SourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(sourceContextKey{}).(string)
+	return source
+}
+
 // Invoker executes actions with deduplication
 type Invoker struct {
-	registry   *Registry
-	ledger     *storage.Ledger
-	ctxFactory func(ctx context.Context) *Context
+	registry    *Registry
+	ledger      *storage.Ledger
+	suppression *Suppression
+	ctxFactory  func(ctx context.Context) *Context
+	bus         *events.Bus // receives action_failed events; nil disables them
+	kvLookup    KVLookup    // resolves ${kv:bucket/key} args; nil leaves them as is
 }
 
 // NewInvoker creates a new action invoker
-func NewInvoker(registry *Registry, l *storage.Ledger, ctxFactory func(ctx context.Context) *Context) *Invoker {
+func NewInvoker(registry *Registry, l *storage.Ledger, suppression *Suppression, bus *events.Bus, ctxFactory func(ctx context.Context) *Context) *Invoker {
 	return &Invoker{
-		registry:   registry,
-		ledger:     l,
-		ctxFactory: ctxFactory,
+		registry:    registry,
+		ledger:      l,
+		suppression: suppression,
+		ctxFactory:  ctxFactory,
+		bus:         bus,
 	}
 }
 
+// SetKVLookup enables ${kv:bucket/key} placeholders in action args,
+// resolved against lookup at invocation time.
+func (i *Invoker) SetKVLookup(lookup KVLookup) {
+	i.kvLookup = lookup
+}
+
+// Suppression returns the "do not disturb" window applied to automated invocations
+func (i *Invoker) Suppression() *Suppression {
+	return i.suppression
+}
+
 // Invoke executes an action with the given idempotency key
 // - For schedules: idempotencyKey = occurrence_id ("scene:dawn/1735372800")
 // - For buttons: idempotencyKey = button_event_id (from Hue SSE)
@@ -61,6 +98,36 @@ func (i *Invoker) invoke(ctx context.Context, actionName string, args map[string
 		return fmt.Errorf("action %q not found", actionName)
 	}
 
+	// Trace ID of the triggering event, carried into logs, ledger and action_failed
+	traceID := events.TraceIDFromContext(ctx)
+
+	// Skip automated invocations during a suppression window
+	if i.suppression != nil && IsSuppressible(source) && i.suppression.Active(time.Now()) {
+		until, _ := i.suppression.Until()
+		log.Info().
+			Str("action", actionName).
+			Str("source", source).
+			Time("until", until).
+			Str("trace_id", traceID).
+			Msg("Action suppressed")
+		i.appendLedger(storage.EventActionSuppressed, idempotencyKey, source, defID, withTraceID(map[string]any{
+			"action": actionName,
+			"until":  until.Unix(),
+		}, traceID))
+		return nil
+	}
+
+	// Resolve ${kv:bucket/key} placeholders against current KV values
+	args, err := expandKVArgs(args, i.kvLookup)
+	if err != nil {
+		err = fmt.Errorf("action %q args: %w", actionName, err)
+		i.recordFailure(actionName, idempotencyKey, source, defID, traceID, err)
+		return err
+	}
+
+	if source != "" {
+		ctx = WithSource(ctx, source)
+	}
 	actx := i.ctxFactory(ctx)
 
 	// Execute action
@@ -71,33 +138,69 @@ func (i *Invoker) invoke(ctx context.Context, actionName string, args map[string
 	if defID != "" {
 		logEvent = logEvent.Str("def_id", defID)
 	}
+	if traceID != "" {
+		logEvent = logEvent.Str("trace_id", traceID)
+	}
 	if len(args) > 0 {
 		logEvent = logEvent.Interface("args", args)
 	}
 	logEvent.Msg("Executing action")
 
-	err := action.Execute(actx, args)
+	start := time.Now()
+	err = action.Execute(actx, args)
+	elapsed := time.Since(start)
 
 	// Log completion or failure
 	if err != nil {
-		if idempotencyKey != "" {
-			i.appendLedger(storage.EventActionFailed, idempotencyKey, source, defID, map[string]any{
-				"action": actionName,
-				"error":  err.Error(),
-			})
-		}
+		i.recordFailure(actionName, idempotencyKey, source, defID, traceID, err)
 		return err
 	}
 
 	if idempotencyKey != "" {
-		i.appendLedger(storage.EventActionCompleted, idempotencyKey, source, defID, map[string]any{
-			"action": actionName,
-		})
+		i.appendLedger(storage.EventActionCompleted, idempotencyKey, source, defID, withTraceID(map[string]any{
+			"action":      actionName,
+			"duration_ms": elapsed.Milliseconds(),
+		}, traceID))
 	}
 
 	return nil
 }
 
+// recordFailure logs a failed invocation to the ledger and publishes it.
+func (i *Invoker) recordFailure(actionName, idempotencyKey, source, defID, traceID string, err error) {
+	if idempotencyKey != "" {
+		i.appendLedger(storage.EventActionFailed, idempotencyKey, source, defID, withTraceID(map[string]any{
+			"action": actionName,
+			"error":  err.Error(),
+		}, traceID))
+	}
+	i.publishFailure(actionName, source, traceID, err)
+}
+
+// publishFailure emits an action_failed event under the failed action's
+// trace ID, except for failures of action_failed handlers themselves.
+func (i *Invoker) publishFailure(actionName, source, traceID string, err error) {
+	if i.bus == nil || source == SourceActionFailed {
+		return
+	}
+	i.bus.Publish(events.Event{
+		Type: events.EventTypeActionFailed,
+		Data: withTraceID(map[string]interface{}{
+			"action": actionName,
+			"error":  err.Error(),
+			"source": source,
+		}, traceID),
+	})
+}
+
+// withTraceID adds the trace ID to a ledger payload or event data, if set.
+func withTraceID(data map[string]any, traceID string) map[string]any {
+	if traceID != "" {
+		data[events.TraceIDKey] = traceID
+	}
+	return data
+}
+
 // appendLedger appends to ledger, using source/defID if provided
 func (i *Invoker) appendLedger(eventType storage.EventType, idempotencyKey, source, defID string, payload map[string]any) error {
 	if source != "" || defID != "" {
@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// KVLookup returns the value stored under key in bucket, or nil if unset.
+type KVLookup func(bucket, key string) (any, error)
+
+// kvPlaceholder matches ${kv:bucket/key}; the key may itself contain slashes.
+var kvPlaceholder = regexp.MustCompile(`\$\{kv:([^/}]+)/([^}]+)\}`)
+
+// expandKVArgs returns args with ${kv:bucket/key} placeholders in string
+// values (including nested tables) replaced by the stored values. A string
+// that is exactly one placeholder takes the stored value as is, so numbers
+// and tables keep their type; placeholders inside longer strings are
+// formatted into the text. The input map is never modified.
+func expandKVArgs(args map[string]any, lookup KVLookup) (map[string]any, error) {
+	if lookup == nil || !hasKVPlaceholder(args) {
+		return args, nil
+	}
+	expanded, err := expandKVValue(args, lookup)
+	if err != nil {
+		return nil, err
+	}
+	return expanded.(map[string]any), nil
+}
+
+func expandKVValue(v any, lookup KVLookup) (any, error) {
+	switch val := v.(type) {
+	case string:
+		return expandKVString(val, lookup)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			expanded, err := expandKVValue(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = expanded
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			expanded, err := expandKVValue(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = expanded
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func expandKVString(s string, lookup KVLookup) (any, error) {
+	if !strings.Contains(s, "${kv:") {
+		return s, nil
+	}
+
+	// Whole-string placeholder: keep the stored type
+	if m := kvPlaceholder.FindStringSubmatch(s); m != nil && m[0] == s {
+		return lookupKV(lookup, m[1], m[2])
+	}
+
+	var lookupErr error
+	out := kvPlaceholder.ReplaceAllStringFunc(s, func(match string) string {
+		m := kvPlaceholder.FindStringSubmatch(match)
+		value, err := lookupKV(lookup, m[1], m[2])
+		if err != nil {
+			if lookupErr == nil {
+				lookupErr = err
+			}
+			return match
+		}
+		return fmt.Sprint(value)
+	})
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	return out, nil
+}
+
+// lookupKV fetches a placeholder value; unset keys are an error so the
+// action doesn't run against a missing target.
+func lookupKV(lookup KVLookup, bucket, key string) (any, error) {
+	value, err := lookup(bucket, key)
+	if err != nil {
+		return nil, fmt.Errorf("kv %s/%s: %w", bucket, key, err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("kv %s/%s is not set", bucket, key)
+	}
+	return value, nil
+}
+
+// hasKVPlaceholder reports whether any string in v contains a placeholder.
+func hasKVPlaceholder(v any) bool {
+	switch val := v.(type) {
+	case string:
+		return strings.Contains(val, "${kv:")
+	case map[string]any:
+		for _, item := range val {
+			if hasKVPlaceholder(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range val {
+			if hasKVPlaceholder(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
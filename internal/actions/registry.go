@@ -2,6 +2,7 @@ package actions
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -11,6 +12,13 @@ type Action interface {
 	Execute(ctx *Context, args map[string]any) error
 }
 
+// StatefulAction is optionally implemented by actions that keep state
+// between invocations. Used for introspection only.
+type StatefulAction interface {
+	Action
+	Stateful() bool
+}
+
 // SimpleAction is the standard action implementation
 type SimpleAction struct {
 	name string
@@ -73,3 +81,22 @@ func (r *Registry) Names() []string {
 	}
 	return names
 }
+
+// List returns all registered action names, sorted
+func (r *Registry) List() []string {
+	names := r.Names()
+	sort.Strings(names)
+	return names
+}
+
+// Describe reports whether an action is registered and whether it is stateful
+func (r *Registry) Describe(name string) (stateful bool, ok bool) {
+	action, ok := r.Get(name)
+	if !ok {
+		return false, false
+	}
+	if sa, isStateful := action.(StatefulAction); isStateful {
+		return sa.Stateful(), true
+	}
+	return false, true
+}
@@ -0,0 +1,50 @@
+package actions
+
+import (
+	"slices"
+	"testing"
+)
+
+// counterAction is a stateful test action
+type counterAction struct{ calls int }
+
+func (a *counterAction) Name() string   { return "counter" }
+func (a *counterAction) Stateful() bool { return true }
+
+func (a *counterAction) Execute(ctx *Context, args map[string]any) error {
+	a.calls++
+	return nil
+}
+
+func TestRegistry_ListAndDescribe(t *testing.T) {
+	r := NewRegistry()
+	noop := func(ctx *Context, args map[string]any) error { return nil }
+	for _, name := range []string{"zeta", "alpha", "mid"} {
+		if err := r.RegisterSimple(name, noop); err != nil {
+			t.Fatalf("RegisterSimple(%q): %v", name, err)
+		}
+	}
+	if err := r.Register(&counterAction{}); err != nil {
+		t.Fatalf("Register(counter): %v", err)
+	}
+
+	if got, want := r.List(), []string{"alpha", "counter", "mid", "zeta"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name         string
+		wantStateful bool
+		wantOK       bool
+	}{
+		{"alpha", false, true},
+		{"counter", true, true},
+		{"missing", false, false},
+	}
+	for _, tt := range tests {
+		stateful, ok := r.Describe(tt.name)
+		if stateful != tt.wantStateful || ok != tt.wantOK {
+			t.Errorf("Describe(%q) = (%v, %v), want (%v, %v)", tt.name, stateful, ok, tt.wantStateful, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// Store location of the suppression window
+const (
+	suppressionKind = "automation"
+	suppressionID   = "suppress_until"
+)
+
+// suppressibleSources are the invocation sources skipped while a suppression
+// window is active. Manual runs, webhooks, buttons and rotary dials are never
+// suppressed.
+var suppressibleSources = map[string]bool{
+	"scheduler":     true,
+	"boot_recovery": true,
+	"connectivity":  true,
+	"light_change":  true,
+}
+
+// IsSuppressible reports whether actions invoked from source are skipped
+// while a suppression window is active.
+func IsSuppressible(source string) bool {
+	return suppressibleSources[source]
+}
+
+// Suppression is a "do not disturb" window during which automated
+// invocations are skipped. The window is persisted in the state store so it
+// survives restarts.
+type Suppression struct {
+	store *storage.Store
+
+	mu    sync.RWMutex
+	until time.Time
+}
+
+// NewSuppression creates a suppression window, restoring a persisted one if present.
+func NewSuppression(store *storage.Store) *Suppression {
+	s := &Suppression{store: store}
+
+	payload, _, err := store.Get(suppressionKind, suppressionID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load suppression window")
+		return s
+	}
+	if payload == nil {
+		return s
+	}
+
+	var until time.Time
+	if err := json.Unmarshal(payload, &until); err != nil {
+		log.Warn().Err(err).Msg("Invalid persisted suppression window, ignoring")
+		return s
+	}
+	s.until = until
+
+	if s.Active(time.Now()) {
+		log.Info().Time("until", until).Msg("Automation suppression restored")
+	}
+	return s
+}
+
+// Suppress starts (or replaces) a window lasting d from now.
+// Returns the time the window ends.
+func (s *Suppression) Suppress(d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, fmt.Errorf("suppression duration must be positive")
+	}
+	until := time.Now().Add(d)
+	if err := s.set(until); err != nil {
+		return time.Time{}, err
+	}
+	log.Info().Time("until", until).Msg("Automations suppressed")
+	return until, nil
+}
+
+// Resume ends the current window early.
+func (s *Suppression) Resume() error {
+	if err := s.set(time.Time{}); err != nil {
+		return err
+	}
+	log.Info().Msg("Automation suppression cleared")
+	return nil
+}
+
+// Until returns the end of the window and whether it is still active.
+func (s *Suppression) Until() (time.Time, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.until, time.Now().Before(s.until)
+}
+
+// Active reports whether the window covers now.
+func (s *Suppression) Active(now time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return now.Before(s.until)
+}
+
+func (s *Suppression) set(until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payload, err := json.Marshal(until)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Set(suppressionKind, suppressionID, payload); err != nil {
+		return fmt.Errorf("failed to persist suppression window: %w", err)
+	}
+	s.until = until
+	return nil
+}
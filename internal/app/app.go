@@ -83,6 +83,24 @@ func (a *App) ClearDesiredState() error {
 	return nil
 }
 
+// ClearGeocache forgets all geocoded locations so they are resolved again.
+// This is useful with the --clear-geocache flag after moving.
+func (a *App) ClearGeocache() (int64, error) {
+	if a.services != nil {
+		return a.services.GeoCalc.ClearCachedLocation("")
+	}
+	return 0, nil
+}
+
+// ResetStreamStats zeroes the persisted event stream counters.
+// This is useful with the --reset-stream-stats flag.
+func (a *App) ResetStreamStats() error {
+	if a.services != nil {
+		return a.services.Hue.ResetStreamStats()
+	}
+	return nil
+}
+
 // SignalContext creates a context that is cancelled when SIGINT or SIGTERM is received.
 func SignalContext() context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DashboardState reports, in one table, the current mode, the next scheduled
+// occurrence per tag, desired vs actual state per group and the event stream
+// status. It only reads cached state and never contacts the bridge, so it is
+// cheap enough to serve on every dashboard refresh (see system.dashboard_state).
+// The result holds only JSON types.
+func (s *Services) DashboardState() map[string]any {
+	resp := map[string]any{
+		"mode": s.Modes.Get(),
+		"reconciler": map[string]any{
+			"paused": s.Hue.Orchestrator.Paused(),
+		},
+		"groups":       s.dashboardGroups(),
+		"event_stream": s.dashboardStream(),
+	}
+	if s.Scheduler.Scheduler != nil {
+		resp["next_by_tag"] = s.Scheduler.Scheduler.NextByTag(time.Now())
+	}
+	if fetched := s.Hue.GroupProvider.ActualProvider().LastFetched(); !fetched.IsZero() {
+		resp["actual_at"] = fetched.Format(time.RFC3339)
+	}
+
+	// Round-trip through JSON so callers (Lua) only see plain maps, slices,
+	// strings, numbers and booleans
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode dashboard state")
+		return map[string]any{}
+	}
+	var plain map[string]any
+	if err := json.Unmarshal(data, &plain); err != nil {
+		log.Error().Err(err).Msg("Failed to decode dashboard state")
+		return map[string]any{}
+	}
+	return plain
+}
+
+// dashboardGroups pairs each group's desired state with its last known
+// actual state. Groups not seen on the bridge yet have no "actual".
+func (s *Services) dashboardGroups() []map[string]any {
+	groups := []map[string]any{}
+
+	desired, _, err := s.Hue.Stores.Groups().GetAll()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load desired group state")
+		return groups
+	}
+
+	ids := make([]string, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	actualProvider := s.Hue.GroupProvider.ActualProvider()
+	for _, id := range ids {
+		entry := map[string]any{
+			"id":      id,
+			"desired": desired[id],
+		}
+		if actual, ok := actualProvider.Cached(id); ok {
+			entry["actual"] = map[string]any{
+				"any_on":    actual.AnyOn,
+				"all_on":    actual.AllOn,
+				"bri":       actual.Bri,
+				"reachable": actual.Reachable,
+			}
+		}
+		groups = append(groups, entry)
+	}
+	return groups
+}
+
+// dashboardStream reports whether the event stream is connected and when
+// it last received data.
+func (s *Services) dashboardStream() map[string]any {
+	stream := map[string]any{
+		"enabled":   s.cfg.Events.SSE.IsEnabled(),
+		"connected": false,
+	}
+	if since := s.Hue.EventStream.ConnectedSince(); !since.IsZero() {
+		stream["connected"] = true
+		stream["connected_since"] = since.Format(time.RFC3339)
+	}
+	if last := s.Hue.EventStream.LastActivity(); !last.IsZero() {
+		stream["last_activity"] = last.Format(time.RFC3339)
+	}
+	return stream
+}
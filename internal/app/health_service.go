@@ -2,27 +2,61 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/dokzlo13/lightd/internal/buildinfo"
 	"github.com/dokzlo13/lightd/internal/config"
+	"github.com/dokzlo13/lightd/internal/geo"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+	"github.com/dokzlo13/lightd/internal/mode"
+	"github.com/dokzlo13/lightd/internal/scheduler"
+	"github.com/dokzlo13/lightd/internal/webhook"
 )
 
 // HealthService provides HTTP health check endpoints.
 type HealthService struct {
 	cfg    *config.Config
+	hue    *HueService
+	modes  *mode.Manager
+	sched  *scheduler.Scheduler // nil when the scheduler is disabled
+	geo    *geo.Calculator
 	server *http.Server
+
+	// Webhook handlers sharing this server's listener (nil = separate server)
+	webhook *webhook.Server
+
+	// Cached bridge probe result (see bridgeStatus)
+	probeMu     sync.Mutex
+	probeAt     time.Time
+	probeErr    error
+	probeSource string
 }
 
 // NewHealthService creates a new HealthService.
-func NewHealthService(cfg *config.Config) *HealthService {
+func NewHealthService(cfg *config.Config, hue *HueService, modes *mode.Manager, sched *scheduler.Scheduler, geoCalc *geo.Calculator) *HealthService {
 	return &HealthService{
-		cfg: cfg,
+		cfg:   cfg,
+		hue:   hue,
+		modes: modes,
+		sched: sched,
+		geo:   geoCalc,
 	}
 }
 
+// MountWebhook serves the webhook handlers on this server's mux. Health
+// endpoints take precedence over webhook paths. Must be called before Start.
+func (s *HealthService) MountWebhook(server *webhook.Server) {
+	s.webhook = server
+}
+
 // Start begins the health check server if enabled.
 func (s *HealthService) Start(ctx context.Context) {
 	if !s.cfg.Healthcheck.Enabled {
@@ -51,12 +85,38 @@ func (s *HealthService) run(ctx context.Context) {
 		w.Write([]byte(`{"status":"ready"}`))
 	})
 
+	// Liveness endpoint with a real bridge reachability check
+	mux.HandleFunc("/healthz", s.handleHealthz)
+
+	// Build/process info endpoint
+	mux.HandleFunc("/info", s.handleInfo)
+
+	// Runtime state endpoint
+	mux.HandleFunc("/state", s.handleState)
+
+	// Cumulative event stream counters
+	mux.HandleFunc("/metrics", s.handleMetrics)
+
+	// Schedule occurrences for a day as JSON
+	mux.HandleFunc("/schedule", s.handleSchedule)
+
+	// Recent raw SSE items, opt-in for troubleshooting
+	if s.cfg.Healthcheck.DebugEvents {
+		mux.HandleFunc("/debug/events", s.handleDebugEvents)
+		log.Warn().Msg("Debug events endpoint enabled at /debug/events")
+	}
+
+	// Webhooks on the same listener (healthcheck.serve_webhooks)
+	if s.webhook != nil {
+		s.webhook.Register(mux)
+	}
+
 	s.server = &http.Server{
 		Addr:    addr,
 		Handler: mux,
 	}
 
-	log.Info().Str("addr", addr).Msg("Starting health check server")
+	log.Info().Str("addr", addr).Bool("webhooks", s.webhook != nil).Msg("Starting health check server")
 
 	go func() {
 		<-ctx.Done()
@@ -71,3 +131,232 @@ func (s *HealthService) run(ctx context.Context) {
 		log.Error().Err(err).Msg("Health check server error")
 	}
 }
+
+// handleHealthz reports 200 if the bridge link is alive, 503 otherwise.
+func (s *HealthService) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	source, err := s.bridgeStatus(r.Context())
+
+	resp := map[string]any{
+		"status": "healthy",
+		"bridge": "reachable",
+		"source": source,
+	}
+	code := http.StatusOK
+	if err != nil {
+		resp["status"] = "unhealthy"
+		resp["bridge"] = "unreachable"
+		resp["error"] = err.Error()
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// bridgeStatus checks bridge reachability. Recent SSE activity counts as
+// reachable; otherwise the bridge is probed directly. Probe results are cached
+// for the configured TTL so frequent health checks don't hammer the bridge.
+// Returns how reachability was determined ("sse" or "probe").
+func (s *HealthService) bridgeStatus(ctx context.Context) (string, error) {
+	hc := &s.cfg.Healthcheck
+
+	if last := s.hue.EventStream.LastActivity(); !last.IsZero() && time.Since(last) < hc.GetBridgeStaleAfter() {
+		return "sse", nil
+	}
+
+	s.probeMu.Lock()
+	defer s.probeMu.Unlock()
+
+	if !s.probeAt.IsZero() && time.Since(s.probeAt) < hc.GetProbeCacheTTL() {
+		return s.probeSource, s.probeErr
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, s.cfg.Hue.GetTimeout())
+	defer cancel()
+
+	s.probeErr = s.hue.Client.V2().Ping(probeCtx)
+	s.probeAt = time.Now()
+	s.probeSource = "probe"
+
+	if s.probeErr != nil {
+		log.Warn().Err(s.probeErr).Msg("Health check: bridge probe failed")
+	}
+
+	return s.probeSource, s.probeErr
+}
+
+// handleInfo reports build and process information.
+func (s *HealthService) handleInfo(w http.ResponseWriter, r *http.Request) {
+	uptime := buildinfo.Uptime()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"version":        buildinfo.Version,
+		"commit":         buildinfo.GetCommit(),
+		"go_version":     buildinfo.GoVersion(),
+		"start_time":     buildinfo.StartTime.Format(time.RFC3339),
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"config_path":    s.cfg.Path,
+		"script_path":    s.cfg.ScriptName(),
+	})
+}
+
+// handleState reports runtime automation state. With ?explain=1 it also
+// reports the reconcile action each group with desired state would take now,
+// and why.
+func (s *HealthService) handleState(w http.ResponseWriter, r *http.Request) {
+	resp := map[string]any{
+		"mode": s.modes.Get(),
+		"reconciler": map[string]any{
+			"paused": s.hue.Orchestrator.Paused(),
+		},
+	}
+	if s.sched != nil {
+		schedState := map[string]any{"next_wake": nil}
+		if at, sched := s.sched.NextWake(); sched != nil {
+			schedState["next_wake"] = map[string]any{
+				"time":        at.In(s.sched.Timezone()).Format(time.RFC3339),
+				"schedule_id": sched.ID(),
+				"action":      sched.ActionName(),
+			}
+		}
+		resp["scheduler"] = schedState
+	}
+	if r.URL.Query().Get("explain") == "1" {
+		resp["groups"] = s.explainGroups(r.Context())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleMetrics reports cumulative event stream counters and the geo
+// calculator's cache counters. Stream totals include previous runs; the
+// connection fields describe the current connection only. Geo counters
+// start at zero on each run.
+func (s *HealthService) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	stats := s.hue.EventStream.Stats()
+	var total int64
+	for _, n := range stats.EventsByType {
+		total += n
+	}
+
+	stream := map[string]any{
+		"events_total":   total,
+		"events_by_type": stats.EventsByType,
+		"bytes_received": stats.BytesReceived,
+		"reconnects":     stats.Reconnects,
+		"connected":      false,
+	}
+	if since := s.hue.EventStream.ConnectedSince(); !since.IsZero() {
+		stream["connected"] = true
+		stream["connected_since"] = since.Format(time.RFC3339)
+		stream["connection_uptime_seconds"] = int64(time.Since(since).Seconds())
+	}
+
+	resp := map[string]any{
+		"event_stream": stream,
+	}
+	if s.geo != nil {
+		resp["geo"] = s.geo.Stats()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// explainGroups runs the group FSM against each group's current actual state
+// without applying anything. Bridge errors are reported per group.
+func (s *HealthService) explainGroups(ctx context.Context) []map[string]any {
+	groups := []map[string]any{}
+
+	desired, _, err := s.hue.Stores.Groups().GetAll()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load desired group state")
+		return groups
+	}
+
+	ids := make([]string, 0, len(desired))
+	for id := range desired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	actualProvider := s.hue.GroupProvider.ActualProvider()
+	for _, id := range ids {
+		entry := map[string]any{"id": id}
+		if actual, err := actualProvider.Get(ctx, id); err != nil {
+			entry["error"] = err.Error()
+		} else {
+			action, reason := group.ExplainAction(desired[id], actual)
+			entry["action"] = action.String()
+			entry["reason"] = reason
+		}
+		groups = append(groups, entry)
+	}
+	return groups
+}
+
+// handleDebugEvents returns the most recent SSE event items, oldest first.
+func (s *HealthService) handleDebugEvents(w http.ResponseWriter, r *http.Request) {
+	recent := s.hue.EventStream.RecentEvents()
+	if recent == nil {
+		recent = []v2.RecentEvent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"capacity": s.cfg.Events.SSE.GetRecentEvents(),
+		"events":   recent,
+	})
+}
+
+// handleSchedule returns the occurrences for ?day=today|tomorrow|yesterday|YYYY-MM-DD.
+func (s *HealthService) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if s.sched == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"scheduler is disabled"}`))
+		return
+	}
+
+	tz := s.sched.Timezone()
+	day, err := parseScheduleDay(r.URL.Query().Get("day"), time.Now().In(tz), tz)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{
+		"day":         day.Format("2006-01-02"),
+		"timezone":    tz.String(),
+		"occurrences": s.sched.OccurrencesForDay(day),
+	})
+}
+
+// parseScheduleDay resolves a day query value relative to now.
+func parseScheduleDay(value string, now time.Time, tz *time.Location) (time.Time, error) {
+	switch value {
+	case "", "today":
+		return now, nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", value, tz)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid day %q (use today, tomorrow, yesterday or YYYY-MM-DD)", value)
+	}
+	return day, nil
+}
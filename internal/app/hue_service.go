@@ -3,7 +3,11 @@ package app
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
 
+	"github.com/amimof/huego"
 	"github.com/rs/zerolog/log"
 
 	"github.com/dokzlo13/lightd/internal/config"
@@ -12,16 +16,19 @@ import (
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/zone"
 	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
 
 // HueService wraps all Hue-related components: client, cache, event stream, and orchestrator.
 type HueService struct {
-	cfg *config.Config
+	cfg   *config.Config
+	store *storage.Store
 
 	Client       *hue.Client
 	SceneIndex   *hue.SceneIndex
+	Devices      *hue.DeviceIndex
 	EventStream  *v2.EventStream
 	Orchestrator *reconcile.Orchestrator
 	Bus          *events.Bus
@@ -30,12 +37,14 @@ type HueService struct {
 	// Resource providers
 	GroupProvider *group.Provider
 	LightProvider *light.Provider
+	ZoneProvider  *zone.Provider
 }
 
 // NewHueService creates a new HueService with all components initialized but not connected.
 func NewHueService(cfg *config.Config, db *sql.DB, store *storage.Store) (*HueService, error) {
 	// Initialize Hue client (holder for V1/V2 clients with shared HTTP config)
-	client := hue.NewClient(cfg.Hue.Bridge, cfg.Hue.Token, cfg.Hue.GetTimeout())
+	client := hue.NewClient(cfg.Hue.Bridge, cfg.Hue.Token, cfg.Hue.GetTimeout(), cfg.Hue.GetAPIVersion())
+	client.SetClientKey(cfg.Hue.ClientKey)
 
 	// Initialize scene index (pure index, caller loads data)
 	sceneIndex := hue.NewSceneIndex()
@@ -43,17 +52,44 @@ func NewHueService(cfg *config.Config, db *sql.DB, store *storage.Store) (*HueSe
 	// Create store registry (centralized typed stores)
 	storeRegistry := hue.NewStoreRegistry(store)
 
-	// Create actual state providers (no caching - always fetch from bridge)
-	groupActualProvider := group.NewActualProvider(client.V1())
-	lightActualProvider := light.NewActualProvider(client.V1())
+	// Device index also caches V1→V2 IDs for the V2 appliers
+	devices := hue.NewDeviceIndex(client.V2())
 
-	// Create appliers
-	groupApplier := group.NewHueApplier(client.V1(), sceneIndex)
-	lightApplier := light.NewHueApplier(client.V1())
+	// Create actual state providers sharing one bridge snapshot per pass
+	// (refetched after writes; see also the stabilization window)
+	stateCache := reconcile.NewStateCache(hue.NewStateSource(client))
+	groupActualProvider := group.NewActualProvider(stateCache)
+	lightActualProvider := light.NewActualProvider(stateCache)
+	groupActualProvider.SetStabilization(cfg.Reconciler.GetStabilizationWindow())
+	lightActualProvider.SetStabilization(cfg.Reconciler.GetStabilizationWindow())
+
+	// Create appliers (V1 or V2 per hue.api_version; "auto" decides on connect)
+	groupApplier := group.NewVersionedApplier(
+		group.NewHueApplier(client.V1, sceneIndex),
+		group.NewV2Applier(devices.V2Client(), sceneIndex),
+		client.V2Only,
+	)
+	lightApplier := light.NewVersionedApplier(
+		light.NewHueApplier(client.V1),
+		light.NewV2Applier(devices.V2Client()),
+		client.V2Only,
+	)
 
 	// Create resource providers
 	groupProvider := group.NewProvider(storeRegistry.Groups(), groupActualProvider, groupApplier)
 	lightProvider := light.NewProvider(storeRegistry.Lights(), lightActualProvider, lightApplier)
+	groupProvider.SetMaxBriStep(cfg.Reconciler.GetMaxBriStep())
+	lightProvider.SetMaxBriStep(cfg.Reconciler.GetMaxBriStep())
+	// Zones are applied through their member groups, resolved from the V2 topology
+	zoneProvider := zone.NewProvider(
+		storeRegistry.Zones(),
+		zone.TopologyFunc(func(ctx context.Context) (*zone.Topology, error) {
+			return hue.FetchZoneTopologyV2(ctx, client.V2())
+		}),
+		groupActualProvider,
+		groupApplier,
+	)
+	zoneProvider.SetLights(lightActualProvider, lightApplier)
 
 	// Initialize orchestrator
 	orchestrator := reconcile.NewOrchestrator(
@@ -63,6 +99,14 @@ func NewHueService(cfg *config.Config, db *sql.DB, store *storage.Store) (*HueSe
 	)
 	orchestrator.Register(groupProvider)
 	orchestrator.Register(lightProvider)
+	orchestrator.Register(zoneProvider)
+	for key, rps := range cfg.Reconciler.GetRateLimits() {
+		orchestrator.SetRateLimit(key, rps)
+	}
+	groupProvider.SetOffDelay(cfg.Reconciler.GetOffDelay(), orchestrator.TriggerGroup)
+	zoneProvider.SetOffDelay(cfg.Reconciler.GetOffDelay(), func(zoneID string) {
+		orchestrator.TriggerResource(reconcile.ResourceKey{Kind: reconcile.KindZone, ID: zoneID})
+	})
 
 	// Initialize event bus
 	bus := events.NewBusWithConfig(cfg.EventBus.GetWorkers(), cfg.EventBus.GetQueueSize())
@@ -73,20 +117,31 @@ func NewHueService(cfg *config.Config, db *sql.DB, store *storage.Store) (*HueSe
 		MaxBackoff:    cfg.Events.SSE.GetMaxRetryBackoff(),
 		Multiplier:    cfg.Events.SSE.GetRetryMultiplier(),
 		MaxReconnects: cfg.Events.SSE.GetMaxReconnects(),
+		MaxEventSize:  cfg.Events.SSE.GetMaxEventSize(),
+		Types:         cfg.Events.SSE.GetTypes(),
+	}
+	if cfg.Healthcheck.DebugEvents {
+		eventStreamConfig.RecentEvents = cfg.Events.SSE.GetRecentEvents()
 	}
 	eventStream := v2.NewEventStreamWithConfig(client.V2(), eventStreamConfig)
 
-	return &HueService{
+	s := &HueService{
 		cfg:           cfg,
+		store:         store,
 		Client:        client,
 		SceneIndex:    sceneIndex,
+		Devices:       devices,
 		EventStream:   eventStream,
 		Orchestrator:  orchestrator,
 		Bus:           bus,
 		Stores:        storeRegistry,
 		GroupProvider: groupProvider,
 		LightProvider: lightProvider,
-	}, nil
+		ZoneProvider:  zoneProvider,
+	}
+	s.restoreStreamStats()
+
+	return s, nil
 }
 
 // Start connects to the Hue bridge and preloads caches.
@@ -96,15 +151,14 @@ func (s *HueService) Start(ctx context.Context) error {
 	}
 
 	// Fetch and load scenes into index
-	scenes, err := s.Client.V1().GetScenes()
-	if err != nil {
+	if err := s.loadScenes(ctx); err != nil {
 		log.Warn().Err(err).Msg("Failed to fetch scenes")
-	} else {
-		s.SceneIndex.Load(scenes)
-		log.Info().Int("count", len(scenes)).Msg("Loaded scenes into index")
 	}
 
-	log.Info().Str("bridge", s.cfg.Hue.Bridge).Msg("Connected to Hue bridge")
+	log.Info().
+		Str("bridge", s.cfg.Hue.Bridge).
+		Bool("v2_only", s.Client.V2Only()).
+		Msg("Connected to Hue bridge")
 	return nil
 }
 
@@ -125,10 +179,23 @@ func (s *HueService) StartBackground(ctx context.Context, onFatalError func(erro
 				}
 			}
 		}()
+		go s.persistStreamStats(ctx)
 	} else {
 		log.Info().Msg("SSE event stream disabled")
 	}
 
+	// Re-queue resources parked as unreachable once a device reconnects
+	s.Bus.Subscribe(events.EventTypeConnectivity, func(event events.Event) {
+		if status, _ := event.Data["status"].(string); status == "connected" {
+			s.Orchestrator.RequeueUnreachable()
+		}
+	})
+
+	// Device list changed (added, renamed, removed): refetch on next hue.devices()
+	s.Bus.Subscribe(events.EventTypeDeviceChange, func(event events.Event) {
+		s.Devices.Invalidate()
+	})
+
 	// Start orchestrator
 	go func() {
 		if err := s.Orchestrator.Run(ctx); err != nil {
@@ -137,6 +204,125 @@ func (s *HueService) StartBackground(ctx context.Context, onFatalError func(erro
 	}()
 }
 
+// Store location of the persisted event stream counters
+const (
+	streamStatsKind = "stats"
+	streamStatsID   = "event_stream"
+)
+
+// restoreStreamStats seeds the event stream counters with the persisted totals.
+func (s *HueService) restoreStreamStats() {
+	payload, _, err := s.store.Get(streamStatsKind, streamStatsID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load event stream stats")
+		return
+	}
+	if payload == nil {
+		return
+	}
+
+	var saved v2.StreamStats
+	if err := json.Unmarshal(payload, &saved); err != nil {
+		log.Warn().Err(err).Msg("Invalid persisted event stream stats, starting from zero")
+		return
+	}
+	s.EventStream.RestoreStats(saved)
+}
+
+// persistStreamStats saves the event stream counters every events.sse.stats_interval
+// until ctx is cancelled. The final save happens during shutdown (see Services.Stop).
+func (s *HueService) persistStreamStats(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Events.SSE.GetStatsInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SaveStreamStats(); err != nil {
+				log.Warn().Err(err).Msg("Failed to persist event stream stats")
+			}
+		}
+	}
+}
+
+// SaveStreamStats persists the current event stream counters.
+func (s *HueService) SaveStreamStats() error {
+	payload, err := json.Marshal(s.EventStream.Stats())
+	if err != nil {
+		return err
+	}
+	return s.store.Set(streamStatsKind, streamStatsID, payload)
+}
+
+// ResetStreamStats zeroes the event stream counters, in memory and on disk.
+func (s *HueService) ResetStreamStats() error {
+	s.EventStream.ResetStats()
+	if err := s.store.Delete(streamStatsKind, streamStatsID); err != nil {
+		return fmt.Errorf("failed to delete event stream stats: %w", err)
+	}
+	return nil
+}
+
+// loadScenes fetches all scenes from the bridge and replaces the scene index.
+func (s *HueService) loadScenes(ctx context.Context) error {
+	var scenes []huego.Scene
+	var err error
+	if s.Client.V2Only() {
+		scenes, err = hue.FetchScenesV2(ctx, s.Client.V2())
+	} else {
+		scenes, err = s.Client.V1().GetScenesContext(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	s.SceneIndex.Load(scenes)
+	log.Info().Int("count", len(scenes)).Msg("Loaded scenes into index")
+	return nil
+}
+
+// RefreshCache reloads the scene index from the bridge and drops the cached
+// device list, so scenes and devices added in the Hue app are picked up
+// without a restart. Bounded by hue.timeout.
+func (s *HueService) RefreshCache(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Hue.GetTimeout())
+	defer cancel()
+
+	if err := s.loadScenes(ctx); err != nil {
+		return fmt.Errorf("failed to fetch scenes: %w", err)
+	}
+	s.Devices.Invalidate()
+	return nil
+}
+
+// HoldBrightness stops a brightness ramp on a group or light by writing its
+// current brightness back as the desired one.
+func (s *HueService) HoldBrightness(ctx context.Context, kind reconcile.Kind, id string) error {
+	switch kind {
+	case reconcile.KindGroup:
+		return s.GroupProvider.Hold(ctx, id)
+	case reconcile.KindLight:
+		return s.LightProvider.Hold(ctx, id)
+	}
+	return fmt.Errorf("cannot hold %s resources", kind)
+}
+
+// keyDeviceType identifies keys created by RotateKey in the bridge whitelist
+const keyDeviceType = "lightd#rotated"
+
+// RotateKey creates a new Hue application key and client key, saves them to hue.token_file and
+// switches the clients to it. Requires token_file so the key survives restarts.
+func (s *HueService) RotateKey(ctx context.Context) error {
+	tokenFile := s.cfg.Hue.TokenFile
+	if tokenFile == "" {
+		return fmt.Errorf("hue.token_file is not configured")
+	}
+	return s.Client.RotateKey(ctx, keyDeviceType, func(token, clientKey string) error {
+		return config.WriteTokenFile(tokenFile, token, clientKey)
+	})
+}
+
 // Close releases all resources.
 func (s *HueService) Close() {
 	if s.Bus != nil {
@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 
+	"github.com/rs/zerolog/log"
 	luastate "github.com/yuin/gopher-lua"
 
 	"github.com/dokzlo13/lightd/internal/config"
@@ -26,9 +27,16 @@ func NewLuaService(deps lua.RuntimeDeps) (*LuaService, error) {
 	}, nil
 }
 
-// LoadScript loads and executes the Lua script.
+// LoadScript loads and executes the Lua script: script_inline if set,
+// otherwise the file at script.
 // Must be called before Start().
 func (s *LuaService) LoadScript() error {
+	if s.cfg.ScriptInline != "" {
+		if s.cfg.Script != "" {
+			log.Info().Str("script", s.cfg.Script).Msg("Both script and script_inline are set, using script_inline")
+		}
+		return s.Runtime.LoadScriptString(s.cfg.ScriptInline)
+	}
 	if err := s.Runtime.LoadScript(s.cfg.GetScript()); err != nil {
 		return err
 	}
@@ -41,6 +49,11 @@ func (s *LuaService) Start(ctx context.Context) {
 	go s.Runtime.Run(ctx)
 }
 
+// RunStartHooks queues the script's system.on_start hooks on the Lua worker.
+func (s *LuaService) RunStartHooks(ctx context.Context) error {
+	return s.Runtime.RunStartHooks(ctx)
+}
+
 // GetSSEModule returns the SSE module for handler registration.
 func (s *LuaService) GetSSEModule() *modules.SSEModule {
 	return s.Runtime.GetSSEModule()
@@ -51,12 +64,33 @@ func (s *LuaService) GetWebhookModule() *modules.WebhookModule {
 	return s.Runtime.GetWebhookModule()
 }
 
+// GetModeModule returns the mode module for handler registration.
+func (s *LuaService) GetModeModule() *modules.ModeModule {
+	return s.Runtime.GetModeModule()
+}
+
+// GetEventsModule returns the events module for handler registration.
+func (s *LuaService) GetEventsModule() *modules.EventsModule {
+	return s.Runtime.GetEventsModule()
+}
+
 // Do queues work to be executed on the Lua VM.
 // This method satisfies the sse.LuaExecutor and webhook.LuaExecutor interfaces.
 func (s *LuaService) Do(ctx context.Context, work func(ctx context.Context)) bool {
 	return s.Runtime.Do(ctx, work)
 }
 
+// DoSyncWithResult queues work on the Lua VM and waits for it to finish.
+func (s *LuaService) DoSyncWithResult(ctx context.Context, work func(ctx context.Context) error) error {
+	return s.Runtime.DoSyncWithResult(ctx, work)
+}
+
+// Shutdown drains queued Lua work, runs the on_stop hooks and closes the
+// runtime, bounded by ctx.
+func (s *LuaService) Shutdown(ctx context.Context) error {
+	return s.Runtime.Shutdown(ctx)
+}
+
 // Close closes the Lua runtime.
 func (s *LuaService) Close() {
 	if s.Runtime != nil {
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -11,6 +12,7 @@ import (
 	"github.com/dokzlo13/lightd/internal/geo"
 	"github.com/dokzlo13/lightd/internal/scheduler"
 	"github.com/dokzlo13/lightd/internal/storage"
+	"github.com/dokzlo13/lightd/internal/storage/kv"
 )
 
 // SchedulerService wraps the scheduler and related periodic tasks.
@@ -18,6 +20,7 @@ type SchedulerService struct {
 	cfg       *config.Config
 	Scheduler *scheduler.Scheduler
 	ledger    *storage.Ledger
+	db        *sql.DB
 	enabled   bool
 }
 
@@ -27,6 +30,7 @@ func NewSchedulerService(
 	bus *events.Bus,
 	l *storage.Ledger,
 	geoCalc *geo.Calculator,
+	db *sql.DB,
 ) *SchedulerService {
 	enabled := cfg.Events.Scheduler.IsEnabled()
 	geoCfg := cfg.Events.Scheduler.Geo
@@ -41,12 +45,14 @@ func NewSchedulerService(
 			sched = scheduler.NewWithFixedTimeOnly(bus, l, geoCfg.GetTimezone())
 			log.Info().Msg("Scheduler geo is disabled - astronomical times (@dawn, @noon, @sunset, etc.) are not available")
 		}
+		sched.SetFiredDedupWindow(cfg.Events.Scheduler.GetFiredDedupWindow())
 	}
 
 	return &SchedulerService{
 		cfg:       cfg,
 		Scheduler: sched,
 		ledger:    l,
+		db:        db,
 		enabled:   enabled,
 	}
 }
@@ -79,7 +85,9 @@ func (s *SchedulerService) Start(ctx context.Context) {
 	}
 }
 
-// runLedgerCleanup periodically cleans up old ledger entries.
+// runLedgerCleanup periodically cleans up old ledger entries, including the
+// schedule_fired records used for occurrence deduplication.
+// The same sweep also reclaims expired KV rows.
 func (s *SchedulerService) runLedgerCleanup(ctx context.Context) {
 	retention := s.cfg.Ledger.GetRetentionPeriod()
 	interval := s.cfg.Ledger.GetRetentionInterval()
@@ -97,6 +105,13 @@ func (s *SchedulerService) runLedgerCleanup(ctx context.Context) {
 			} else if deleted > 0 {
 				log.Info().Int64("deleted", deleted).Dur("retention", retention).Msg("Cleaned up old ledger entries")
 			}
+
+			reclaimed, err := kv.CleanupExpired(s.db)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to cleanup expired KV entries")
+			} else if reclaimed > 0 {
+				log.Info().Int64("reclaimed", reclaimed).Msg("Swept expired KV entries")
+			}
 		}
 	}
 }
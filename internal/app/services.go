@@ -2,16 +2,22 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/dokzlo13/lightd/internal/actions"
 	"github.com/dokzlo13/lightd/internal/config"
+	actionevents "github.com/dokzlo13/lightd/internal/events/action"
+	"github.com/dokzlo13/lightd/internal/events/filter"
+	modeevents "github.com/dokzlo13/lightd/internal/events/mode"
 	"github.com/dokzlo13/lightd/internal/events/schedule"
 	"github.com/dokzlo13/lightd/internal/events/sse"
 	"github.com/dokzlo13/lightd/internal/events/webhook"
 	"github.com/dokzlo13/lightd/internal/geo"
 	"github.com/dokzlo13/lightd/internal/lua"
+	"github.com/dokzlo13/lightd/internal/mode"
 	"github.com/dokzlo13/lightd/internal/storage"
 	"github.com/dokzlo13/lightd/internal/storage/kv"
 )
@@ -32,6 +38,9 @@ type Services struct {
 	// KV storage
 	KV *kv.Manager
 
+	// Global automation mode
+	Modes *mode.Manager
+
 	// Action system
 	Registry *actions.Registry
 	Invoker  *actions.Invoker
@@ -49,7 +58,7 @@ func NewServices(cfg *config.Config) (*Services, error) {
 	s := &Services{cfg: cfg}
 
 	// Initialize database
-	database, err := storage.Open(cfg.Database.GetPath())
+	database, err := storage.Open(cfg.Database.GetPath(), cfg.Database.GetBusyTimeout())
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +86,12 @@ func NewServices(cfg *config.Config) (*Services, error) {
 		log.Warn().Msg("No lat/lon configured, will use Nominatim geocoding (cached in SQLite)")
 		s.GeoCalc = geo.NewCalculatorWithCache(geoCfg.GetHTTPTimeout(), geoCache)
 	}
+	if geoCfg.CAFile != "" {
+		if err := s.GeoCalc.SetCAFile(geoCfg.CAFile); err != nil {
+			s.Close()
+			return nil, fmt.Errorf("events.scheduler.geo.ca_file: %w", err)
+		}
+	}
 
 	// Initialize action registry
 	s.Registry = actions.NewRegistry()
@@ -100,26 +115,44 @@ func NewServices(cfg *config.Config) (*Services, error) {
 	}
 
 	// Initialize action invoker
-	s.Invoker = actions.NewInvoker(s.Registry, s.Ledger, ctxFactory)
+	s.Invoker = actions.NewInvoker(s.Registry, s.Ledger, actions.NewSuppression(s.Store), s.Hue.Bus, ctxFactory)
 
 	// Initialize scheduler service (now uses EventBus instead of direct invocation)
-	s.Scheduler = NewSchedulerService(cfg, s.Hue.Bus, s.Ledger, s.GeoCalc)
+	s.Scheduler = NewSchedulerService(cfg, s.Hue.Bus, s.Ledger, s.GeoCalc, database.DB)
 
 	// Initialize KV manager
 	s.KV = kv.NewManager(database.DB)
 
+	// Resolve ${kv:bucket/key} action args against the KV store
+	s.Invoker.SetKVLookup(func(bucket, key string) (any, error) {
+		return s.KV.Bucket(bucket, true).Get(key)
+	})
+
+	// Initialize mode manager (restores persisted mode)
+	s.Modes = mode.NewManager(s.Store, s.Hue.Bus)
+
 	// Initialize Lua service
 	luaDeps := lua.RuntimeDeps{
 		Config:       cfg,
 		Registry:     s.Registry,
 		Invoker:      s.Invoker,
 		Scheduler:    s.Scheduler.Scheduler,
-		Bridge:       s.Hue.Client.V1(),
+		Bridge:       s.Hue.Client.V1,
+		V2Client:     s.Hue.Devices.V2Client(),
 		SceneIndex:   s.Hue.SceneIndex,
+		Devices:      s.Hue.Devices,
 		Stores:       s.Hue.Stores,
 		Orchestrator: s.Hue.Orchestrator,
+		GroupActual:  s.Hue.GroupProvider.ActualProvider(),
 		GeoCalc:      s.GeoCalc,
 		KVManager:    s.KV,
+		Ledger:       s.Ledger,
+		Modes:        s.Modes,
+		RotateKey:    s.Hue.RotateKey,
+		RefreshCache: s.Hue.RefreshCache,
+		ClientKey:    s.Hue.Client.ClientKey,
+		HoldBri:      s.Hue.HoldBrightness,
+		Dashboard:    s.DashboardState,
 	}
 
 	s.Lua, err = NewLuaService(luaDeps)
@@ -129,10 +162,15 @@ func NewServices(cfg *config.Config) (*Services, error) {
 	}
 
 	// Initialize health service
-	s.Health = NewHealthService(cfg)
+	s.Health = NewHealthService(cfg, s.Hue, s.Modes, s.Scheduler.Scheduler, s.GeoCalc)
 
 	// Initialize webhook service
 	s.Webhook = NewWebhookService(cfg, s.Hue.Bus)
+	if cfg.SharedListener() {
+		s.Webhook.MountOn(s.Health)
+	} else if cfg.Healthcheck.ServeWebhooks {
+		log.Warn().Msg("healthcheck.serve_webhooks needs both servers enabled on the same host and port, keeping them separate")
+	}
 
 	return s, nil
 }
@@ -150,6 +188,9 @@ func (s *Services) Start(ctx context.Context, onFatalError func(error)) error {
 		return err
 	}
 
+	// Event filters from events.filter() run before any handler
+	filter.RegisterFilters(ctx, s.Lua.GetEventsModule(), s.Hue.Bus, s.Lua)
+
 	// Register event handlers from modules (after Lua script is loaded)
 	// SSE handlers (button, rotary, connectivity from Hue event stream)
 	if s.cfg.Events.SSE.IsEnabled() {
@@ -162,10 +203,18 @@ func (s *Services) Start(ctx context.Context, onFatalError func(error)) error {
 		webhook.RegisterHandlers(ctx, webhookModule, s.Hue.Bus, s.Invoker, s.Lua)
 		// Set path matcher for HTTP request validation
 		s.Webhook.SetPathMatcher(webhookModule)
+		// webhook.respond() handlers answer requests directly
+		s.Webhook.SetResponder(webhook.NewResponder(webhookModule, s.Lua))
 	}
-	// Schedule handlers (scheduler events go through EventBus)
+	// Mode change handlers
+	modeevents.RegisterHandlers(ctx, s.Lua.GetModeModule(), s.Hue.Bus, s.Invoker, s.Lua)
+	// Action failure handlers
+	actionevents.RegisterHandlers(ctx, s.Lua.GetEventsModule(), s.Hue.Bus, s.Invoker, s.Lua)
+	// Schedule handlers (scheduler events go through EventBus, sync schedules
+	// are invoked directly)
 	if s.cfg.Events.Scheduler.IsEnabled() {
 		schedule.RegisterHandler(ctx, s.Hue.Bus, s.Invoker, s.Lua)
+		s.Scheduler.Scheduler.SetSyncInvoker(schedule.NewSyncInvoker(s.Invoker, s.Lua))
 	}
 
 	// Start all background services
@@ -178,6 +227,11 @@ func (s *Services) Start(ctx context.Context, onFatalError func(error)) error {
 	// Start KV cleanup goroutine
 	s.KV.StartCleanup(ctx, s.cfg.KV.GetCleanupInterval())
 
+	// Everything is up and the bridge is connected: run system.on_start hooks
+	if err := s.Lua.RunStartHooks(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to queue on_start hooks")
+	}
+
 	return nil
 }
 
@@ -186,13 +240,50 @@ func (s *Services) ClearState() error {
 	return s.Store.Clear("")
 }
 
-// Stop gracefully stops all services.
+// Stop gracefully stops all services in dependency order, bounded by the
+// shutdown timeout. The caller must have cancelled the start context first, so
+// the SSE stream, webhook server and scheduler have stopped producing events.
+//
+//  1. Event bus: stop accepting events, finish in-flight handlers
+//  2. Orchestrator: let the current reconcile pass finish
+//  3. Lua worker: drain queued actions, run on_stop hooks, then close the VM
+//  4. KV cleanup, event stream stats and Hue client
+//  5. Database: closed last, after every writer has stopped
+//
+// If a step times out, the Hue client and database are left open, since the
+// abandoned step may still be using them.
 func (s *Services) Stop() error {
-	s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.GetShutdownTimeout())
+	defer cancel()
+
+	abandoned := runShutdown(ctx, s.shutdownSteps())
+	if len(abandoned) > 0 {
+		return fmt.Errorf("shutdown timed out during: %s", strings.Join(abandoned, ", "))
+	}
+
+	log.Info().Msg("Shutdown complete")
 	return nil
 }
 
-// Close releases all resources.
+// shutdownSteps returns the ordered steps run by Stop.
+func (s *Services) shutdownSteps() []shutdownStep {
+	return []shutdownStep{
+		{name: "event bus", fn: func(ctx context.Context) { s.Hue.Bus.Close(ctx) }},
+		{name: "orchestrator", fn: func(ctx context.Context) { s.Hue.Orchestrator.Wait(ctx) }},
+		{name: "lua worker", fn: func(ctx context.Context) { s.Lua.Shutdown(ctx) }},
+		{name: "kv cleanup", fn: func(ctx context.Context) { s.KV.StopCleanup() }},
+		{name: "stream stats", fn: func(ctx context.Context) {
+			if err := s.Hue.SaveStreamStats(); err != nil {
+				log.Warn().Err(err).Msg("Failed to persist event stream stats")
+			}
+		}},
+		{name: "hue client", release: true, fn: func(ctx context.Context) { s.Hue.Client.Close() }},
+		{name: "database", release: true, fn: func(ctx context.Context) { s.DB.Close() }},
+	}
+}
+
+// Close releases all resources without ordering guarantees.
+// Used to clean up after a failed initialization; prefer Stop for running services.
 func (s *Services) Close() {
 	if s.KV != nil {
 		s.KV.StopCleanup()
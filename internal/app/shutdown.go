@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// shutdownStep is one stage of ordered shutdown.
+type shutdownStep struct {
+	name string
+	fn   func(ctx context.Context)
+
+	// release marks a step that closes a resource earlier steps may still be
+	// using (database, bridge client). It is skipped once a step has been
+	// abandoned, leaving the resource to be released by process exit.
+	release bool
+}
+
+// runShutdown runs steps in order, all sharing ctx's deadline.
+// A step still running when the deadline passes is abandoned (logged). The
+// remaining steps then run inline with the expired context and must return
+// promptly, so shutdown always completes; release steps are skipped instead,
+// since the abandoned step may still be using what they close.
+// Returns the names of abandoned steps.
+func runShutdown(ctx context.Context, steps []shutdownStep) []string {
+	var abandoned []string
+
+	for _, step := range steps {
+		if step.release && len(abandoned) > 0 {
+			log.Warn().
+				Str("step", step.name).
+				Strs("abandoned", abandoned).
+				Msg("Shutdown step skipped, an abandoned step may still be using it")
+			continue
+		}
+		if ctx.Err() != nil {
+			step.fn(ctx)
+			continue
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			step.fn(ctx)
+		}()
+
+		select {
+		case <-done:
+			log.Debug().Str("step", step.name).Msg("Shutdown step complete")
+		case <-ctx.Done():
+			log.Warn().Str("step", step.name).Msg("Shutdown step timed out, continuing")
+			abandoned = append(abandoned, step.name)
+		}
+	}
+
+	return abandoned
+}
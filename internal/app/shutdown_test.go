@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunShutdown_Order(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	step := func(name string) shutdownStep {
+		return shutdownStep{name: name, fn: func(ctx context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}}
+	}
+
+	abandoned := runShutdown(context.Background(), []shutdownStep{
+		step("intake"),
+		step("reconciler"),
+		step("lua"),
+		step("database"),
+	})
+
+	if len(abandoned) != 0 {
+		t.Fatalf("expected no abandoned steps, got %v", abandoned)
+	}
+	want := []string{"intake", "reconciler", "lua", "database"}
+	if !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestRunShutdown_TimeoutSkipsReleaseSteps(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	kvStopped, dbClosed := false, false
+	start := time.Now()
+	abandoned := runShutdown(ctx, []shutdownStep{
+		{name: "lua", fn: func(ctx context.Context) { <-block }},
+		{name: "kv", fn: func(ctx context.Context) { kvStopped = true }},
+		{name: "database", release: true, fn: func(ctx context.Context) { dbClosed = true }},
+	})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took %v, expected to be bounded by the deadline", elapsed)
+	}
+	if !slices.Equal(abandoned, []string{"lua"}) {
+		t.Fatalf("abandoned = %v, want [lua]", abandoned)
+	}
+	if !kvStopped {
+		t.Error("expected later steps to run after a timeout")
+	}
+	if dbClosed {
+		t.Error("database closed while an abandoned step may still use it")
+	}
+}
+
+func TestServices_ShutdownOrder(t *testing.T) {
+	var names, release []string
+	for _, step := range (&Services{}).shutdownSteps() {
+		names = append(names, step.name)
+		if step.release {
+			release = append(release, step.name)
+		}
+	}
+
+	want := []string{"event bus", "orchestrator", "lua worker", "kv cleanup", "stream stats", "hue client", "database"}
+	if !slices.Equal(names, want) {
+		t.Fatalf("shutdown order = %v, want %v", names, want)
+	}
+	if want := []string{"hue client", "database"}; !slices.Equal(release, want) {
+		t.Errorf("release steps = %v, want %v", release, want)
+	}
+}
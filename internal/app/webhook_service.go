@@ -14,11 +14,15 @@ import (
 type WebhookService struct {
 	cfg    *config.Config
 	server *webhook.Server
+	shared bool // handlers are mounted on the health server instead
 }
 
 // NewWebhookService creates a new WebhookService.
 func NewWebhookService(cfg *config.Config, bus *events.Bus) *WebhookService {
 	server := webhook.NewServer(cfg.Events.Webhook.GetHost(), cfg.Events.Webhook.GetPort(), bus)
+	if cfg.Events.Webhook.DebugEmit {
+		server.EnableDebugEmit()
+	}
 	return &WebhookService{
 		cfg:    cfg,
 		server: server,
@@ -31,12 +35,28 @@ func (s *WebhookService) SetPathMatcher(matcher webhook.PathMatcher) {
 	s.server.SetPathMatcher(matcher)
 }
 
+// SetResponder sets the responder for handlers that return a response body.
+func (s *WebhookService) SetResponder(responder webhook.Responder) {
+	s.server.SetResponder(responder)
+}
+
+// MountOn serves the webhook handlers from the health server's listener
+// instead of a separate one. Must be called before Start.
+func (s *WebhookService) MountOn(health *HealthService) {
+	health.MountWebhook(s.server)
+	s.shared = true
+}
+
 // Start begins the webhook server if enabled.
 func (s *WebhookService) Start(ctx context.Context) {
 	if !s.cfg.Events.Webhook.Enabled {
 		log.Debug().Msg("Webhook server disabled")
 		return
 	}
+	if s.shared {
+		log.Debug().Msg("Webhook handlers served by the health server")
+		return
+	}
 
 	go func() {
 		if err := s.server.Run(ctx, s.cfg.GetShutdownTimeout()); err != nil {
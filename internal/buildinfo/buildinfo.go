@@ -0,0 +1,48 @@
+// Package buildinfo exposes version information injected at build time.
+//
+// Set via ldflags:
+//
+//	go build -ldflags "-X github.com/dokzlo13/lightd/internal/buildinfo.Version=v1.2.3 \
+//	  -X github.com/dokzlo13/lightd/internal/buildinfo.Commit=$(git rev-parse --short HEAD)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Injected via -ldflags -X at build time.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// StartTime is when the process started.
+var StartTime = time.Now()
+
+// GetCommit returns the injected commit, falling back to the VCS revision
+// recorded by the Go toolchain, or "unknown".
+func GetCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				return s.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// GoVersion returns the Go version the binary was built with.
+func GoVersion() string {
+	return runtime.Version()
+}
+
+// Uptime returns how long the process has been running.
+func Uptime() time.Duration {
+	return time.Since(StartTime)
+}
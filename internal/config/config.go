@@ -1,8 +1,11 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,12 +24,18 @@ type Config struct {
 	EventBus        EventBusConfig    `yaml:"eventbus"`
 	KV              KVConfig          `yaml:"kv"`
 	Script          string            `yaml:"script"`
+	ScriptInline    string            `yaml:"script_inline"` // Lua source; takes precedence over script
+	ScriptsDir      string            `yaml:"scripts_dir"`   // Base directory for require(); defaults to the script's directory
 	ShutdownTimeout Duration          `yaml:"shutdown_timeout"`
+
+	// Path is the file the config was loaded from (set by Load)
+	Path string `yaml:"-"`
 }
 
 // Default top-level values
 const (
 	DefaultScript          = "main.lua"
+	InlineScriptName       = "<inline>"
 	DefaultShutdownTimeout = 5 * time.Second
 	DefaultGeoTimezone     = "UTC"
 )
@@ -39,6 +48,15 @@ func (c *Config) GetScript() string {
 	return c.Script
 }
 
+// ScriptName returns a label for the script in use: "<inline>" for
+// script_inline, otherwise the script path.
+func (c *Config) ScriptName() string {
+	if c.ScriptInline != "" {
+		return InlineScriptName
+	}
+	return c.GetScript()
+}
+
 // GetShutdownTimeout returns the shutdown timeout with default
 func (c *Config) GetShutdownTimeout() time.Duration {
 	if c.ShutdownTimeout == 0 {
@@ -56,11 +74,28 @@ type EventsConfig struct {
 
 // HueConfig contains Hue bridge connection settings
 type HueConfig struct {
-	Bridge  string   `yaml:"bridge"`
-	Token   string   `yaml:"token"`
-	Timeout Duration `yaml:"timeout"`
+	Bridge     string     `yaml:"bridge"`
+	Token      string     `yaml:"token"`
+	TokenFile  string     `yaml:"token_file"` // overrides token when the file exists; written by hue.rotate_key()
+	ClientKey  string     `yaml:"clientkey"`  // entertainment (DTLS) client key; overridden by token_file
+	Timeout    Duration   `yaml:"timeout"`
+	APIVersion string     `yaml:"api_version"` // "v1", "v2" or "auto" (default)
+	Aliases    HueAliases `yaml:"aliases"`
+}
+
+// HueAliases maps friendly names to V1 light and group IDs for hue.light() and hue.group()
+type HueAliases struct {
+	Lights map[string]string `yaml:"lights"`
+	Groups map[string]string `yaml:"groups"`
 }
 
+// Hue API versions for hue.api_version
+const (
+	HueAPIVersionV1   = "v1"
+	HueAPIVersionV2   = "v2"
+	HueAPIVersionAuto = "auto"
+)
+
 // Default timeout values
 const (
 	DefaultHueTimeout         = 30 * time.Second
@@ -69,6 +104,9 @@ const (
 	DefaultSSEMaxRetryBackoff = 2 * time.Minute
 	DefaultSSERetryMultiplier = 2.0
 	DefaultSSEMaxReconnects   = 0 // infinite
+	DefaultSSEMaxEventSize    = 1 << 20
+	DefaultSSERecentEvents    = 100
+	DefaultSSEStatsInterval   = 5 * time.Minute
 )
 
 // GetTimeout returns the Hue timeout with default
@@ -79,6 +117,54 @@ func (c *HueConfig) GetTimeout() time.Duration {
 	return c.Timeout.Duration()
 }
 
+// GetAPIVersion returns the Hue API version with default
+func (c *HueConfig) GetAPIVersion() string {
+	switch c.APIVersion {
+	case HueAPIVersionV1, HueAPIVersionV2:
+		return c.APIVersion
+	default:
+		return HueAPIVersionAuto
+	}
+}
+
+// ReadTokenFile returns the application key stored on the first line of the
+// file at path, and the entertainment client key on the second line, if any.
+func ReadTokenFile(path string) (token, clientKey string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	token, clientKey, _ = strings.Cut(strings.TrimSpace(string(data)), "\n")
+	return strings.TrimSpace(token), strings.TrimSpace(clientKey), nil
+}
+
+// WriteTokenFile atomically replaces the application key (and client key,
+// when not empty) stored at path. The file is only readable by the owner.
+func WriteTokenFile(path, token, clientKey string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	content := token + "\n"
+	if clientKey != "" {
+		content += clientKey + "\n"
+	}
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
 // GeoConfig contains geo/location settings for astronomical calculations
 type GeoConfig struct {
 	Enabled     *bool    `yaml:"enabled"`
@@ -88,6 +174,7 @@ type GeoConfig struct {
 	Lat         float64  `yaml:"lat,omitempty"`
 	Lon         float64  `yaml:"lon,omitempty"`
 	HTTPTimeout Duration `yaml:"http_timeout"`
+	CAFile      string   `yaml:"ca_file"` // extra PEM roots for geocoding requests
 }
 
 // IsEnabled returns whether geo is enabled (defaults to true if not set)
@@ -124,11 +211,15 @@ func (c *GeoConfig) GetTimezone() string {
 
 // DatabaseConfig contains database settings
 type DatabaseConfig struct {
-	Path string `yaml:"path"`
+	Path        string   `yaml:"path"`
+	BusyTimeout Duration `yaml:"busy_timeout"` // How long a writer waits on a locked database
 }
 
 // Default database values
-const DefaultDatabasePath = "./hueplanner.sqlite"
+const (
+	DefaultDatabasePath        = "./hueplanner.sqlite"
+	DefaultDatabaseBusyTimeout = 5 * time.Second
+)
 
 // GetPath returns the database path with default
 func (c *DatabaseConfig) GetPath() string {
@@ -138,6 +229,14 @@ func (c *DatabaseConfig) GetPath() string {
 	return c.Path
 }
 
+// GetBusyTimeout returns the SQLite busy timeout with default
+func (c *DatabaseConfig) GetBusyTimeout() time.Duration {
+	if c.BusyTimeout == 0 {
+		return DefaultDatabaseBusyTimeout
+	}
+	return c.BusyTimeout.Duration()
+}
+
 // LogConfig contains logging settings
 type LogConfig struct {
 	Level   string `yaml:"level"`
@@ -162,6 +261,24 @@ type ReconcilerConfig struct {
 	PeriodicInterval Duration `yaml:"periodic_interval"` // 0 = disabled
 	DebounceMs       int      `yaml:"debounce_ms"`       // Delay before running reconciliation (0 = immediate)
 	RateLimitRPS     float64  `yaml:"rate_limit_rps"`
+
+	// RateLimits overrides the global rate per kind ("group") or per resource ("group:3").
+	// Resources without an override share the global limiter.
+	RateLimits map[string]float64 `yaml:"rate_limits"`
+
+	// MaxBriStep caps the brightness change per apply for groups and lights;
+	// larger changes are ramped over several rate-limited applies. 0 = disabled.
+	MaxBriStep int `yaml:"max_bri_step"`
+
+	// OffDelay holds back turning a group or zone off when a scheduled action
+	// set it, so a new desired state (e.g. a button press) can cancel it.
+	// 0 = turn off immediately.
+	OffDelay Duration `yaml:"off_delay"`
+
+	// StabilizationWindow makes actual-state reads of a group or light return
+	// the state expected after a write for this long, instead of a transition
+	// state the bridge may still report. 0 = always read from the bridge.
+	StabilizationWindow Duration `yaml:"stabilization_window"`
 }
 
 // Default reconciler values
@@ -195,6 +312,37 @@ func (c *ReconcilerConfig) GetRateLimitRPS() float64 {
 	return c.RateLimitRPS
 }
 
+// GetMaxBriStep returns the brightness ramp step, 0 if disabled.
+// Values outside 1-253 disable ramping.
+func (c *ReconcilerConfig) GetMaxBriStep() int {
+	if c.MaxBriStep <= 0 || c.MaxBriStep >= 254 {
+		return 0
+	}
+	return c.MaxBriStep
+}
+
+// GetOffDelay returns how long scheduled turn-offs are held back, 0 if disabled.
+func (c *ReconcilerConfig) GetOffDelay() time.Duration {
+	if c.OffDelay <= 0 {
+		return 0
+	}
+	return c.OffDelay.Duration()
+}
+
+// GetStabilizationWindow returns how long written state is preferred over
+// bridge reads, 0 if disabled.
+func (c *ReconcilerConfig) GetStabilizationWindow() time.Duration {
+	if c.StabilizationWindow <= 0 {
+		return 0
+	}
+	return c.StabilizationWindow.Duration()
+}
+
+// GetRateLimits returns the per-kind/per-resource rate overrides (may be nil)
+func (c *ReconcilerConfig) GetRateLimits() map[string]float64 {
+	return c.RateLimits
+}
+
 // LedgerConfig contains event ledger settings
 type LedgerConfig struct {
 	Enabled           *bool    `yaml:"enabled"`
@@ -234,15 +382,21 @@ func (c *LedgerConfig) GetRetentionInterval() time.Duration {
 
 // HealthcheckConfig contains health check server settings
 type HealthcheckConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
+	Enabled          bool     `yaml:"enabled"`
+	Host             string   `yaml:"host"`
+	Port             int      `yaml:"port"`
+	BridgeStaleAfter Duration `yaml:"bridge_stale_after"` // Probe the bridge if no SSE data for this long
+	ProbeCacheTTL    Duration `yaml:"probe_cache_ttl"`    // How long a probe result is reused
+	DebugEvents      bool     `yaml:"debug_events"`       // Expose /debug/events (recent SSE items)
+	ServeWebhooks    bool     `yaml:"serve_webhooks"`     // Share one listener with the webhook server when host/port match
 }
 
 // Default healthcheck values
 const (
-	DefaultHealthcheckHost = "0.0.0.0"
-	DefaultHealthcheckPort = 9090
+	DefaultHealthcheckHost             = "0.0.0.0"
+	DefaultHealthcheckPort             = 9090
+	DefaultHealthcheckBridgeStaleAfter = 60 * time.Second
+	DefaultHealthcheckProbeCacheTTL    = 5 * time.Second
 )
 
 // GetHost returns the host with default
@@ -261,11 +415,28 @@ func (c *HealthcheckConfig) GetPort() int {
 	return c.Port
 }
 
+// GetBridgeStaleAfter returns the SSE staleness threshold with default
+func (c *HealthcheckConfig) GetBridgeStaleAfter() time.Duration {
+	if c.BridgeStaleAfter == 0 {
+		return DefaultHealthcheckBridgeStaleAfter
+	}
+	return c.BridgeStaleAfter.Duration()
+}
+
+// GetProbeCacheTTL returns the probe cache TTL with default
+func (c *HealthcheckConfig) GetProbeCacheTTL() time.Duration {
+	if c.ProbeCacheTTL == 0 {
+		return DefaultHealthcheckProbeCacheTTL
+	}
+	return c.ProbeCacheTTL.Duration()
+}
+
 // WebhookConfig contains webhook server settings
 type WebhookConfig struct {
-	Enabled bool   `yaml:"enabled"`
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
+	Enabled   bool   `yaml:"enabled"`
+	Host      string `yaml:"host"`
+	Port      int    `yaml:"port"`
+	DebugEmit bool   `yaml:"debug_emit"` // Serve POST /debug/emit to inject synthetic events
 }
 
 // Default webhook values
@@ -290,6 +461,17 @@ func (c *WebhookConfig) GetPort() int {
 	return c.Port
 }
 
+// SharedListener reports whether the health and webhook handlers are served
+// by one HTTP server: healthcheck.serve_webhooks is set, both servers are
+// enabled and their host and port match.
+func (c *Config) SharedListener() bool {
+	return c.Healthcheck.ServeWebhooks &&
+		c.Healthcheck.Enabled &&
+		c.Events.Webhook.Enabled &&
+		c.Healthcheck.GetHost() == c.Events.Webhook.GetHost() &&
+		c.Healthcheck.GetPort() == c.Events.Webhook.GetPort()
+}
+
 // SSEConfig contains SSE (Hue event stream) settings
 type SSEConfig struct {
 	Enabled         *bool    `yaml:"enabled"`
@@ -297,6 +479,25 @@ type SSEConfig struct {
 	MaxRetryBackoff Duration `yaml:"max_retry_backoff"`
 	RetryMultiplier float64  `yaml:"retry_multiplier"`
 	MaxReconnects   int      `yaml:"max_reconnects"`
+	MaxEventSize    int      `yaml:"max_event_size"` // bytes per event stream line
+	RecentEvents    int      `yaml:"recent_events"`  // items kept for /debug/events
+	StatsInterval   Duration `yaml:"stats_interval"` // how often stream counters are persisted
+	Types           []string `yaml:"types"`          // event types published to handlers, empty = all
+}
+
+// SSETypes lists the event stream types that can be enabled with events.sse.types
+var SSETypes = []string{"button", "rotary", "connectivity", "light_change"}
+
+// GetTypes returns the set of enabled event types, or nil if all are enabled
+func (c *SSEConfig) GetTypes() map[string]bool {
+	if len(c.Types) == 0 {
+		return nil
+	}
+	types := make(map[string]bool, len(c.Types))
+	for _, t := range c.Types {
+		types[t] = true
+	}
+	return types
 }
 
 // IsEnabled returns whether SSE is enabled (defaults to true if not set)
@@ -336,12 +537,40 @@ func (c *SSEConfig) GetMaxReconnects() int {
 	return c.MaxReconnects
 }
 
+// GetMaxEventSize returns the max size of a single event stream line with default
+func (c *SSEConfig) GetMaxEventSize() int {
+	if c.MaxEventSize <= 0 {
+		return DefaultSSEMaxEventSize
+	}
+	return c.MaxEventSize
+}
+
+// GetRecentEvents returns how many recent event items to keep with default
+func (c *SSEConfig) GetRecentEvents() int {
+	if c.RecentEvents <= 0 {
+		return DefaultSSERecentEvents
+	}
+	return c.RecentEvents
+}
+
+// GetStatsInterval returns how often event stream counters are persisted with default
+func (c *SSEConfig) GetStatsInterval() time.Duration {
+	if c.StatsInterval <= 0 {
+		return DefaultSSEStatsInterval
+	}
+	return c.StatsInterval.Duration()
+}
+
 // SchedulerConfig contains scheduler settings
 type SchedulerConfig struct {
-	Enabled *bool     `yaml:"enabled"`
-	Geo     GeoConfig `yaml:"geo"`
+	Enabled          *bool     `yaml:"enabled"`
+	Geo              GeoConfig `yaml:"geo"`
+	FiredDedupWindow Duration  `yaml:"fired_dedup_window"`
 }
 
+// DefaultFiredDedupWindow is how long a fired occurrence blocks re-emission
+const DefaultFiredDedupWindow = 10 * time.Minute
+
 // IsEnabled returns whether the scheduler is enabled (defaults to true if not set)
 func (c *SchedulerConfig) IsEnabled() bool {
 	if c.Enabled == nil {
@@ -350,6 +579,15 @@ func (c *SchedulerConfig) IsEnabled() bool {
 	return *c.Enabled
 }
 
+// GetFiredDedupWindow returns how long an emitted occurrence is not emitted
+// again, even if its action has not completed yet
+func (c *SchedulerConfig) GetFiredDedupWindow() time.Duration {
+	if c.FiredDedupWindow <= 0 {
+		return DefaultFiredDedupWindow
+	}
+	return c.FiredDedupWindow.Duration()
+}
+
 // EventBusConfig contains event bus settings
 type EventBusConfig struct {
 	Workers   int `yaml:"workers"`
@@ -432,6 +670,36 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal([]byte(expanded), &cfg); err != nil {
 		return nil, err
 	}
+	cfg.Path = path
+
+	// A relative token_file is resolved against the config file's directory.
+	// A missing file falls back to hue.token (it is created on first rotation).
+	if cfg.Hue.TokenFile != "" {
+		if !filepath.IsAbs(cfg.Hue.TokenFile) {
+			cfg.Hue.TokenFile = filepath.Join(filepath.Dir(path), cfg.Hue.TokenFile)
+		}
+		token, clientKey, err := ReadTokenFile(cfg.Hue.TokenFile)
+		switch {
+		case err == nil && token != "":
+			cfg.Hue.Token = token
+			if clientKey != "" {
+				cfg.Hue.ClientKey = clientKey
+			}
+		case err != nil && !os.IsNotExist(err):
+			return nil, fmt.Errorf("failed to read hue.token_file: %w", err)
+		}
+	}
+
+	for _, t := range cfg.Events.SSE.Types {
+		if !slices.Contains(SSETypes, t) {
+			return nil, fmt.Errorf("events.sse.types: unknown type %q (expected one of %s)", t, strings.Join(SSETypes, ", "))
+		}
+	}
+
+	// A relative geo ca_file is resolved against the config file's directory too
+	if geo := &cfg.Events.Scheduler.Geo; geo.CAFile != "" && !filepath.IsAbs(geo.CAFile) {
+		geo.CAFile = filepath.Join(filepath.Dir(path), geo.CAFile)
+	}
 
 	return &cfg, nil
 }
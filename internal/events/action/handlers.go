@@ -0,0 +1,70 @@
+// Package action provides event handling for action failure events.
+package action
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/events/sse"
+	"github.com/dokzlo13/lightd/internal/lua/exec"
+)
+
+// FailedHandler is an action invoked when a matching action fails
+type FailedHandler struct {
+	Action     sse.Matcher // Matches the failed action's name ("*" for any, "a|b" for multiple)
+	ActionName string
+	ActionArgs map[string]any
+}
+
+// HandlerRegistry provides handler lookup functions
+type HandlerRegistry interface {
+	GetActionFailedHandlers() []FailedHandler
+}
+
+// RegisterHandlers subscribes to action failure events on the event bus and dispatches to handlers.
+// Handlers run with source "action_failed"; their own failures are not re-published.
+func RegisterHandlers(
+	ctx context.Context,
+	registry HandlerRegistry,
+	bus *events.Bus,
+	invoker *actions.Invoker,
+	luaExec exec.Executor,
+) {
+	bus.Subscribe(events.EventTypeActionFailed, func(event events.Event) {
+		failed, _ := event.Data["action"].(string)
+		errMsg, _ := event.Data["error"].(string)
+		source, _ := event.Data["source"].(string)
+
+		for _, handler := range registry.GetActionFailedHandlers() {
+			if !handler.Action.Matches(failed) {
+				continue
+			}
+
+			log.Info().
+				Str("trigger", "action_failed").
+				Str("failed_action", failed).
+				Str("action", handler.ActionName).
+				Str("trace_id", event.TraceID()).
+				Msg("Action triggered by action failure")
+
+			args := make(map[string]any, len(handler.ActionArgs)+3)
+			for k, v := range handler.ActionArgs {
+				args[k] = v
+			}
+			args["action"] = failed
+			args["error"] = errMsg
+			args["source"] = source
+
+			actionName := handler.ActionName
+			traceID := event.TraceID()
+			luaExec.Do(ctx, func(workCtx context.Context) {
+				if err := invoker.InvokeWithSource(events.WithTraceID(workCtx, traceID), actionName, args, "", actions.SourceActionFailed, ""); err != nil {
+					log.Error().Err(err).Str("action", actionName).Msg("Failed to invoke action_failed handler")
+				}
+			})
+		}
+	})
+}
@@ -2,6 +2,8 @@ package events
 
 import (
 	"context"
+	"maps"
+	"sort"
 	"sync"
 
 	"github.com/rs/zerolog/log"
@@ -17,6 +19,9 @@ const (
 	EventTypeLightChange  EventType = "light_change"
 	EventTypeSchedule     EventType = "schedule"
 	EventTypeWebhook      EventType = "webhook"
+	EventTypeModeChange   EventType = "mode_change"
+	EventTypeDeviceChange EventType = "device_change"
+	EventTypeActionFailed EventType = "action_failed"
 )
 
 // Default configuration
@@ -34,16 +39,53 @@ type Event struct {
 // Handler is a function that handles events
 type Handler func(Event)
 
+// DefaultPriority is the priority used by Subscribe
+const DefaultPriority = 0
+
+// subscription is a handler registered with a priority
+type subscription struct {
+	priority int
+	handler  Handler
+}
+
+// Filter inspects an event before any subscriber sees it.
+// Returning false marks the event as consumed and stops further dispatch.
+type Filter func(Event) bool
+
+// filterEntry is a filter registered with a priority
+type filterEntry struct {
+	priority int
+	filter   Filter
+}
+
+// gate runs the filters for one published event exactly once, no matter
+// how many handlers (and workers) the event fans out to
+type gate struct {
+	once    sync.Once
+	filters []filterEntry
+	pass    bool
+}
+
 // work represents a unit of work for the worker pool
 type work struct {
-	event   Event
-	handler Handler
+	event Event
+	sub   subscription
+	gate  *gate
 }
 
-// Bus provides event routing with a bounded worker pool
+// Bus provides event routing with a bounded worker pool.
+//
+// Every subscriber of an event gets its own unit of work, so a slow handler
+// does not hold up the others. Work is queued in priority order (highest
+// first; equal priorities in subscription order), so with a single worker
+// handlers run strictly in that order; with more workers that is the order
+// they start in. Filters run first, once per event, on the worker that picks
+// the event up. Handlers run on bus worker goroutines and must be safe for
+// concurrent use.
 type Bus struct {
 	mu       sync.RWMutex
-	handlers map[EventType][]Handler
+	handlers map[EventType][]subscription
+	filters  map[EventType][]filterEntry
 
 	// Worker pool
 	workQueue chan work
@@ -63,7 +105,8 @@ func NewBus() *Bus {
 // NewBusWithConfig creates a new event bus with custom worker count and queue size
 func NewBusWithConfig(workerCount, queueSize int) *Bus {
 	b := &Bus{
-		handlers:  make(map[EventType][]Handler),
+		handlers:  make(map[EventType][]subscription),
+		filters:   make(map[EventType][]filterEntry),
 		workQueue: make(chan work, queueSize),
 		closing:   make(chan struct{}),
 	}
@@ -83,48 +126,140 @@ func (b *Bus) worker(id int) {
 	defer b.wg.Done()
 
 	for w := range b.workQueue {
+		if w.gate != nil && !w.gate.open(id, w.event) {
+			continue
+		}
 		func() {
 			defer func() {
 				if r := recover(); r != nil {
 					log.Error().
 						Interface("panic", r).
 						Str("event_type", string(w.event.Type)).
+						Int("priority", w.sub.priority).
 						Int("worker", id).
 						Msg("Event handler panicked")
 				}
 			}()
-			w.handler(w.event)
+			w.sub.handler(w.event)
 		}()
 	}
 }
 
-// Subscribe registers a handler for a specific event type
+// open runs the filters on first use and reports whether the event passed them.
+// A panicking filter lets the event through.
+func (g *gate) open(workerID int, event Event) bool {
+	g.once.Do(func() {
+		g.pass = true
+		for _, f := range g.filters {
+			if !runFilter(workerID, event, f) {
+				g.pass = false
+				log.Debug().
+					Str("event_type", string(event.Type)).
+					Int("priority", f.priority).
+					Msg("Event consumed by filter")
+				return
+			}
+		}
+	})
+	return g.pass
+}
+
+func runFilter(workerID int, event Event, f filterEntry) (pass bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().
+				Interface("panic", r).
+				Str("event_type", string(event.Type)).
+				Int("priority", f.priority).
+				Int("worker", workerID).
+				Msg("Event filter panicked")
+			pass = true
+		}
+	}()
+	return f.filter(event)
+}
+
+// Subscribe registers a handler for a specific event type at DefaultPriority
 func (b *Bus) Subscribe(eventType EventType, handler Handler) {
+	b.SubscribeWithPriority(eventType, DefaultPriority, handler)
+}
+
+// SubscribeWithPriority registers a handler that is dispatched before handlers
+// with a lower priority for the same event. Handlers run on bus worker goroutines.
+func (b *Bus) SubscribeWithPriority(eventType EventType, priority int, handler Handler) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.handlers[eventType] = append(b.handlers[eventType], handler)
+	// Copy-on-write so Publish can use the slice without holding the lock
+	subs := make([]subscription, 0, len(b.handlers[eventType])+1)
+	subs = append(subs, b.handlers[eventType]...)
+	subs = append(subs, subscription{priority: priority, handler: handler})
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].priority > subs[j].priority
+	})
+	b.handlers[eventType] = subs
+}
+
+// AddFilter registers a filter that runs before every subscriber of the event
+// type. Filters run in priority order (highest first; equal priorities in the
+// order they were added); if one returns false, no subscriber sees the event.
+// Filters run on bus worker goroutines, so keep them cheap.
+func (b *Bus) AddFilter(eventType EventType, priority int, filter Filter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Copy-on-write so Publish can use the slice without holding the lock
+	filters := make([]filterEntry, 0, len(b.filters[eventType])+1)
+	filters = append(filters, b.filters[eventType]...)
+	filters = append(filters, filterEntry{priority: priority, filter: filter})
+	sort.SliceStable(filters, func(i, j int) bool {
+		return filters[i].priority > filters[j].priority
+	})
+	b.filters[eventType] = filters
 }
 
 // Publish sends an event to all subscribed handlers.
+// Events without a trace ID get a new one (see TraceIDKey).
 // Non-blocking: if the work queue is full or bus is closing, events are dropped.
 // Uses channel-based signaling for race-free shutdown detection.
 func (b *Bus) Publish(event Event) {
 	b.mu.RLock()
 	handlers := b.handlers[event.Type]
+	filters := b.filters[event.Type]
 	b.mu.RUnlock()
 
-	for _, handler := range handlers {
+	if len(handlers) == 0 {
+		return
+	}
+
+	if event.TraceID() == "" {
+		// Copy before adding the trace ID; the caller may still hold Data
+		data := make(map[string]interface{}, len(event.Data)+1)
+		maps.Copy(data, event.Data)
+		data[TraceIDKey] = NewTraceID()
+		event.Data = data
+	}
+
+	var g *gate
+	if len(filters) > 0 {
+		g = &gate{filters: filters}
+	}
+
+	for _, sub := range handlers {
 		select {
 		case <-b.closing:
-			log.Warn().Str("event_type", string(event.Type)).Msg("Event bus closing, dropping event")
+			log.Warn().
+				Str("event_type", string(event.Type)).
+				Str("trace_id", event.TraceID()).
+				Msg("Event bus closing, dropping event")
 			return
-		case b.workQueue <- work{event: event, handler: handler}:
+		case b.workQueue <- work{event: event, sub: sub, gate: g}:
 			// Successfully queued
 		default:
 			// Queue full - drop event with warning
 			log.Warn().
 				Str("event_type", string(event.Type)).
+				Str("trace_id", event.TraceID()).
 				Msg("Event bus queue full, dropping event")
 		}
 	}
@@ -132,14 +267,15 @@ func (b *Bus) Publish(event Event) {
 
 // Close shuts down the worker pool gracefully.
 // First signals publishers to stop, then closes the work queue and waits for workers.
+// Safe to call more than once.
 func (b *Bus) Close(ctx context.Context) {
-	// Signal publishers to stop sending
 	b.closeOnce.Do(func() {
+		// Signal publishers to stop sending
 		close(b.closing)
-	})
 
-	// Now it's safe to close the work queue - no new sends after closing is signaled
-	close(b.workQueue)
+		// Now it's safe to close the work queue - no new sends after closing is signaled
+		close(b.workQueue)
+	})
 
 	// Wait for workers to finish with timeout
 	done := make(chan struct{})
@@ -156,10 +292,11 @@ func (b *Bus) Close(ctx context.Context) {
 	}
 }
 
-// Clear removes all handlers
+// Clear removes all handlers and filters
 func (b *Bus) Clear() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.handlers = make(map[EventType][]Handler)
+	b.handlers = make(map[EventType][]subscription)
+	b.filters = make(map[EventType][]filterEntry)
 }
@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBus_SlowHandlerDoesNotBlockOthers(t *testing.T) {
+	b := NewBusWithConfig(2, 10)
+	defer b.Close(context.Background())
+
+	release := make(chan struct{})
+	defer close(release)
+	b.Subscribe(EventTypeButton, func(Event) { <-release })
+
+	ran := make(chan struct{}, 1)
+	b.Subscribe(EventTypeButton, func(Event) { ran <- struct{}{} })
+
+	b.Publish(Event{Type: EventTypeButton})
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("second handler did not run while the first was blocked")
+	}
+}
+
+func TestBus_FiltersRunInPriorityOrderOncePerEvent(t *testing.T) {
+	b := NewBusWithConfig(4, 10)
+
+	var order []string
+	b.AddFilter(EventTypeButton, 0, func(Event) bool { order = append(order, "low"); return true })
+	b.AddFilter(EventTypeButton, 10, func(Event) bool { order = append(order, "high"); return true })
+	b.AddFilter(EventTypeButton, 0, func(Event) bool { order = append(order, "low2"); return true })
+
+	handled := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		b.Subscribe(EventTypeButton, func(Event) { handled <- struct{}{} })
+	}
+
+	b.Publish(Event{Type: EventTypeButton})
+	for i := 0; i < 3; i++ {
+		select {
+		case <-handled:
+		case <-time.After(time.Second):
+			t.Fatalf("handler %d did not run", i)
+		}
+	}
+	b.Close(context.Background())
+
+	want := []string{"high", "low", "low2"}
+	if len(order) != len(want) {
+		t.Fatalf("filters ran %v, want %v once each", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("filters ran %v, want %v", order, want)
+		}
+	}
+}
+
+func TestBus_FilterConsumesEvent(t *testing.T) {
+	b := NewBusWithConfig(2, 10)
+
+	lowRan := false
+	b.AddFilter(EventTypeButton, 10, func(e Event) bool { return e.Data["action"] != "short_release" })
+	b.AddFilter(EventTypeButton, 0, func(Event) bool { lowRan = true; return true })
+
+	handled := make(chan string, 2)
+	b.Subscribe(EventTypeButton, func(e Event) { handled <- e.Data["action"].(string) })
+	b.Subscribe(EventTypeRotary, func(Event) { handled <- "rotary" })
+
+	b.Publish(Event{Type: EventTypeButton, Data: map[string]interface{}{"action": "short_release"}})
+	b.Publish(Event{Type: EventTypeRotary})
+	b.Close(context.Background())
+	close(handled)
+
+	var got []string
+	for h := range handled {
+		got = append(got, h)
+	}
+	if len(got) != 1 || got[0] != "rotary" {
+		t.Errorf("handled %v, want only the unfiltered rotary event", got)
+	}
+	if lowRan {
+		t.Error("lower-priority filter ran after the event was consumed")
+	}
+}
+
+func TestBus_PublishDoesNotMutateCallerData(t *testing.T) {
+	b := NewBusWithConfig(1, 10)
+	defer b.Close(context.Background())
+
+	got := make(chan Event, 1)
+	b.Subscribe(EventTypeButton, func(e Event) { got <- e })
+
+	data := map[string]interface{}{"button_id": "b1"}
+	b.Publish(Event{Type: EventTypeButton, Data: data})
+
+	select {
+	case e := <-got:
+		if e.TraceID() == "" {
+			t.Error("handler event has no trace ID")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("handler did not run")
+	}
+	if _, ok := data[TraceIDKey]; ok {
+		t.Errorf("Publish() added %s to the caller's data", TraceIDKey)
+	}
+}
+
+func TestBus_SubscribeWithPriorityDispatchOrder(t *testing.T) {
+	// A single worker runs handlers strictly in dispatch order
+	b := NewBusWithConfig(1, 10)
+	defer b.Close(context.Background())
+
+	ran := make(chan string, 4)
+	b.Subscribe(EventTypeButton, func(Event) { ran <- "default" })
+	b.SubscribeWithPriority(EventTypeButton, 10, func(Event) { ran <- "high" })
+	b.SubscribeWithPriority(EventTypeButton, -5, func(Event) { ran <- "low" })
+	b.Subscribe(EventTypeButton, func(Event) { ran <- "default2" })
+
+	b.Publish(Event{Type: EventTypeButton})
+
+	want := []string{"high", "default", "default2", "low"}
+	for i, w := range want {
+		select {
+		case got := <-ran:
+			if got != w {
+				t.Fatalf("handler %d = %q, want %q (order %v)", i, got, w, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for handler %d", i)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// Package filter installs Lua event filters on the event bus.
+package filter
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	glua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/lua/exec"
+)
+
+// Filter is a Lua function that can swallow events of one type
+type Filter struct {
+	EventType events.EventType
+	Priority  int
+	Fn        *glua.LFunction
+}
+
+// Registry provides the registered filters
+type Registry interface {
+	GetEventFilters() []Filter
+}
+
+// RegisterFilters adds every registered filter to the event bus.
+// Each filter call waits for the Lua VM; if the VM cannot take the call
+// (queue full or shutting down) the event is let through.
+func RegisterFilters(
+	ctx context.Context,
+	registry Registry,
+	bus *events.Bus,
+	luaExec exec.Executor,
+) {
+	for _, f := range registry.GetEventFilters() {
+		fn := f.Fn
+		bus.AddFilter(f.EventType, f.Priority, func(event events.Event) bool {
+			result := make(chan bool, 1)
+			queued := luaExec.Do(ctx, func(context.Context) {
+				result <- exec.CallFilter(luaExec.LState(), fn, event.Data)
+			})
+			if !queued {
+				return true
+			}
+
+			select {
+			case pass := <-result:
+				if !pass {
+					log.Info().
+						Str("event_type", string(event.Type)).
+						Str("trace_id", event.TraceID()).
+						Msg("Event swallowed by filter")
+				}
+				return pass
+			case <-ctx.Done():
+				return true
+			}
+		})
+		log.Debug().Str("event_type", string(f.EventType)).Int("priority", f.Priority).Msg("Registered event filter")
+	}
+}
@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	glua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/events"
+)
+
+// inlineExecutor runs work immediately on the caller's goroutine
+type inlineExecutor struct{ L *glua.LState }
+
+func (e inlineExecutor) Do(ctx context.Context, work func(ctx context.Context)) bool {
+	work(ctx)
+	return true
+}
+
+func (e inlineExecutor) LState() *glua.LState { return e.L }
+
+type registry []Filter
+
+func (r registry) GetEventFilters() []Filter { return r }
+
+func TestRegisterFilters_LuaFilter(t *testing.T) {
+	L := glua.NewState()
+	defer L.Close()
+
+	if err := L.DoString(`
+		night = function(e) return e.action ~= "short_release" end
+		broken = function(e) error("boom") end
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	b := events.NewBusWithConfig(1, 10)
+	RegisterFilters(context.Background(), registry{
+		{EventType: events.EventTypeButton, Fn: L.GetGlobal("night").(*glua.LFunction)},
+		{EventType: events.EventTypeRotary, Fn: L.GetGlobal("broken").(*glua.LFunction)},
+	}, b, inlineExecutor{L})
+
+	handled := make(chan string, 3)
+	b.Subscribe(events.EventTypeButton, func(e events.Event) { handled <- e.Data["action"].(string) })
+	b.Subscribe(events.EventTypeRotary, func(events.Event) { handled <- "rotary" })
+
+	b.Publish(events.Event{Type: events.EventTypeButton, Data: map[string]interface{}{"action": "short_release"}})
+	b.Publish(events.Event{Type: events.EventTypeButton, Data: map[string]interface{}{"action": "long_press"}})
+	b.Publish(events.Event{Type: events.EventTypeRotary})
+	b.Close(context.Background())
+	close(handled)
+
+	var got []string
+	for h := range handled {
+		got = append(got, h)
+	}
+	if len(got) != 2 || got[0] != "long_press" || got[1] != "rotary" {
+		t.Errorf("handled %v, want [long_press rotary] (swallowed short_release, failing filter passes)", got)
+	}
+}
@@ -1,5 +1,11 @@
 package middleware
 
+import (
+	"context"
+
+	"github.com/dokzlo13/lightd/internal/events"
+)
+
 // FlushFunc is called when collector flushes events
 type FlushFunc func(events []map[string]any)
 
@@ -8,3 +14,15 @@ type Collector interface {
 	AddEvent(event map[string]any)
 	Close()
 }
+
+// TraceContext returns ctx carrying the trace ID of the most recent collected
+// event that has one (see events.TraceIDKey), so the action run for a flush
+// is logged under the trace of the event that completed it.
+func TraceContext(ctx context.Context, collected []map[string]any) context.Context {
+	for i := len(collected) - 1; i >= 0; i-- {
+		if id, _ := collected[i][events.TraceIDKey].(string); id != "" {
+			return events.WithTraceID(ctx, id)
+		}
+	}
+	return ctx
+}
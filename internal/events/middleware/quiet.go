@@ -5,13 +5,19 @@ import (
 	"time"
 )
 
+// maxAdaptiveGap bounds how far apart two events may be and still count as
+// one continuous burst for an adaptive QuietCollector
+const maxAdaptiveGap = time.Second
+
 // QuietCollector flushes after a quiet period (no new events for N ms)
 type QuietCollector struct {
-	mu      sync.Mutex
-	events  []map[string]any
-	timer   *time.Timer
-	quietMs int
-	onFlush FlushFunc
+	mu        sync.Mutex
+	events    []map[string]any
+	timer     *time.Timer
+	quietMs   int
+	adaptive  bool      // widen the quiet period to the gap between events
+	lastEvent time.Time // arrival of the previous event (adaptive only)
+	onFlush   FlushFunc
 }
 
 // NewQuietCollector creates a new QuietCollector
@@ -22,6 +28,16 @@ func NewQuietCollector(quietMs int, onFlush FlushFunc) *QuietCollector {
 	}
 }
 
+// NewAdaptiveQuietCollector creates a QuietCollector whose quiet period widens
+// while events keep arriving. Devices that report a continuous gesture as
+// events spaced further apart than quietMs (e.g. a rotary dial during a long
+// spin) are then flushed once when the gesture stops, not once per event.
+func NewAdaptiveQuietCollector(quietMs int, onFlush FlushFunc) *QuietCollector {
+	c := NewQuietCollector(quietMs, onFlush)
+	c.adaptive = true
+	return c
+}
+
 // AddEvent adds an event and resets the quiet timer
 func (c *QuietCollector) AddEvent(event map[string]any) {
 	c.mu.Lock()
@@ -32,7 +48,25 @@ func (c *QuietCollector) AddEvent(event map[string]any) {
 	if c.timer != nil {
 		c.timer.Stop()
 	}
-	c.timer = time.AfterFunc(time.Duration(c.quietMs)*time.Millisecond, c.flush)
+	c.timer = time.AfterFunc(c.quietPeriod(), c.flush)
+}
+
+// quietPeriod returns the configured quiet period. An adaptive collector
+// stretches it to 1.5x the gap since the previous event while the events
+// form a burst, so the timer only fires once the burst has clearly ended.
+func (c *QuietCollector) quietPeriod() time.Duration {
+	quiet := time.Duration(c.quietMs) * time.Millisecond
+	if !c.adaptive {
+		return quiet
+	}
+
+	now := time.Now()
+	gap := now.Sub(c.lastEvent)
+	c.lastEvent = now
+	if gap <= maxAdaptiveGap && gap*3/2 > quiet {
+		return gap * 3 / 2
+	}
+	return quiet
 }
 
 // flush sends accumulated events to the flush callback
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottleCollector forwards at most one event per window to the next
+// collector. The first event passes through immediately; events arriving
+// within the window are coalesced and only the latest is forwarded when the
+// window ends.
+type ThrottleCollector struct {
+	mu      sync.Mutex
+	window  time.Duration
+	timer   *time.Timer // non-nil while a window is open
+	latest  map[string]any
+	pending bool
+	closed  bool
+	next    Collector
+}
+
+// NewThrottleCollector creates a new ThrottleCollector feeding next
+func NewThrottleCollector(window time.Duration, next Collector) *ThrottleCollector {
+	return &ThrottleCollector{
+		window: window,
+		next:   next,
+	}
+}
+
+// AddEvent forwards the event now if no window is open, otherwise keeps it
+// as the latest pending event
+func (c *ThrottleCollector) AddEvent(event map[string]any) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	if c.timer != nil {
+		c.latest = event
+		c.pending = true
+		c.mu.Unlock()
+		return
+	}
+	c.timer = time.AfterFunc(c.window, c.tick)
+	c.mu.Unlock()
+
+	c.next.AddEvent(event)
+}
+
+// tick closes the window, forwarding the latest pending event (which opens
+// a new window) if there is one
+func (c *ThrottleCollector) tick() {
+	c.mu.Lock()
+	if c.closed || !c.pending {
+		c.timer = nil
+		c.mu.Unlock()
+		return
+	}
+	event := c.latest
+	c.latest = nil
+	c.pending = false
+	c.timer = time.AfterFunc(c.window, c.tick)
+	c.mu.Unlock()
+
+	c.next.AddEvent(event)
+}
+
+// Close stops the timer, drops any pending event and closes the next collector
+func (c *ThrottleCollector) Close() {
+	c.mu.Lock()
+	c.closed = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mu.Unlock()
+
+	c.next.Close()
+}
@@ -0,0 +1,62 @@
+// Package mode provides event handling for mode change events.
+package mode
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/lua/exec"
+)
+
+// Handler is an action invoked when the mode changes
+type Handler struct {
+	ActionName string
+	ActionArgs map[string]any
+}
+
+// HandlerRegistry provides handler lookup functions
+type HandlerRegistry interface {
+	GetChangeHandlers() []Handler
+}
+
+// RegisterHandlers subscribes to mode change events on the event bus and dispatches to handlers.
+func RegisterHandlers(
+	ctx context.Context,
+	registry HandlerRegistry,
+	bus *events.Bus,
+	invoker *actions.Invoker,
+	luaExec exec.Executor,
+) {
+	bus.Subscribe(events.EventTypeModeChange, func(event events.Event) {
+		newMode, _ := event.Data["mode"].(string)
+		previous, _ := event.Data["previous"].(string)
+
+		for _, handler := range registry.GetChangeHandlers() {
+			log.Info().
+				Str("trigger", "mode_change").
+				Str("mode", newMode).
+				Str("previous", previous).
+				Str("action", handler.ActionName).
+				Str("trace_id", event.TraceID()).
+				Msg("Action triggered by mode change")
+
+			args := make(map[string]any, len(handler.ActionArgs)+2)
+			for k, v := range handler.ActionArgs {
+				args[k] = v
+			}
+			args["mode"] = newMode
+			args["previous"] = previous
+
+			actionName := handler.ActionName
+			traceID := event.TraceID()
+			luaExec.Do(ctx, func(workCtx context.Context) {
+				if err := invoker.InvokeWithSource(events.WithTraceID(workCtx, traceID), actionName, args, "", "mode", ""); err != nil {
+					log.Error().Err(err).Str("action", actionName).Msg("Failed to invoke mode change action")
+				}
+			})
+		}
+	})
+}
@@ -9,8 +9,14 @@ import (
 	"github.com/dokzlo13/lightd/internal/actions"
 	"github.com/dokzlo13/lightd/internal/events"
 	"github.com/dokzlo13/lightd/internal/lua/exec"
+	"github.com/dokzlo13/lightd/internal/scheduler"
 )
 
+// SyncExecutor runs work on the Lua VM and waits for its result.
+type SyncExecutor interface {
+	DoSyncWithResult(ctx context.Context, work func(ctx context.Context) error) error
+}
+
 // RegisterHandler subscribes to schedule events on the event bus and dispatches to the invoker.
 func RegisterHandler(
 	ctx context.Context,
@@ -31,6 +37,7 @@ func RegisterHandler(
 			Str("action", actionName).
 			Str("occurrence_id", occurrenceID).
 			Str("source", source).
+			Str("trace_id", event.TraceID()).
 			Msg("Action triggered by schedule")
 
 		// Capture values for closure
@@ -38,10 +45,15 @@ func RegisterHandler(
 		aArgs := actionArgs
 		occID := occurrenceID
 		sID := scheduleID
+		src := source
+		if src == "" {
+			src = "scheduler"
+		}
+		traceID := event.TraceID()
 
 		// Queue work to Lua worker (single-threaded execution)
 		luaExec.Do(ctx, func(workCtx context.Context) {
-			err := invoker.InvokeWithSource(workCtx, aName, aArgs, occID, "scheduler", sID)
+			err := invoker.InvokeWithSource(events.WithTraceID(workCtx, traceID), aName, aArgs, occID, src, sID)
 			if err != nil {
 				log.Error().Err(err).
 					Str("action", aName).
@@ -52,3 +64,15 @@ func RegisterHandler(
 		})
 	})
 }
+
+// NewSyncInvoker returns a scheduler.SyncInvoker that runs the action on the
+// Lua worker and waits for it, skipping the bus hop. Used for schedules
+// defined with sync = true.
+func NewSyncInvoker(invoker *actions.Invoker, luaExec SyncExecutor) scheduler.SyncInvoker {
+	return func(ctx context.Context, actionName string, args map[string]any, occurrenceID, source, scheduleID string) error {
+		traceID := events.NewTraceID()
+		return luaExec.DoSyncWithResult(ctx, func(workCtx context.Context) error {
+			return invoker.InvokeWithSource(events.WithTraceID(workCtx, traceID), actionName, args, occurrenceID, source, scheduleID)
+		})
+	}
+}
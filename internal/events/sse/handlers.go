@@ -3,6 +3,7 @@ package sse
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -17,6 +18,8 @@ type HandlerRegistry interface {
 	FindButtonHandler(resourceID, buttonAction string) *ButtonHandler
 	FindConnectivityHandler(deviceID, status string) *ConnectivityHandler
 	FindRotaryHandler(resourceID string) *RotaryHandler
+	// FindLightChangeHandlers returns matches in dispatch order: higher
+	// Priority first, then registration order.
 	FindLightChangeHandlers(resourceID, resourceType string) []*LightChangeHandler
 }
 
@@ -146,6 +149,7 @@ func registerButtonHandler(
 			Str("resource_id", resourceID).
 			Str("button_action", buttonAction).
 			Str("action", handler.ActionName).
+			Str("trace_id", event.TraceID()).
 			Msg("Action triggered by button press")
 
 		// Build collector key
@@ -161,6 +165,7 @@ func registerButtonHandler(
 			"resource_id": resourceID,
 			"action":      buttonAction,
 			"event_id":    eventID,
+			"trace_id":    event.TraceID(),
 		})
 	})
 }
@@ -195,9 +200,10 @@ func createButtonCollector(
 			delete(args, "event_id")
 			delete(args, "resource_id")
 			delete(args, "action")
+			delete(args, "trace_id")
 
 			// Invoke action with button event ID as idempotency key
-			invoker.Invoke(workCtx, handler.ActionName, args, eid)
+			invoker.Invoke(middleware.TraceContext(workCtx, events), handler.ActionName, args, eid)
 		})
 	}
 
@@ -230,6 +236,7 @@ func registerConnectivityHandler(
 			Str("device_id", deviceID).
 			Str("status", status).
 			Str("action", handler.ActionName).
+			Str("trace_id", event.TraceID()).
 			Msg("Action triggered by connectivity change")
 
 		// Build collector key
@@ -244,6 +251,7 @@ func registerConnectivityHandler(
 		collector.AddEvent(map[string]any{
 			"device_id": deviceID,
 			"status":    status,
+			"trace_id":  event.TraceID(),
 		})
 	})
 }
@@ -276,8 +284,17 @@ func createConnectivityCollector(
 			// Remove event metadata from args
 			delete(args, "device_id")
 			delete(args, "status")
+			delete(args, "trace_id")
+
+			// Key on the triggering event so a flapping device reacts once per window
+			idempotencyKey := ""
+			if len(events) > 0 {
+				deviceID, _ := events[0]["device_id"].(string)
+				status, _ := events[0]["status"].(string)
+				idempotencyKey = DedupeKey(handler.DedupeWindow, time.Now(), "connectivity", deviceID, status)
+			}
 
-			if err := invoker.Invoke(workCtx, handler.ActionName, args, ""); err != nil {
+			if err := invoker.InvokeWithSource(middleware.TraceContext(workCtx, events), handler.ActionName, args, idempotencyKey, "connectivity", ""); err != nil {
 				log.Error().Err(err).Str("action", handler.ActionName).Msg("Failed to invoke connectivity action")
 			}
 		})
@@ -308,23 +325,30 @@ func registerRotaryHandler(
 			return
 		}
 
-		log.Info().
+		logEvent := log.Info().
 			Str("trigger", "rotary").
 			Str("resource_id", resourceID).
 			Str("direction", direction).
 			Int("steps", steps).
 			Str("action", handler.ActionName).
-			Msg("Action triggered by rotary dial")
+			Str("trace_id", event.TraceID())
+		if handler.Target != nil {
+			logEvent = logEvent.
+				Str("target", string(handler.Target.Kind)).
+				Str("target_id", handler.Target.ID)
+		}
+		logEvent.Msg("Action triggered by rotary dial")
 
 		collector, ok := cache.Get(resourceID)
 		if !ok {
-			collector = createRotaryCollector(ctx, handler, invoker, luaExec)
+			collector = createRotaryCollector(ctx, resourceID, handler, invoker, luaExec)
 			cache.Set(resourceID, collector)
 		}
 
 		collector.AddEvent(map[string]any{
 			"direction": direction,
 			"steps":     steps,
+			"trace_id":  event.TraceID(),
 		})
 	})
 }
@@ -332,6 +356,7 @@ func registerRotaryHandler(
 // createRotaryCollector creates a collector for rotary events
 func createRotaryCollector(
 	ctx context.Context,
+	resourceID string,
 	handler *RotaryHandler,
 	invoker *actions.Invoker,
 	luaExec exec.Executor,
@@ -354,7 +379,20 @@ func createRotaryCollector(
 				args[k] = v
 			}
 
-			if err := invoker.Invoke(workCtx, handler.ActionName, args, ""); err != nil {
+			delete(args, "trace_id")
+
+			// Target is set after the reducer so it always reaches the action
+			if handler.Target != nil {
+				args["target"] = handler.Target.Args()
+			}
+
+			idempotencyKey := ""
+			if len(events) > 0 {
+				direction, _ := events[0]["direction"].(string)
+				idempotencyKey = DedupeKey(handler.DedupeWindow, time.Now(), "rotary", resourceID, direction)
+			}
+
+			if err := invoker.Invoke(middleware.TraceContext(workCtx, events), handler.ActionName, args, idempotencyKey); err != nil {
 				log.Error().Err(err).Str("action", handler.ActionName).Msg("Failed to invoke rotary action")
 			}
 		})
@@ -389,9 +427,12 @@ func registerLightChangeHandler(
 			Str("resource_id", resourceID).
 			Str("resource_type", resourceType).
 			Int("handler_count", len(handlers)).
+			Str("trace_id", event.TraceID()).
 			Msg("Action triggered by light change")
 
-		// Dispatch to all matching handlers
+		// Dispatch to all matching handlers in registry order (priority, then
+		// registration). Immediate handlers queue onto the single Lua worker in
+		// this order, so they also run in it.
 		for _, handler := range handlers {
 			// Use handler identity as key (action name + resource pattern)
 			key := handler.ActionName + ":" + handler.ResourceID.String() + ":" + handler.ResourceType.String()
@@ -441,15 +482,25 @@ func createLightChangeCollector(
 			for k, v := range handler.ActionArgs {
 				args[k] = v
 			}
+			delete(args, "trace_id")
 
-			if err := invoker.Invoke(workCtx, handler.ActionName, args, ""); err != nil {
+			if err := invoker.InvokeWithSource(middleware.TraceContext(workCtx, events), handler.ActionName, args, "", "light_change", ""); err != nil {
 				log.Error().Err(err).Str("action", handler.ActionName).Msg("Failed to invoke light change action")
 			}
 		})
 	}
 
+	var collector middleware.Collector
 	if handler.CollectorFactory != nil {
-		return handler.CollectorFactory.Create(onFlush)
+		collector = handler.CollectorFactory.Create(onFlush)
+	} else {
+		collector = middleware.NewImmediateCollector(onFlush)
 	}
-	return middleware.NewImmediateCollector(onFlush)
+
+	// Throttle in front of the collector: the handler is shared by every
+	// matching resource, so a room-wide change collapses into one event
+	if handler.Throttle > 0 {
+		return middleware.NewThrottleCollector(handler.Throttle, collector)
+	}
+	return collector
 }
@@ -2,6 +2,9 @@
 package sse
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/dokzlo13/lightd/internal/lua/modules/collect"
 )
 
@@ -29,14 +32,68 @@ type ConnectivityHandler struct {
 	ActionName       string
 	ActionArgs       map[string]any
 	CollectorFactory *collect.CollectorFactory // nil = immediate
+	DedupeWindow     time.Duration             // 0 = every event invokes the action
+}
+
+// DedupeKey builds an idempotency key shared by all events with the same
+// parts inside one window-sized time bucket, so the invoker runs the action
+// once per bucket. Returns "" (no dedupe) when window is 0.
+func DedupeKey(window time.Duration, now time.Time, parts ...string) string {
+	if window <= 0 {
+		return ""
+	}
+	bucket := now.UnixNano() / int64(window)
+	key := "sse"
+	for _, p := range parts {
+		key += ":" + p
+	}
+	return fmt.Sprintf("%s:%d", key, bucket)
+}
+
+// RotaryTargetKind is the kind of resource a rotary dial controls.
+type RotaryTargetKind string
+
+const (
+	RotaryTargetLight RotaryTargetKind = "light"
+	RotaryTargetGroup RotaryTargetKind = "group"
+)
+
+// RotaryTarget identifies the light or group a rotary dial controls.
+type RotaryTarget struct {
+	Kind RotaryTargetKind
+	ID   string
+}
+
+// ParseRotaryTarget validates a target kind ("light" or "group") and ID.
+func ParseRotaryTarget(kind, id string) (*RotaryTarget, error) {
+	switch RotaryTargetKind(kind) {
+	case RotaryTargetLight, RotaryTargetGroup:
+	default:
+		return nil, fmt.Errorf("invalid rotary target %q (expected \"light\" or \"group\")", kind)
+	}
+	if id == "" {
+		return nil, fmt.Errorf("rotary target %q requires an id", kind)
+	}
+	return &RotaryTarget{Kind: RotaryTargetKind(kind), ID: id}, nil
+}
+
+// Args returns the target in the same shape it is configured in Lua:
+// {target = "light"|"group", id = "..."}.
+func (t *RotaryTarget) Args() map[string]any {
+	return map[string]any{
+		"target": string(t.Kind),
+		"id":     t.ID,
+	}
 }
 
 // RotaryHandler is called when a rotary event occurs
 type RotaryHandler struct {
-	ResourceID       Matcher // Matches rotary resource ID ("*" for any)
+	ResourceID       Matcher       // Matches rotary resource ID ("*" for any)
+	Target           *RotaryTarget // Light or group the dial controls (nil = unspecified)
 	ActionName       string
 	ActionArgs       map[string]any
 	CollectorFactory *collect.CollectorFactory // nil = immediate
+	DedupeWindow     time.Duration             // 0 = every event invokes the action
 }
 
 // LightChangeHandler is called when a light state changes (brightness, power, color, etc.)
@@ -46,4 +103,6 @@ type LightChangeHandler struct {
 	ActionName       string
 	ActionArgs       map[string]any
 	CollectorFactory *collect.CollectorFactory // nil = immediate
+	Throttle         time.Duration             // 0 = every event reaches the collector
+	Priority         int                       // higher runs first among handlers matching the same event
 }
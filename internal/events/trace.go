@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// TraceIDKey is the Event.Data key holding the event's trace ID. Publish sets
+// it on events that don't carry one, so every action, ledger entry and
+// reconcile caused by an event can be correlated in the logs.
+const TraceIDKey = "trace_id"
+
+// NewTraceID returns a short random trace ID (8 hex characters).
+func NewTraceID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// TraceID returns the event's trace ID, or "" if it has none.
+func (e Event) TraceID() string {
+	id, _ := e.Data[TraceIDKey].(string)
+	return id
+}
+
+// traceIDContextKey is the key used to store the trace ID in Go's context.Context
+type traceIDContextKey struct{}
+
+// WithTraceID returns a copy of ctx carrying the trace ID. An empty ID
+// returns ctx unchanged.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	if traceID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, traceIDContextKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID stored in ctx, or "" if none.
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDContextKey{}).(string)
+	return id
+}
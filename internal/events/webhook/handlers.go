@@ -45,6 +45,10 @@ func RegisterHandlers(
 				Msg("No webhook handler found for request")
 			return
 		}
+		if match.Handler.Respond != nil {
+			// Answered synchronously by the Responder, nothing to invoke
+			return
+		}
 
 		log.Info().
 			Str("trigger", "webhook").
@@ -52,6 +56,7 @@ func RegisterHandlers(
 			Str("path", path).
 			Str("action", match.Handler.ActionName).
 			Interface("path_params", match.PathParams).
+			Str("trace_id", event.TraceID()).
 			Msg("Action triggered by webhook request")
 
 		// Build collector key from method and registered path pattern
@@ -85,6 +90,7 @@ func RegisterHandlers(
 			"headers":     headersAny,
 			"path_params": match.PathParams,
 			"event_id":    eventID,
+			"trace_id":    event.TraceID(),
 		})
 	})
 }
@@ -131,6 +137,7 @@ func createWebhookCollector(
 			delete(args, "headers")
 			delete(args, "path_params")
 			delete(args, "event_id")
+			delete(args, "trace_id")
 
 			// Convert headers back to map[string]interface{}
 			headersIface := make(map[string]interface{})
@@ -155,7 +162,7 @@ func createWebhookCollector(
 			}
 
 			// Inject request data into context for the RequestModule to extract
-			ctxWithRequest := context.WithValue(workCtx, luactx.RequestContextKey, requestData)
+			ctxWithRequest := context.WithValue(middleware.TraceContext(workCtx, events), luactx.RequestContextKey, requestData)
 			if err := invoker.Invoke(ctxWithRequest, handler.ActionName, args, eventID); err != nil {
 				log.Error().Err(err).Str("action", handler.ActionName).Msg("Failed to invoke webhook action")
 			}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/lua/exec"
+	webhookserver "github.com/dokzlo13/lightd/internal/webhook"
+)
+
+// Responder runs handlers registered with webhook.respond() and turns their
+// return values into HTTP responses. Implements webhookserver.Responder.
+type Responder struct {
+	registry HandlerRegistry
+	luaExec  exec.Executor
+}
+
+// NewResponder creates a responder for the handlers in registry
+func NewResponder(registry HandlerRegistry, luaExec exec.Executor) *Responder {
+	return &Responder{registry: registry, luaExec: luaExec}
+}
+
+// responderResult is what a responder function returned
+type responderResult struct {
+	body   any
+	status int
+	err    error
+}
+
+// Respond calls the matching responder function on the Lua VM and waits for
+// its result. A table becomes a JSON body, a string a text body and nil an
+// empty one; the optional second return value is the status code.
+func (r *Responder) Respond(ctx context.Context, request map[string]interface{}) (webhookserver.Response, bool) {
+	method, _ := request["method"].(string)
+	path, _ := request["path"].(string)
+
+	match := r.registry.FindHandler(method, path)
+	if match == nil || match.Handler.Respond == nil {
+		return webhookserver.Response{}, false
+	}
+
+	params := make(map[string]any, len(match.PathParams))
+	for k, v := range match.PathParams {
+		params[k] = v
+	}
+	req := make(map[string]any, len(request)+1)
+	for k, v := range request {
+		req[k] = v
+	}
+	req["path_params"] = params
+
+	fn := match.Handler.Respond
+	done := make(chan responderResult, 1)
+	if !r.luaExec.Do(ctx, func(context.Context) {
+		body, status, err := exec.CallResponder(r.luaExec.LState(), fn, req)
+		done <- responderResult{body: body, status: status, err: err}
+	}) {
+		return errorResponse(http.StatusServiceUnavailable, "lua runtime busy"), true
+	}
+
+	var res responderResult
+	select {
+	case res = <-done:
+	case <-ctx.Done():
+		log.Warn().Str("method", method).Str("path", path).Msg("Webhook responder timed out")
+		return errorResponse(http.StatusGatewayTimeout, "handler timed out"), true
+	}
+
+	if res.err != nil {
+		log.Error().Err(res.err).Str("method", method).Str("path", path).Msg("Webhook responder failed")
+		return errorResponse(http.StatusInternalServerError, res.err.Error()), true
+	}
+
+	log.Info().
+		Str("trigger", "webhook").
+		Str("method", method).
+		Str("path", path).
+		Int("status", res.status).
+		Msg("Webhook answered by responder")
+
+	return buildResponse(res.body, res.status), true
+}
+
+// buildResponse encodes a responder's return values. status 0 means 200, or
+// 204 for an empty body.
+func buildResponse(body any, status int) webhookserver.Response {
+	resp := webhookserver.Response{Status: status}
+
+	switch b := body.(type) {
+	case nil:
+		if resp.Status == 0 {
+			resp.Status = http.StatusNoContent
+		}
+		return resp
+	case string:
+		resp.ContentType = "text/plain; charset=utf-8"
+		resp.Body = []byte(b)
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return errorResponse(http.StatusInternalServerError, "response is not JSON-encodable: "+err.Error())
+		}
+		resp.ContentType = "application/json"
+		resp.Body = data
+	}
+
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	return resp
+}
+
+func errorResponse(status int, msg string) webhookserver.Response {
+	body, _ := json.Marshal(map[string]string{"error": msg})
+	return webhookserver.Response{Status: status, ContentType: "application/json", Body: body}
+}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	glua "github.com/yuin/gopher-lua"
+)
+
+// inlineExecutor runs work immediately on the caller's goroutine
+type inlineExecutor struct{ L *glua.LState }
+
+func (e inlineExecutor) Do(ctx context.Context, work func(ctx context.Context)) bool {
+	work(ctx)
+	return true
+}
+
+func (e inlineExecutor) LState() *glua.LState { return e.L }
+
+type handlers []Handler
+
+func (h handlers) FindHandler(method, path string) *MatchResult {
+	for i := range h {
+		if h[i].Method != method {
+			continue
+		}
+		if params, ok := MatchPath(h[i].Path, path); ok {
+			return &MatchResult{Handler: &h[i], PathParams: params}
+		}
+	}
+	return nil
+}
+
+func TestResponder_Respond(t *testing.T) {
+	L := glua.NewState()
+	defer L.Close()
+
+	if err := L.DoString(`
+		state = function(req) return { mode = "home", group = req.path_params.id } end
+		created = function(req) return "made " .. req.json.name, 201 end
+		empty = function(req) end
+		broken = function(req) error("boom") end
+	`); err != nil {
+		t.Fatal(err)
+	}
+	fn := func(name string) *glua.LFunction { return L.GetGlobal(name).(*glua.LFunction) }
+
+	r := NewResponder(handlers{
+		{Method: "GET", Path: "/state/{id}", Respond: fn("state")},
+		{Method: "POST", Path: "/things", Respond: fn("created")},
+		{Method: "DELETE", Path: "/things", Respond: fn("empty")},
+		{Method: "GET", Path: "/broken", Respond: fn("broken")},
+		{Method: "POST", Path: "/action", ActionName: "toggle"},
+	}, inlineExecutor{L})
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		json        map[string]interface{}
+		wantStatus  int
+		wantType    string
+		wantBody    string
+		wantHandled bool
+	}{
+		{"table as JSON", "GET", "/state/5", nil, http.StatusOK, "application/json", `{"group":"5","mode":"home"}`, true},
+		{"string with status", "POST", "/things", map[string]interface{}{"name": "lamp"}, http.StatusCreated, "text/plain; charset=utf-8", "made lamp", true},
+		{"nil is empty", "DELETE", "/things", nil, http.StatusNoContent, "", "", true},
+		{"error is 500", "GET", "/broken", nil, http.StatusInternalServerError, "application/json", "", true},
+		{"action handlers are published", "POST", "/action", nil, 0, "", "", false},
+		{"no handler", "GET", "/missing", nil, 0, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, ok := r.Respond(context.Background(), map[string]interface{}{
+				"method": tt.method,
+				"path":   tt.path,
+				"json":   tt.json,
+			})
+			if ok != tt.wantHandled {
+				t.Fatalf("handled = %v, want %v", ok, tt.wantHandled)
+			}
+			if !ok {
+				return
+			}
+			if resp.Status != tt.wantStatus || resp.ContentType != tt.wantType {
+				t.Errorf("status %d %q, want %d %q", resp.Status, resp.ContentType, tt.wantStatus, tt.wantType)
+			}
+			if tt.wantBody != "" && string(resp.Body) != tt.wantBody {
+				t.Errorf("body %s, want %s", resp.Body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ package webhook
 import (
 	"strings"
 
+	glua "github.com/yuin/gopher-lua"
+
 	"github.com/dokzlo13/lightd/internal/lua/modules/collect"
 )
 
@@ -14,6 +16,10 @@ type Handler struct {
 	ActionName       string
 	ActionArgs       map[string]any
 	CollectorFactory *collect.CollectorFactory // nil = immediate
+
+	// Respond, when set, runs on the Lua VM while the request waits and its
+	// result is the HTTP response; no action is invoked
+	Respond *glua.LFunction
 }
 
 // MatchResult contains a matched handler and extracted path parameters
@@ -2,13 +2,17 @@ package geo
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
 	"net/http"
 	"net/url"
+	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -16,8 +20,34 @@ import (
 	"github.com/dokzlo13/lightd/internal/storage"
 )
 
-// Default HTTP client (timeout set per-request via context)
-var httpClient = &http.Client{}
+// Default HTTP client (timeout set per-request via context).
+// Uses a clone of http.DefaultTransport, which honors HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY and keeps the standard dial, TLS handshake and idle timeouts.
+var httpClient = &http.Client{
+	Transport: http.DefaultTransport.(*http.Transport).Clone(),
+}
+
+// newHTTPClient returns a geocoding client that honors proxy environment
+// variables and also trusts the PEM certificates in caFile.
+func newHTTPClient(caFile string) (*http.Client, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: transport}, nil
+}
 
 // AstroTimes contains astronomical times for a day
 type AstroTimes struct {
@@ -27,6 +57,29 @@ type AstroTimes struct {
 	Sunset   time.Time `json:"sunset"`
 	Dusk     time.Time `json:"dusk"`
 	Midnight time.Time `json:"midnight"`
+
+	// Set when the sun never sets (PolarDay) or never rises (PolarNight) on
+	// this date; Sunrise and Sunset are then meaningless.
+	PolarDay   bool `json:"polar_day,omitempty"`
+	PolarNight bool `json:"polar_night,omitempty"`
+
+	// Same for civil twilight (sun 6° below the horizon): set when the sun
+	// never drops below (CivilPolarDay) or never rises above (CivilPolarNight)
+	// it on this date; Dawn and Dusk are then meaningless.
+	CivilPolarDay   bool `json:"civil_polar_day,omitempty"`
+	CivilPolarNight bool `json:"civil_polar_night,omitempty"`
+}
+
+// IsDay reports whether t is between sunrise and sunset. It is always true
+// during polar day and always false during polar night.
+func (a *AstroTimes) IsDay(t time.Time) bool {
+	switch {
+	case a.PolarDay:
+		return true
+	case a.PolarNight:
+		return false
+	}
+	return !t.Before(a.Sunrise) && t.Before(a.Sunset)
 }
 
 // Calculator calculates astronomical times
@@ -45,6 +98,37 @@ type Calculator struct {
 
 	// HTTP timeout for geocoding requests
 	httpTimeout time.Duration
+
+	// HTTP client for geocoding requests (nil = package default)
+	httpClient *http.Client
+
+	// Cache and geocoding counters reported by Stats()
+	astroHits, astroMisses       atomic.Int64
+	locationHits, locationMisses atomic.Int64
+	geocodeCalls, geocodeErrors  atomic.Int64
+}
+
+// Stats are cumulative Calculator counters since startup. Location lookups
+// answered by pre-configured coordinates are not counted.
+type Stats struct {
+	AstroCacheHits      int64 `json:"astro_cache_hits"`
+	AstroCacheMisses    int64 `json:"astro_cache_misses"`
+	LocationCacheHits   int64 `json:"location_cache_hits"` // in-memory or persistent geocache
+	LocationCacheMisses int64 `json:"location_cache_misses"`
+	GeocodeCalls        int64 `json:"geocode_calls"`
+	GeocodeFailures     int64 `json:"geocode_failures"`
+}
+
+// Stats returns a snapshot of the cache and geocoding counters.
+func (c *Calculator) Stats() Stats {
+	return Stats{
+		AstroCacheHits:      c.astroHits.Load(),
+		AstroCacheMisses:    c.astroMisses.Load(),
+		LocationCacheHits:   c.locationHits.Load(),
+		LocationCacheMisses: c.locationMisses.Load(),
+		GeocodeCalls:        c.geocodeCalls.Load(),
+		GeocodeFailures:     c.geocodeErrors.Load(),
+	}
 }
 
 // Location represents a geocoded location
@@ -120,6 +204,29 @@ func NewCalculatorWithLocationAndCache(name string, lat, lon float64, timezone s
 	}
 }
 
+// SetCAFile makes geocoding requests trust the PEM certificates in path in
+// addition to the system roots, e.g. for a TLS-intercepting proxy.
+func (c *Calculator) SetCAFile(path string) error {
+	client, err := newHTTPClient(path)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.httpClient = client
+	c.mu.Unlock()
+	return nil
+}
+
+// client returns the HTTP client used for geocoding.
+func (c *Calculator) client() *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.httpClient != nil {
+		return c.httpClient
+	}
+	return httpClient
+}
+
 // GetTimes returns astronomical times for a location on a given date
 func (c *Calculator) GetTimes(locationName string, date time.Time, timezone string) (*AstroTimes, error) {
 	// Get location coordinates (use pre-configured if available)
@@ -141,8 +248,10 @@ func (c *Calculator) GetTimes(locationName string, date time.Time, timezone stri
 	cached, ok := c.cache[cacheKey]
 	c.mu.RUnlock()
 	if ok {
+		c.astroHits.Add(1)
 		return cached, nil
 	}
+	c.astroMisses.Add(1)
 
 	// Calculate times
 	times := c.calculate(loc.Latitude, loc.Longitude, date, tz)
@@ -168,6 +277,7 @@ func (c *Calculator) getLocation(name string) (*Location, error) {
 	cached, ok := c.locationCache[name]
 	c.mu.RUnlock()
 	if ok {
+		c.locationHits.Add(1)
 		return cached, nil
 	}
 
@@ -183,13 +293,17 @@ func (c *Calculator) getLocation(name string) (*Location, error) {
 			c.mu.Lock()
 			c.locationCache[name] = loc
 			c.mu.Unlock()
+			c.locationHits.Add(1)
 			return loc, nil
 		}
 	}
+	c.locationMisses.Add(1)
 
 	// 4. Geocode using Nominatim (with timeout)
+	c.geocodeCalls.Add(1)
 	loc, err := c.geocode(name)
 	if err != nil {
+		c.geocodeErrors.Add(1)
 		return nil, err
 	}
 
@@ -224,7 +338,7 @@ func (c *Calculator) geocode(name string) (*Location, error) {
 	}
 	req.Header.Set("User-Agent", "HuePlanner/2.0")
 
-	resp, err := httpClient.Do(req)
+	resp, err := c.client().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("geocoding request failed: %w", err)
 	}
@@ -289,13 +403,21 @@ func (c *Calculator) calculate(lat, lon float64, date time.Time, tz *time.Locati
 	// Midnight is next day at 00:00
 	midnight := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, tz)
 
+	// Out-of-range hour angle: the sun stays above or below the horizon all day
+	cosOmega, _ := sunHourAngle(jd, lat, lon, -0.833)
+	cosCivil, _ := sunHourAngle(jd, lat, lon, -6.0)
+
 	return &AstroTimes{
-		Dawn:     dawn,
-		Sunrise:  sunrise,
-		Noon:     noon,
-		Sunset:   sunset,
-		Dusk:     dusk,
-		Midnight: midnight,
+		Dawn:            dawn,
+		Sunrise:         sunrise,
+		Noon:            noon,
+		Sunset:          sunset,
+		Dusk:            dusk,
+		Midnight:        midnight,
+		PolarDay:        cosOmega < -1,
+		PolarNight:      cosOmega > 1,
+		CivilPolarDay:   cosCivil < -1,
+		CivilPolarNight: cosCivil > 1,
 	}
 }
 
@@ -344,6 +466,31 @@ func solarNoon(jd, lon float64, tz *time.Location, date time.Time) time.Time {
 
 // sunTime calculates sunrise or sunset time
 func sunTime(jd, lat, lon float64, tz *time.Location, date time.Time, angle float64, rising bool) time.Time {
+	cosOmega, jTransit := sunHourAngle(jd, lat, lon, angle)
+
+	// Clamp to valid range
+	if cosOmega > 1 {
+		cosOmega = 1
+	} else if cosOmega < -1 {
+		cosOmega = -1
+	}
+
+	omega := math.Acos(cosOmega) * 180.0 / math.Pi
+
+	var jTime float64
+	if rising {
+		jTime = jTransit - omega/360.0
+	} else {
+		jTime = jTransit + omega/360.0
+	}
+
+	return julianToTime(jTime, tz, date)
+}
+
+// sunHourAngle returns the cosine of the hour angle at which the sun crosses
+// angle (unclamped: >1 means it never rises that high, <-1 never sinks that
+// low) and the Julian date of solar transit.
+func sunHourAngle(jd, lat, lon, angle float64) (cosOmega, jTransit float64) {
 	// Approximate solar noon
 	n := jd - 2451545.0 + 0.0008
 	jStar := n - lon/360.0
@@ -360,7 +507,7 @@ func sunTime(jd, lat, lon float64, tz *time.Location, date time.Time, angle floa
 	lambdaRad := lambda * math.Pi / 180.0
 
 	// Solar transit
-	jTransit := 2451545.0 + jStar + 0.0053*math.Sin(mRad) - 0.0069*math.Sin(2*lambdaRad)
+	jTransit = 2451545.0 + jStar + 0.0053*math.Sin(mRad) - 0.0069*math.Sin(2*lambdaRad)
 
 	// Declination of the sun
 	sinDec := math.Sin(lambdaRad) * math.Sin(23.44*math.Pi/180.0)
@@ -370,25 +517,8 @@ func sunTime(jd, lat, lon float64, tz *time.Location, date time.Time, angle floa
 	latRad := lat * math.Pi / 180.0
 	angleRad := angle * math.Pi / 180.0
 
-	cosOmega := (math.Sin(angleRad) - math.Sin(latRad)*math.Sin(dec)) / (math.Cos(latRad) * math.Cos(dec))
-
-	// Clamp to valid range
-	if cosOmega > 1 {
-		cosOmega = 1
-	} else if cosOmega < -1 {
-		cosOmega = -1
-	}
-
-	omega := math.Acos(cosOmega) * 180.0 / math.Pi
-
-	var jTime float64
-	if rising {
-		jTime = jTransit - omega/360.0
-	} else {
-		jTime = jTransit + omega/360.0
-	}
-
-	return julianToTime(jTime, tz, date)
+	cosOmega = (math.Sin(angleRad) - math.Sin(latRad)*math.Sin(dec)) / (math.Cos(latRad) * math.Cos(dec))
+	return cosOmega, jTransit
 }
 
 // julianToTime converts Julian day to time.Time
@@ -413,3 +543,29 @@ func (c *Calculator) GetTimesForToday(locationName string, timezone string) (*As
 	today := time.Now().In(tz)
 	return c.GetTimes(locationName, today, timezone)
 }
+
+// CachedLocations returns the persisted geocache entries.
+func (c *Calculator) CachedLocations() ([]storage.GeoCacheEntry, error) {
+	if c.persistentCache == nil {
+		return nil, nil
+	}
+	return c.persistentCache.List()
+}
+
+// ClearCachedLocation forgets a geocoded location (or all of them if name is
+// empty) in both the in-memory and persistent caches, so the next lookup
+// geocodes again. Returns the number of persisted entries removed.
+func (c *Calculator) ClearCachedLocation(name string) (int64, error) {
+	c.mu.Lock()
+	if name == "" {
+		c.locationCache = make(map[string]*Location)
+	} else {
+		delete(c.locationCache, name)
+	}
+	c.mu.Unlock()
+
+	if c.persistentCache == nil {
+		return 0, nil
+	}
+	return c.persistentCache.Clear(name)
+}
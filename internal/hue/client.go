@@ -3,7 +3,11 @@ package hue
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/amimof/huego"
@@ -15,8 +19,16 @@ import (
 // Client is a holder for Hue API clients with shared HTTP configuration.
 // It provides access to both V1 (via huego) and V2 (via custom client) APIs.
 type Client struct {
-	v1 *huego.Bridge // V1 API via huego
-	v2 *v2.Client    // V2 API via custom client (SSE support)
+	v1 atomic.Pointer[huego.Bridge] // V1 API via huego; swapped on key rotation
+	v2 *v2.Client                   // V2 API via custom client (SSE support)
+
+	apiVersion string      // "v1", "v2" or "auto"
+	v2Only     atomic.Bool // true when operations should go through V2
+
+	rotateMu sync.Mutex // serializes RotateKey
+
+	keyMu     sync.RWMutex
+	clientKey string // entertainment (DTLS) client key; empty if unknown
 }
 
 // NewClient creates a new Hue client holder.
@@ -28,7 +40,10 @@ type Client struct {
 //
 // V2 client uses the custom HTTP client with TLS verification disabled
 // (required for Hue bridge's self-signed certificates).
-func NewClient(address, token string, timeout time.Duration) *Client {
+//
+// apiVersion selects which API handles group/scene/light operations:
+// "v1", "v2" (V1 is never probed), or "auto" (V1, falling back to V2 if the V1 probe fails).
+func NewClient(address, token string, timeout time.Duration, apiVersion string) *Client {
 	// Create HTTP client for V2 with TLS verification disabled
 	// (Hue bridge uses self-signed certificates)
 	transport := &http.Transport{
@@ -40,23 +55,43 @@ func NewClient(address, token string, timeout time.Duration) *Client {
 		Transport: transport,
 	}
 
-	// Initialize huego bridge (uses http.DefaultClient internally)
-	bridge := huego.New(address, token)
+	// Initialize huego bridge (uses http.DefaultClient internally). huego
+	// adds a missing scheme to Host on every request, so set it up front to
+	// keep requests from writing to the shared bridge.
+	bridge := huego.New(v1Host(address), token)
 
 	// Initialize V2 client with custom HTTP client
 	v2Client := v2.NewClient(address, token, httpClient)
 
-	return &Client{
-		v1: bridge,
-		v2: v2Client,
+	c := &Client{
+		v2:         v2Client,
+		apiVersion: apiVersion,
 	}
+	c.v1.Store(bridge)
+	c.v2Only.Store(apiVersion == "v2")
+	return c
 }
 
-// Connect tests connectivity to both APIs
+// v1Host returns the V1 API base URL for a bridge address.
+func v1Host(address string) string {
+	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+		return address
+	}
+	return "http://" + address
+}
+
+// Connect tests connectivity to the APIs in use.
+// In "auto" mode a failed V1 probe switches operations to V2 instead of failing.
 func (c *Client) Connect(ctx context.Context) error {
 	// Test V1 API connection via huego
-	if _, err := c.v1.GetCapabilities(); err != nil {
-		return err
+	if c.apiVersion != "v2" {
+		if _, err := c.V1().GetCapabilities(); err != nil {
+			if c.apiVersion != "auto" {
+				return err
+			}
+			log.Warn().Err(err).Msg("Hue V1 API unavailable, using V2 only")
+			c.v2Only.Store(true)
+		}
 	}
 
 	// Test V2 API connection
@@ -65,9 +100,38 @@ func (c *Client) Connect(ctx context.Context) error {
 	}
 
 	log.Info().Str("address", c.v2.Address()).Msg("Connected to Hue bridge")
+	c.checkFirmware(ctx)
 	return nil
 }
 
+// checkFirmware logs the bridge model and software version, warning when the
+// firmware predates a reliable event stream. Failures are only logged.
+func (c *Client) checkFirmware(ctx context.Context) {
+	info, err := c.v2.GetBridgeInfo(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read bridge software version")
+		return
+	}
+
+	log.Info().
+		Str("bridge_id", info.BridgeID).
+		Str("model", info.Model).
+		Str("swversion", info.SoftwareVersion).
+		Msg("Hue bridge info")
+
+	if info.Outdated() {
+		log.Warn().
+			Str("swversion", info.SoftwareVersion).
+			Str("min_version", v2.MinEventStreamVersion).
+			Msg("Bridge firmware is outdated; event stream may be unreliable, update the bridge in the Hue app")
+	}
+}
+
+// V2Only reports whether group/scene/light operations go through the V2 API.
+func (c *Client) V2Only() bool {
+	return c.v2Only.Load()
+}
+
 // Close closes the V2 client connections
 func (c *Client) Close() error {
 	c.v2.Close()
@@ -76,8 +140,10 @@ func (c *Client) Close() error {
 
 // V1 returns the huego bridge for direct V1 API access.
 // Use this for all V1 operations (groups, scenes, lights via V1 API).
+// RotateKey replaces the bridge, so hold on to the Client (or this method),
+// not the returned value.
 func (c *Client) V1() *huego.Bridge {
-	return c.v1
+	return c.v1.Load()
 }
 
 // V2 returns the V2 client for direct V2 API access.
@@ -90,3 +156,57 @@ func (c *Client) V2() *v2.Client {
 func (c *Client) Address() string {
 	return c.v2.Address()
 }
+
+// SetClientKey sets the entertainment client key paired with the application key.
+func (c *Client) SetClientKey(clientKey string) {
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	c.clientKey = clientKey
+}
+
+// ClientKey returns the entertainment client key, or "" if none is known.
+// It is a secret: never log it.
+func (c *Client) ClientKey() string {
+	c.keyMu.RLock()
+	defer c.keyMu.RUnlock()
+	return c.clientKey
+}
+
+// RotateKey creates a new application key and entertainment client key on
+// the bridge and switches both API clients to them. The link button on the
+// bridge must have been pressed.
+//
+// The old key stays in use until the new one has been verified against the
+// V2 API and handed to save along with the client key (which should persist
+// them, e.g. to a token file).
+// If any step fails the clients keep using the old key. The bridge does not
+// allow deleting keys through the API; revoke the old key from the Hue app
+// or account portal once the rotation succeeded.
+func (c *Client) RotateKey(ctx context.Context, deviceType string, save func(token, clientKey string) error) error {
+	c.rotateMu.Lock()
+	defer c.rotateMu.Unlock()
+
+	bridge := c.V1()
+	user, err := bridge.CreateUserWithClientKeyContext(ctx, deviceType)
+	if err != nil {
+		return fmt.Errorf("failed to create application key (is the link button pressed?): %w", err)
+	}
+	token := user.Username
+
+	if err := c.v2.WithToken(token).Ping(ctx); err != nil {
+		return fmt.Errorf("new application key rejected by bridge: %w", err)
+	}
+
+	if err := save(token, user.ClientKey); err != nil {
+		return fmt.Errorf("failed to save new application key: %w", err)
+	}
+
+	// huego reads User without synchronization, so V1 switches to a new
+	// bridge instead of mutating the one in use
+	c.v1.Store(huego.New(bridge.Host, token))
+	c.v2.SetToken(token)
+	c.SetClientKey(user.ClientKey)
+
+	log.Info().Str("address", c.v2.Address()).Msg("Rotated Hue application key")
+	return nil
+}
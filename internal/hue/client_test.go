@@ -0,0 +1,117 @@
+package hue
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amimof/huego"
+
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// newRotateTestClient returns a Client whose V1 API is served by a plain
+// HTTP server that hands out newToken, and whose V2 API accepts only newToken.
+func newRotateTestClient(t *testing.T, newToken string) *Client {
+	t.Helper()
+
+	v1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`[{"success":{"username":"` + newToken + `","clientkey":"new-client-key"}}]`))
+	}))
+	t.Cleanup(v1.Close)
+
+	v2srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("hue-application-key") != newToken {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	t.Cleanup(v2srv.Close)
+
+	c := &Client{
+		apiVersion: "v1",
+		v2:         v2.NewClient(strings.TrimPrefix(v2srv.URL, "https://"), "old-token", v2srv.Client()),
+		clientKey:  "old-client-key",
+	}
+	c.v1.Store(huego.New(v1.URL, "old-token"))
+	return c
+}
+
+func TestClient_RotateKey(t *testing.T) {
+	c := newRotateTestClient(t, "new-token")
+	old := c.V1()
+
+	var saved []string
+	err := c.RotateKey(context.Background(), "test#rotate", func(token, clientKey string) error {
+		saved = []string{token, clientKey}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+
+	if len(saved) != 2 || saved[0] != "new-token" || saved[1] != "new-client-key" {
+		t.Errorf("saved %v, want [new-token new-client-key]", saved)
+	}
+	if got := c.V1().User; got != "new-token" {
+		t.Errorf("V1 user = %q, want new-token", got)
+	}
+	if old.User != "old-token" {
+		t.Errorf("bridge in use was mutated: user = %q", old.User)
+	}
+	if got := c.V2().Token(); got != "new-token" {
+		t.Errorf("V2 token = %q, want new-token", got)
+	}
+	if got := c.ClientKey(); got != "new-client-key" {
+		t.Errorf("client key = %q, want new-client-key", got)
+	}
+}
+
+func TestClient_RotateKeySaveFailureKeepsOldKey(t *testing.T) {
+	c := newRotateTestClient(t, "new-token")
+	old := c.V1()
+
+	saveErr := errors.New("disk full")
+	err := c.RotateKey(context.Background(), "test#rotate", func(token, clientKey string) error {
+		return saveErr
+	})
+	if !errors.Is(err, saveErr) {
+		t.Fatalf("RotateKey error = %v, want %v", err, saveErr)
+	}
+
+	if c.V1() != old || old.User != "old-token" {
+		t.Errorf("V1 bridge changed after failed save: user = %q", c.V1().User)
+	}
+	if got := c.V2().Token(); got != "old-token" {
+		t.Errorf("V2 token = %q, want old-token", got)
+	}
+	if got := c.ClientKey(); got != "old-client-key" {
+		t.Errorf("client key = %q, want old-client-key", got)
+	}
+}
+
+func TestClient_RotateKeyConcurrentReads(t *testing.T) {
+	c := newRotateTestClient(t, "new-token")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			_ = c.V1().User
+			_ = c.V2().Token()
+		}
+	}()
+
+	if err := c.RotateKey(context.Background(), "test#rotate", func(token, clientKey string) error { return nil }); err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	<-done
+}
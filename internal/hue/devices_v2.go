@@ -0,0 +1,191 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// Device is a physical Hue device with the IDs of the services it exposes.
+// Button and rotary IDs are the resource IDs seen in SSE button/rotary events.
+type Device struct {
+	ID          string
+	Name        string
+	ProductName string
+	ModelID     string
+	Room        string // name of the room the device is assigned to, "" if none
+	Buttons     []string
+	Rotaries    []string
+	Services    []v2.ResourceRef
+}
+
+// DeviceIndex caches the bridge's device list and the V1→V2 ID mapping
+// fetched through the V2 API. Both are filled on first use and dropped by
+// Invalidate (e.g. when a device SSE event arrives or on RefreshCache).
+type DeviceIndex struct {
+	client *v2.Client
+
+	mu      sync.Mutex
+	devices []Device                     // nil until fetched
+	ids     map[string]map[string]string // rtype -> id_v1 -> V2 ID
+}
+
+// NewDeviceIndex creates an empty device index backed by the V2 client.
+func NewDeviceIndex(client *v2.Client) *DeviceIndex {
+	return &DeviceIndex{client: client}
+}
+
+// Devices returns the cached device list, fetching it if needed.
+func (d *DeviceIndex) Devices(ctx context.Context) ([]Device, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.devices != nil {
+		return d.devices, nil
+	}
+
+	devices, err := FetchDevicesV2(ctx, d.client)
+	if err != nil {
+		return nil, err
+	}
+	d.devices = devices
+	return devices, nil
+}
+
+// LightsInRoom returns the V2 light service IDs of devices assigned to the
+// named room (case-insensitive).
+func (d *DeviceIndex) LightsInRoom(ctx context.Context, room string) ([]string, error) {
+	devices, err := d.Devices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, dev := range devices {
+		if !strings.EqualFold(dev.Room, room) {
+			continue
+		}
+		for _, svc := range dev.Services {
+			if svc.RType == "light" {
+				ids = append(ids, svc.RID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// ResolveV1 returns the V2 ID of the rtype resource whose id_v1 equals
+// v1Path. Each rtype is listed once; a miss refetches it in case the
+// resource was added since.
+func (d *DeviceIndex) ResolveV1(ctx context.Context, rtype, v1Path string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if id, ok := d.ids[rtype][v1Path]; ok {
+		return id, nil
+	}
+
+	resources, err := d.client.GetResources(ctx, rtype)
+	if err != nil {
+		return "", err
+	}
+	byV1 := make(map[string]string, len(resources))
+	for _, r := range resources {
+		if r.IDV1 != "" {
+			byV1[r.IDV1] = r.ID
+		}
+	}
+	if d.ids == nil {
+		d.ids = make(map[string]map[string]string)
+	}
+	d.ids[rtype] = byV1
+
+	if id, ok := byV1[v1Path]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("no V2 %s for %s", rtype, v1Path)
+}
+
+// V2Client returns the V2 client with ID resolution served from this index.
+func (d *DeviceIndex) V2Client() *ResolvingV2Client {
+	return &ResolvingV2Client{Client: d.client, index: d}
+}
+
+// Invalidate drops the cached devices and IDs so the next call refetches them.
+func (d *DeviceIndex) Invalidate() {
+	d.mu.Lock()
+	d.devices = nil
+	d.ids = nil
+	d.mu.Unlock()
+}
+
+// ResolvingV2Client is a V2 client whose V1→V2 ID lookups go through a
+// DeviceIndex instead of listing resources on every call.
+type ResolvingV2Client struct {
+	*v2.Client
+	index *DeviceIndex
+}
+
+// ResolveV1 resolves through the index cache.
+func (c *ResolvingV2Client) ResolveV1(ctx context.Context, rtype, v1Path string) (string, error) {
+	return c.index.ResolveV1(ctx, rtype, v1Path)
+}
+
+// LightIDForV1 resolves a V1 light ID through the index cache.
+func (c *ResolvingV2Client) LightIDForV1(ctx context.Context, v1ID int) (string, error) {
+	return c.index.ResolveV1(ctx, "light", fmt.Sprintf("/lights/%d", v1ID))
+}
+
+// FetchDevicesV2 loads all devices and the rooms they belong to.
+func FetchDevicesV2(ctx context.Context, client *v2.Client) ([]Device, error) {
+	resources, err := client.GetResources(ctx, "device")
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := client.GetResources(ctx, "room")
+	if err != nil {
+		return nil, err
+	}
+
+	roomOf := make(map[string]string)
+	for _, r := range rooms {
+		if r.Metadata == nil {
+			continue
+		}
+		for _, child := range r.Children {
+			if child.RType == "device" {
+				roomOf[child.RID] = r.Metadata.Name
+			}
+		}
+	}
+
+	devices := make([]Device, 0, len(resources))
+	for _, r := range resources {
+		dev := Device{
+			ID:       r.ID,
+			Room:     roomOf[r.ID],
+			Services: r.Services,
+		}
+		if r.Metadata != nil {
+			dev.Name = r.Metadata.Name
+		}
+		if r.ProductData != nil {
+			dev.ProductName = r.ProductData.ProductName
+			dev.ModelID = r.ProductData.ModelID
+		}
+		for _, svc := range r.Services {
+			switch svc.RType {
+			case "button":
+				dev.Buttons = append(dev.Buttons, svc.RID)
+			case "relative_rotary":
+				dev.Rotaries = append(dev.Rotaries, svc.RID)
+			}
+		}
+		devices = append(devices, dev)
+	}
+
+	return devices, nil
+}
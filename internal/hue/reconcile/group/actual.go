@@ -2,53 +2,188 @@ package group
 
 import (
 	"context"
-	"strconv"
+	"fmt"
+	"sync"
+	"time"
 
-	"github.com/amimof/huego"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
 // ActualProvider provides actual state for groups.
-// Always fetches from the bridge - the bridge is the source of truth.
+// Reads the shared bridge snapshot - the bridge is the source of truth -
+// except within the stabilization window after a write (see SetStabilization).
 type ActualProvider struct {
-	bridge *huego.Bridge
+	state *reconcile.StateCache
+
+	mu            sync.Mutex
+	stabilization time.Duration
+	recent        map[string]recentActual // optimistic state per group after a write
+}
+
+// recentActual is the state a group is expected to settle in after a write.
+type recentActual struct {
+	actual  Actual
+	expires time.Time
 }
 
 // NewActualProvider creates a new actual state provider.
-func NewActualProvider(bridge *huego.Bridge) *ActualProvider {
+func NewActualProvider(state *reconcile.StateCache) *ActualProvider {
 	return &ActualProvider{
-		bridge: bridge,
+		state: state,
+	}
+}
+
+// SetStabilization makes Get return the state recorded by Remember for
+// window after a write, instead of fetching a state the bridge may still
+// report as transitioning. 0 disables it.
+func (p *ActualProvider) SetStabilization(window time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stabilization = window
+	p.recent = nil
+}
+
+// Remember records the state a group is expected to settle in after a write,
+// and drops the bridge snapshot so later reads see the write.
+func (p *ActualProvider) Remember(groupID string, actual Actual) {
+	p.state.Invalidate()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stabilization <= 0 {
+		return
+	}
+	if p.recent == nil {
+		p.recent = make(map[string]recentActual)
+	}
+	p.recent[groupID] = recentActual{actual: actual, expires: time.Now().Add(p.stabilization)}
+}
+
+// BeginPass drops the bridge snapshot, so each reconcile pass reads the
+// bridge once.
+func (p *ActualProvider) BeginPass() {
+	p.state.BeginPass()
+}
+
+// Forget drops all recorded states, so the next Get fetches from the bridge.
+func (p *ActualProvider) Forget() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.recent = nil
+}
+
+// recall returns the recorded state of a group if it has not expired yet.
+func (p *ActualProvider) recall(groupID string) (Actual, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.recent[groupID]
+	if !ok {
+		return Actual{}, false
 	}
+	if time.Now().After(r.expires) {
+		delete(p.recent, groupID)
+		return Actual{}, false
+	}
+	return r.actual, true
 }
 
-// Get returns the actual state for a group by fetching from the bridge.
+// Get returns the actual state for a group, from the state recorded after a
+// recent write if still within the stabilization window, else from the bridge.
 func (p *ActualProvider) Get(ctx context.Context, groupID string) (Actual, error) {
-	state, err := p.fetchGroupState(groupID)
+	if actual, ok := p.recall(groupID); ok {
+		return actual, nil
+	}
+
+	state, err := p.state.State(ctx)
 	if err != nil {
 		return Actual{}, err
 	}
+	group, ok := state.Groups[groupID]
+	if !ok {
+		return Actual{}, fmt.Errorf("group %s not found on bridge", groupID)
+	}
 
-	return Actual{
-		AnyOn: state.AnyOn,
-		AllOn: state.AllOn,
-	}, nil
+	return groupActual(group, state.Lights), nil
 }
 
-// fetchGroupState fetches group state directly from the bridge.
-func (p *ActualProvider) fetchGroupState(groupID string) (*huego.GroupState, error) {
-	id, err := strconv.Atoi(groupID)
-	if err != nil {
-		return nil, err
+// Cached returns a group's actual state without contacting the bridge: the
+// state recorded after a recent write, else the last bridge snapshot. ok is
+// false if neither knows the group.
+func (p *ActualProvider) Cached(groupID string) (actual Actual, ok bool) {
+	if actual, ok := p.recall(groupID); ok {
+		return actual, true
 	}
 
-	group, err := p.bridge.GetGroup(id)
-	if err != nil {
-		return nil, err
+	state, _ := p.state.Last()
+	if state == nil {
+		return Actual{}, false
 	}
+	group, ok := state.Groups[groupID]
+	if !ok {
+		return Actual{}, false
+	}
+	return groupActual(group, state.Lights), true
+}
+
+// LastFetched returns when the bridge snapshot Cached reads from was
+// fetched (zero before the first fetch).
+func (p *ActualProvider) LastFetched() time.Time {
+	_, fetched := p.state.Last()
+	return fetched
+}
+
+// groupActual derives a group's state from its member lights. Bri is the
+// average brightness of the members that are on, as the group's own "action"
+// brightness is only the last value sent. Groups without member lights are
+// treated as reachable.
+func groupActual(group reconcile.GroupState, lights map[string]reconcile.LightState) Actual {
+	actual := Actual{Reachable: len(group.Lights) == 0, AllReachable: true}
 
-	if group.GroupState != nil {
-		return group.GroupState, nil
+	on, briSum := 0, 0
+	for _, id := range group.Lights {
+		l := lights[id]
+		if l.Reachable {
+			actual.Reachable = true
+		} else {
+			actual.AllReachable = false
+		}
+		if l.On {
+			on++
+			briSum += int(l.Bri)
+		}
 	}
 
-	// Return empty state if nil
-	return &huego.GroupState{}, nil
+	actual.AnyOn = on > 0
+	actual.AllOn = on > 0 && on == len(group.Lights)
+	if on > 0 {
+		actual.Bri = uint8((briSum + on/2) / on)
+	}
+	return actual
+}
+
+// ExpectedActual returns the state a group is expected to settle in after
+// action was applied with desired, starting from prev. Unreachable members
+// don't turn on, so AllOn only follows when every member is reachable.
+// Shared with zones, which apply actions to their member groups.
+func ExpectedActual(prev Actual, desired Desired, action Action) Actual {
+	next := prev
+	switch action {
+	case ActionTurnOff:
+		next.AnyOn = false
+		next.AllOn = false
+	case ActionTurnOnWithScene, ActionApplyScene:
+		next.AnyOn = true
+		next.AllOn = prev.AllReachable
+	case ActionTurnOnWithState, ActionApplyState:
+		next.AnyOn = true
+		next.AllOn = prev.AllReachable
+		if desired.Bri != nil {
+			next.Bri = *desired.Bri
+		}
+	}
+	return next
 }
@@ -0,0 +1,137 @@
+package group
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+func TestGroupActual_FromMemberLights(t *testing.T) {
+	lights := map[string]reconcile.LightState{
+		"1": {On: true, Bri: 100, Reachable: true},
+		"2": {On: true, Bri: 201, Reachable: true},
+		"3": {On: false, Bri: 254, Reachable: false},
+	}
+
+	tests := []struct {
+		name    string
+		members []string
+		want    Actual
+	}{
+		{"all on", []string{"1", "2"}, Actual{AnyOn: true, AllOn: true, Bri: 151, Reachable: true, AllReachable: true}},
+		{"some on", []string{"1", "3"}, Actual{AnyOn: true, AllOn: false, Bri: 100, Reachable: true}},
+		{"off and unreachable", []string{"3"}, Actual{Reachable: false}},
+		{"no members", nil, Actual{Reachable: true, AllReachable: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := groupActual(reconcile.GroupState{Lights: tt.members}, lights)
+			if got != tt.want {
+				t.Errorf("groupActual = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSource serves a fixed bridge state.
+type fakeSource struct {
+	state *reconcile.BridgeState
+}
+
+func (s *fakeSource) FetchState(ctx context.Context) (*reconcile.BridgeState, error) {
+	return s.state, nil
+}
+
+func newTestActualProvider(window time.Duration) *ActualProvider {
+	source := &fakeSource{state: &reconcile.BridgeState{
+		Lights: map[string]reconcile.LightState{"1": {On: false, Reachable: true}},
+		Groups: map[string]reconcile.GroupState{"1": {Lights: []string{"1"}}},
+	}}
+	p := NewActualProvider(reconcile.NewStateCache(source))
+	p.SetStabilization(window)
+	return p
+}
+
+func TestActualProvider_StabilizationWindowExpires(t *testing.T) {
+	p := newTestActualProvider(30 * time.Millisecond)
+	expected := Actual{AnyOn: true, AllOn: true, Bri: 200, Reachable: true, AllReachable: true}
+	p.Remember("1", expected)
+
+	got, err := p.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != expected {
+		t.Errorf("within window: Get = %+v, want remembered %+v", got, expected)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	got, err = p.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AnyOn {
+		t.Errorf("after window: Get = %+v, want the bridge state (off)", got)
+	}
+}
+
+func TestActualProvider_ForgetDropsRemembered(t *testing.T) {
+	p := newTestActualProvider(time.Hour)
+	p.Remember("1", Actual{AnyOn: true, AllOn: true, Reachable: true})
+	p.Forget()
+
+	got, err := p.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AnyOn {
+		t.Errorf("after Forget: Get = %+v, want the bridge state (off)", got)
+	}
+}
+
+func TestActualProvider_NoWindowReadsBridge(t *testing.T) {
+	p := newTestActualProvider(0)
+	p.Remember("1", Actual{AnyOn: true, AllOn: true, Reachable: true})
+
+	got, err := p.Get(context.Background(), "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AnyOn {
+		t.Errorf("disabled window: Get = %+v, want the bridge state (off)", got)
+	}
+}
+
+func TestExpectedActual_PerAction(t *testing.T) {
+	off := Actual{Bri: 0, Reachable: true, AllReachable: true}
+	partlyUnreachable := Actual{Reachable: true, AllReachable: false}
+	on := Actual{AnyOn: true, AllOn: true, Bri: 100, Reachable: true, AllReachable: true}
+	desired := Desired{Bri: uint8Ptr(200)}
+
+	tests := []struct {
+		name   string
+		prev   Actual
+		action Action
+		want   Actual
+	}{
+		{"turn off", on, ActionTurnOff, Actual{Bri: 100, Reachable: true, AllReachable: true}},
+		{"turn on with scene", off, ActionTurnOnWithScene, Actual{AnyOn: true, AllOn: true, Reachable: true, AllReachable: true}},
+		{"apply scene keeps bri", on, ActionApplyScene, on},
+		{"turn on with state", off, ActionTurnOnWithState, Actual{AnyOn: true, AllOn: true, Bri: 200, Reachable: true, AllReachable: true}},
+		{"apply state", on, ActionApplyState, Actual{AnyOn: true, AllOn: true, Bri: 200, Reachable: true, AllReachable: true}},
+		{"unreachable members stay off", partlyUnreachable, ActionTurnOnWithState, Actual{AnyOn: true, AllOn: false, Bri: 200, Reachable: true}},
+		{"no action", on, ActionNone, on},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExpectedActual(tt.prev, desired, tt.action)
+			if got != tt.want {
+				t.Errorf("ExpectedActual = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
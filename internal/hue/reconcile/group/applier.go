@@ -24,12 +24,12 @@ type Applier interface {
 
 // HueApplier implements Applier using the Hue bridge.
 type HueApplier struct {
-	bridge     *huego.Bridge
+	bridge     func() *huego.Bridge // current bridge (replaced on key rotation)
 	sceneIndex SceneFinder
 }
 
 // NewHueApplier creates a new group applier.
-func NewHueApplier(bridge *huego.Bridge, sceneIndex SceneFinder) *HueApplier {
+func NewHueApplier(bridge func() *huego.Bridge, sceneIndex SceneFinder) *HueApplier {
 	return &HueApplier{
 		bridge:     bridge,
 		sceneIndex: sceneIndex,
@@ -53,7 +53,7 @@ func (a *HueApplier) TurnOnWithScene(ctx context.Context, groupID, sceneName str
 		return err
 	}
 
-	group, err := a.bridge.GetGroup(id)
+	group, err := a.bridge().GetGroup(id)
 	if err != nil {
 		return err
 	}
@@ -78,7 +78,7 @@ func (a *HueApplier) ApplyScene(ctx context.Context, groupID, sceneName string)
 		return err
 	}
 
-	group, err := a.bridge.GetGroup(id)
+	group, err := a.bridge().GetGroup(id)
 	if err != nil {
 		return err
 	}
@@ -94,7 +94,7 @@ func (a *HueApplier) ApplyState(ctx context.Context, groupID string, desired Des
 		return err
 	}
 
-	group, err := a.bridge.GetGroup(id)
+	group, err := a.bridge().GetGroup(id)
 	if err != nil {
 		return err
 	}
@@ -156,7 +156,7 @@ func (a *HueApplier) TurnOff(ctx context.Context, groupID string) error {
 		return err
 	}
 
-	group, err := a.bridge.GetGroup(id)
+	group, err := a.bridge().GetGroup(id)
 	if err != nil {
 		return err
 	}
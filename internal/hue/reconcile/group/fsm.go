@@ -1,5 +1,7 @@
 package group
 
+import "fmt"
+
 // State represents the power state of a group.
 type State int
 
@@ -43,16 +45,28 @@ func (a Action) String() string {
 // DetermineAction determines what action to take based on desired and actual state.
 // This is the core FSM logic for group reconciliation.
 func DetermineAction(desired Desired, actual Actual) Action {
-	currentState := deriveState(actual)
+	action, _ := ExplainAction(desired, actual)
+	return action
+}
+
+// ExplainAction determines the action like DetermineAction and also returns
+// a human-readable reason for it, e.g.
+// "group off, wants power on with scene "Relax" → turn_on_with_scene".
+// Used for reconcile debug logging.
+func ExplainAction(desired Desired, actual Actual) (Action, string) {
+	var action Action
+	var reason string
 
-	switch currentState {
+	switch deriveState(actual) {
 	case StateOff:
-		return determineActionFromOff(desired)
+		action, reason = determineActionFromOff(desired)
+		reason = "group off, " + reason
 	case StateOn:
-		return determineActionFromOn(desired)
+		action, reason = determineActionFromOn(desired)
+		reason = "group on, " + reason
 	}
 
-	return ActionNone
+	return action, reason + " → " + action.String()
 }
 
 // deriveState determines the current power state from actual.
@@ -64,43 +78,43 @@ func deriveState(actual Actual) State {
 }
 
 // determineActionFromOff determines action when group is currently off.
-func determineActionFromOff(desired Desired) Action {
+func determineActionFromOff(desired Desired) (Action, string) {
 	if !wantsPowerOn(desired) {
-		return ActionNone
+		return ActionNone, "power on not requested"
 	}
 
 	// Group is off and we want it on
 	if desired.SceneName != "" {
-		return ActionTurnOnWithScene
+		return ActionTurnOnWithScene, fmt.Sprintf("wants power on with scene %q", desired.SceneName)
 	}
 	if hasColorProperties(desired) {
-		return ActionTurnOnWithState
+		return ActionTurnOnWithState, "wants power on with color/brightness"
 	}
 
 	// Power on requested but no scene or state to apply
 	// This shouldn't happen in normal usage, but we can't turn on without something
-	return ActionNone
+	return ActionNone, "wants power on but has no scene or color/brightness to apply"
 }
 
 // determineActionFromOn determines action when group is currently on.
-func determineActionFromOn(desired Desired) Action {
+func determineActionFromOn(desired Desired) (Action, string) {
 	// First priority: power off
 	if wantsPowerOff(desired) {
-		return ActionTurnOff
+		return ActionTurnOff, "wants power off"
 	}
 
 	// Second priority: apply scene if one is desired
 	// Always apply - we don't cache what scene is active, bridge is source of truth
 	if desired.SceneName != "" {
-		return ActionApplyScene
+		return ActionApplyScene, fmt.Sprintf("wants scene %q", desired.SceneName)
 	}
 
 	// Third priority: color/brightness changes (only if no scene is active)
 	if hasColorProperties(desired) {
-		return ActionApplyState
+		return ActionApplyState, "wants color/brightness"
 	}
 
-	return ActionNone
+	return ActionNone, "nothing to change"
 }
 
 // wantsPowerOn returns true if desired explicitly wants power on.
@@ -1,6 +1,7 @@
 package group
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -182,6 +183,61 @@ func TestDetermineAction(t *testing.T) {
 	}
 }
 
+func TestExplainAction(t *testing.T) {
+	// The desired and actual states used by TestDetermineAction; every
+	// combination must explain the same action DetermineAction picks
+	desired := []Desired{
+		{},
+		{Power: boolPtr(true)},
+		{Power: boolPtr(false)},
+		{SceneName: "Relax"},
+		{Power: boolPtr(true), SceneName: "Relax"},
+		{Power: boolPtr(true), SceneName: "Energize"},
+		{Power: boolPtr(false), SceneName: "Relax"},
+		{SceneName: "Concentrate", Bri: uint8Ptr(200)},
+		{Bri: uint8Ptr(128)},
+		{Power: boolPtr(true), Bri: uint8Ptr(254)},
+		{Bri: uint8Ptr(200), Ct: uint16Ptr(400)},
+		{Ct: uint16Ptr(300)},
+		{Ct: uint16Ptr(400)},
+		{Power: boolPtr(true), Ct: uint16Ptr(300)},
+		{Hue: uint16Ptr(10000)},
+		{Hue: uint16Ptr(30000)},
+		{Sat: uint8Ptr(200)},
+		{Power: boolPtr(true), Hue: uint16Ptr(10000), Sat: uint8Ptr(200)},
+		{Xy: []float32{0.3, 0.4}},
+		{Xy: []float32{0.5, 0.5}},
+		{Power: boolPtr(true), Xy: []float32{0.5, 0.5}},
+	}
+	actual := map[string]Actual{
+		"off":        {AnyOn: false, AllOn: false},
+		"partial_on": {AnyOn: true, AllOn: false},
+		"on":         {AnyOn: true, AllOn: true},
+	}
+
+	for state, a := range actual {
+		for _, d := range desired {
+			want := DetermineAction(d, a)
+			got, reason := ExplainAction(d, a)
+			if got != want {
+				t.Errorf("%s %+v: ExplainAction() = %s, DetermineAction() = %s", state, d, got, want)
+			}
+			if !strings.HasSuffix(reason, "→ "+want.String()) {
+				t.Errorf("%s %+v: ExplainAction() reason = %q, want it to end with %s", state, d, reason, want)
+			}
+		}
+	}
+
+	_, reason := ExplainAction(
+		Desired{Power: boolPtr(true), SceneName: "Relax"},
+		Actual{AnyOn: false},
+	)
+	want := `group off, wants power on with scene "Relax" → turn_on_with_scene`
+	if reason != want {
+		t.Errorf("ExplainAction() reason = %q, want %q", reason, want)
+	}
+}
+
 func TestDeriveState(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2,6 +2,7 @@ package group
 
 import (
 	"context"
+	"time"
 
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/storage"
@@ -12,6 +13,12 @@ type Provider struct {
 	store   *storage.TypedStore[Desired]
 	actual  *ActualProvider
 	applier Applier
+
+	// maxBriStep enables brightness ramping on resources (0 = disabled)
+	maxBriStep int
+
+	// offDelays holds back scheduled turn-offs (nil = turn off immediately)
+	offDelays *reconcile.OffDelays
 }
 
 // NewProvider creates a new group provider.
@@ -41,7 +48,7 @@ func (p *Provider) ListDirty(ctx context.Context, lastVersions map[string]int64)
 
 	resources := make([]reconcile.Resource, 0, len(ids))
 	for _, id := range ids {
-		resources = append(resources, NewResource(id, p.store, p.actual, p.applier))
+		resources = append(resources, p.newResource(id))
 	}
 
 	return resources, nil
@@ -49,7 +56,55 @@ func (p *Provider) ListDirty(ctx context.Context, lastVersions map[string]int64)
 
 // Get returns a specific resource by ID.
 func (p *Provider) Get(ctx context.Context, id string) (reconcile.Resource, error) {
-	return NewResource(id, p.store, p.actual, p.applier), nil
+	return p.newResource(id), nil
+}
+
+// SetMaxBriStep limits brightness changes to step per apply; larger changes
+// are ramped over several applies. 0 disables ramping.
+func (p *Provider) SetMaxBriStep(step int) {
+	p.maxBriStep = step
+}
+
+// SetOffDelay holds back turning a group off for delay when the power-off
+// was set by a scheduled action (Desired.DelayOff), so a new desired state
+// (e.g. power on from a button press) can cancel it. trigger is called with
+// the group ID when the delay ends, to re-queue it. 0 disables the delay.
+func (p *Provider) SetOffDelay(delay time.Duration, trigger func(groupID string)) {
+	if delay <= 0 {
+		p.offDelays = nil
+		return
+	}
+	p.offDelays = reconcile.NewOffDelays(delay, trigger)
+}
+
+// BeginPass implements reconcile.PassAware.
+func (p *Provider) BeginPass() {
+	p.actual.BeginPass()
+}
+
+// Hold sets a group's desired brightness to its current brightness, so a
+// ramp in progress stops where it is. Groups that are off or have no desired
+// brightness are left alone.
+func (p *Provider) Hold(ctx context.Context, groupID string) error {
+	desired, _, err := p.store.Get(groupID)
+	if err != nil || desired.Bri == nil {
+		return err
+	}
+	actual, err := p.actual.Get(ctx, groupID)
+	if err != nil || !actual.AnyOn {
+		return err
+	}
+
+	desired.Bri = &actual.Bri
+	return p.store.Set(groupID, desired)
+}
+
+// newResource creates a resource carrying the provider's ramp and off delay settings.
+func (p *Provider) newResource(id string) *Resource {
+	r := NewResource(id, p.store, p.actual, p.applier)
+	r.ramp.MaxStep = p.maxBriStep
+	r.offDelays = p.offDelays
+	return r
 }
 
 // ListAllIDs returns all resource IDs that have desired state.
@@ -66,9 +121,11 @@ func (p *Provider) ListAllIDs(ctx context.Context) ([]string, error) {
 	return ids, nil
 }
 
-// ClearCaches is a no-op for group provider.
-// We don't cache state - the bridge is the source of truth.
-func (p *Provider) ClearCaches() {}
+// ClearCaches drops the states recorded for the stabilization window, so a
+// forced reconcile compares against the bridge.
+func (p *Provider) ClearCaches() {
+	p.actual.Forget()
+}
 
 // Store returns the typed store for direct access (e.g., from actions).
 func (p *Provider) Store() *storage.TypedStore[Desired] {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/dokzlo13/lightd/internal/events"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
@@ -20,6 +21,12 @@ type Resource struct {
 	desired        Desired
 	desiredVersion int64
 	actualState    Actual
+
+	// ramp steps brightness toward the target across ReconcileStep calls
+	ramp reconcile.BriRamp
+
+	// offDelays holds back scheduled turn-offs (shared across resources, nil = disabled)
+	offDelays *reconcile.OffDelays
 }
 
 // NewResource creates a new group resource.
@@ -58,6 +65,11 @@ func (r *Resource) Load(ctx context.Context) error {
 		return err
 	}
 
+	// A delayed turn-off is dropped once desired state no longer calls for it
+	if !r.desired.DelayOff || DetermineAction(r.desired, r.actualState) != ActionTurnOff {
+		r.offDelays.Cancel(r.groupID)
+	}
+
 	return nil
 }
 
@@ -69,7 +81,7 @@ func (r *Resource) NeedsReconcile() bool {
 
 // ReconcileStep performs one transition step using the FSM.
 func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
-	action := DetermineAction(r.desired, r.actualState)
+	action, reason := ExplainAction(r.desired, r.actualState)
 
 	// Debug logging
 	log.Debug().
@@ -77,50 +89,95 @@ func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
 		Interface("desired", r.desired).
 		Interface("actual", r.actualState).
 		Str("action", action.String()).
+		Str("reason", reason).
+		Str("trace_id", events.TraceIDFromContext(ctx)).
 		Msg("Group reconcile step")
 
 	if action == ActionNone {
 		return true, nil
 	}
 
-	return r.executeAction(ctx, action)
-}
+	// Hold back scheduled turn-offs during the off delay; the group is
+	// re-queued when it ends
+	if action == ActionTurnOff && r.desired.DelayOff && !r.offDelays.Ready(r.groupID) {
+		return true, nil
+	}
 
-// executeAction executes the determined action.
-func (r *Resource) executeAction(ctx context.Context, action Action) (done bool, err error) {
-	switch action {
-	case ActionTurnOnWithScene:
-		if err := r.applier.TurnOnWithScene(ctx, r.groupID, r.desired.SceneName); err != nil {
+	// Spread large brightness changes over several applies
+	if step, ok := r.rampStep(action); ok {
+		if err := ExecuteAction(ctx, r.applier, r.groupID, step, action); err != nil {
 			return false, err
 		}
-		return true, nil
+		r.actual.Remember(r.groupID, ExpectedActual(r.actualState, step, action))
+		return false, nil
+	}
 
+	if err := ExecuteAction(ctx, r.applier, r.groupID, r.desired, action); err != nil {
+		return false, err
+	}
+	r.actual.Remember(r.groupID, ExpectedActual(r.actualState, r.desired, action))
+	return true, nil
+}
+
+// rampStep returns the desired state for an intermediate ramp step, or false
+// when the target brightness can be applied directly. A group that is on but
+// reports no brightness is not ramped, since there is no known start point.
+func (r *Resource) rampStep(action Action) (Desired, bool) {
+	if r.desired.Bri == nil {
+		return Desired{}, false
+	}
+
+	var current uint8
+	switch action {
 	case ActionTurnOnWithState:
-		if err := r.applier.ApplyState(ctx, r.groupID, r.desired); err != nil {
-			return false, err
+		current = 0
+	case ActionApplyState:
+		if r.actualState.Bri == 0 {
+			return Desired{}, false
 		}
-		return true, nil
+		current = r.actualState.Bri
+	default:
+		return Desired{}, false
+	}
 
-	case ActionTurnOff:
-		if err := r.applier.TurnOff(ctx, r.groupID); err != nil {
-			return false, err
-		}
-		return true, nil
+	bri, final := r.ramp.Next(current, *r.desired.Bri)
+	if final {
+		return Desired{}, false
+	}
 
-	case ActionApplyScene:
-		if err := r.applier.ApplyScene(ctx, r.groupID, r.desired.SceneName); err != nil {
-			return false, err
-		}
-		return true, nil
+	log.Debug().
+		Str("group", r.groupID).
+		Uint8("from", current).
+		Uint8("to", bri).
+		Uint8("target", *r.desired.Bri).
+		Msg("Ramping group brightness")
+
+	step := r.desired
+	step.Bri = &bri
+	return step, true
+}
 
+// ExecuteAction applies one FSM action to a group.
+// Shared with resources that drive groups on behalf of others (e.g. zones).
+func ExecuteAction(ctx context.Context, applier Applier, groupID string, desired Desired, action Action) error {
+	switch action {
+	case ActionTurnOnWithScene:
+		return applier.TurnOnWithScene(ctx, groupID, desired.SceneName)
+	case ActionTurnOnWithState:
+		return applier.ApplyState(ctx, groupID, desired)
+	case ActionTurnOff:
+		return applier.TurnOff(ctx, groupID)
+	case ActionApplyScene:
+		return applier.ApplyScene(ctx, groupID, desired.SceneName)
 	case ActionApplyState:
-		if err := r.applier.ApplyState(ctx, r.groupID, r.desired); err != nil {
-			return false, err
-		}
-		return true, nil
+		return applier.ApplyState(ctx, groupID, desired)
 	}
+	return nil
+}
 
-	return true, nil
+// Reachable returns whether any light in the group responded as reachable on last Load().
+func (r *Resource) Reachable() bool {
+	return r.actualState.Reachable
 }
 
 // DesiredVersion returns the version of the desired state.
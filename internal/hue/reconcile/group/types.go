@@ -11,10 +11,18 @@ type Desired struct {
 	Sat       *uint8    `json:"sat,omitempty"`        // saturation (0-254)
 	Xy        []float32 `json:"xy,omitempty"`         // CIE xy color coordinates
 	Ct        *uint16   `json:"ct,omitempty"`         // color temperature in mirek (153-500)
+
+	// DelayOff marks a power-off set by a scheduled action; only those are
+	// held back by the reconciler's off delay
+	DelayOff bool `json:"delay_off,omitempty"`
 }
 
 // Actual is the actual state of a group (from Hue bridge).
 type Actual struct {
-	AnyOn bool
-	AllOn bool
+	AnyOn     bool
+	AllOn     bool
+	Bri       uint8 // average brightness of the members that are on (1-254), 0 if none
+	Reachable bool  // true if at least one member light is reachable
+
+	AllReachable bool // true if every member light is reachable
 }
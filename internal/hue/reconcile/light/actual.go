@@ -2,46 +2,144 @@ package light
 
 import (
 	"context"
-	"strconv"
+	"fmt"
+	"sync"
+	"time"
 
-	"github.com/amimof/huego"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
 // ActualProvider provides actual state for lights.
-// Always fetches from the bridge - no caching, as the bridge is the source of truth.
+// Reads the shared bridge snapshot, as the bridge is the source of truth - except
+// within the stabilization window after a write (see SetStabilization).
 type ActualProvider struct {
-	bridge *huego.Bridge
+	state *reconcile.StateCache
+
+	mu            sync.Mutex
+	stabilization time.Duration
+	recent        map[string]recentActual // optimistic state per light after a write
+}
+
+// recentActual is the state a light is expected to settle in after a write.
+type recentActual struct {
+	actual  Actual
+	expires time.Time
 }
 
 // NewActualProvider creates a new actual state provider.
-func NewActualProvider(bridge *huego.Bridge) *ActualProvider {
+func NewActualProvider(state *reconcile.StateCache) *ActualProvider {
 	return &ActualProvider{
-		bridge: bridge,
+		state: state,
+	}
+}
+
+// SetStabilization makes Get return the state recorded by Remember for
+// window after a write, instead of fetching a state the bridge may still
+// report as transitioning. 0 disables it.
+func (p *ActualProvider) SetStabilization(window time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stabilization = window
+	p.recent = nil
+}
+
+// Remember records the state a light is expected to settle in after a write,
+// and drops the bridge snapshot so later reads see the write.
+func (p *ActualProvider) Remember(lightID string, actual Actual) {
+	p.state.Invalidate()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stabilization <= 0 {
+		return
+	}
+	if p.recent == nil {
+		p.recent = make(map[string]recentActual)
+	}
+	p.recent[lightID] = recentActual{actual: actual, expires: time.Now().Add(p.stabilization)}
+}
+
+// BeginPass drops the bridge snapshot, so each reconcile pass reads the
+// bridge once.
+func (p *ActualProvider) BeginPass() {
+	p.state.BeginPass()
+}
+
+// Forget drops all recorded states, so the next Get fetches from the bridge.
+func (p *ActualProvider) Forget() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.recent = nil
+}
+
+// recall returns the recorded state of a light if it has not expired yet.
+func (p *ActualProvider) recall(lightID string) (Actual, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	r, ok := p.recent[lightID]
+	if !ok {
+		return Actual{}, false
+	}
+	if time.Now().After(r.expires) {
+		delete(p.recent, lightID)
+		return Actual{}, false
 	}
+	return r.actual, true
 }
 
-// Get returns the actual state for a light by fetching from the bridge.
+// Get returns the actual state for a light, from the state recorded after a
+// recent write if still within the stabilization window, else from the bridge.
 func (p *ActualProvider) Get(ctx context.Context, lightID string) (Actual, error) {
-	id, err := strconv.Atoi(lightID)
-	if err != nil {
-		return Actual{}, err
+	if actual, ok := p.recall(lightID); ok {
+		return actual, nil
 	}
 
-	light, err := p.bridge.GetLight(id)
+	state, err := p.state.State(ctx)
 	if err != nil {
 		return Actual{}, err
 	}
+	l, ok := state.Lights[lightID]
+	if !ok {
+		return Actual{}, fmt.Errorf("light %s not found on bridge", lightID)
+	}
 
-	actual := Actual{}
-	if light.State != nil {
-		actual.On = light.State.On
-		actual.Bri = light.State.Bri
-		actual.Hue = light.State.Hue
-		actual.Sat = light.State.Sat
-		actual.Xy = light.State.Xy
-		actual.Ct = light.State.Ct
+	actual := Actual{
+		On:        l.On,
+		Bri:       l.Bri,
+		Hue:       l.Hue,
+		Sat:       l.Sat,
+		Xy:        l.Xy,
+		Ct:        l.Ct,
+		Reachable: l.Reachable,
 	}
 
 	return actual, nil
 }
 
+// ExpectedActual returns the state a light is expected to settle in after
+// desired was applied (turning it on), starting from prev.
+func ExpectedActual(prev Actual, desired Desired) Actual {
+	next := prev
+	next.On = true
+	if desired.Bri != nil {
+		next.Bri = *desired.Bri
+	}
+	if desired.Hue != nil {
+		next.Hue = *desired.Hue
+	}
+	if desired.Sat != nil {
+		next.Sat = *desired.Sat
+	}
+	if desired.Xy != nil {
+		next.Xy = append([]float32(nil), desired.Xy...)
+	}
+	if desired.Ct != nil {
+		next.Ct = *desired.Ct
+	}
+	return next
+}
+
@@ -17,11 +17,11 @@ type Applier interface {
 
 // HueApplier implements Applier using the Hue bridge.
 type HueApplier struct {
-	bridge *huego.Bridge
+	bridge func() *huego.Bridge // current bridge (replaced on key rotation)
 }
 
 // NewHueApplier creates a new light applier.
-func NewHueApplier(bridge *huego.Bridge) *HueApplier {
+func NewHueApplier(bridge func() *huego.Bridge) *HueApplier {
 	return &HueApplier{
 		bridge: bridge,
 	}
@@ -34,7 +34,7 @@ func (a *HueApplier) Apply(ctx context.Context, lightID string, desired Desired)
 		return err
 	}
 
-	light, err := a.bridge.GetLight(id)
+	light, err := a.bridge().GetLight(id)
 	if err != nil {
 		return err
 	}
@@ -91,7 +91,7 @@ func (a *HueApplier) TurnOn(ctx context.Context, lightID string) error {
 		return err
 	}
 
-	light, err := a.bridge.GetLight(id)
+	light, err := a.bridge().GetLight(id)
 	if err != nil {
 		return err
 	}
@@ -107,7 +107,7 @@ func (a *HueApplier) TurnOff(ctx context.Context, lightID string) error {
 		return err
 	}
 
-	light, err := a.bridge.GetLight(id)
+	light, err := a.bridge().GetLight(id)
 	if err != nil {
 		return err
 	}
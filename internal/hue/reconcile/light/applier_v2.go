@@ -0,0 +1,90 @@
+package light
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+// V2Applier implements Applier using the Hue V2 (CLIP) API.
+type V2Applier struct {
+	client reconcile.V2Client
+}
+
+// NewV2Applier creates a new V2 light applier.
+func NewV2Applier(client reconcile.V2Client) *V2Applier {
+	return &V2Applier{
+		client: client,
+	}
+}
+
+// Apply applies the desired state to a light.
+func (a *V2Applier) Apply(ctx context.Context, lightID string, desired Desired) error {
+	if desired.Hue != nil || desired.Sat != nil {
+		log.Debug().Str("light", lightID).Msg("Ignoring hue/sat: not supported by V2 API")
+	}
+
+	update := reconcile.V2StateUpdate(desired.Power, desired.Bri, desired.Xy, desired.Ct)
+	if len(update) == 0 {
+		return nil
+	}
+
+	id, err := a.client.ResolveV1(ctx, "light", "/lights/"+lightID)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("light", lightID).
+		Interface("state", update).
+		Msg("Applying state to light (V2)")
+	return a.client.UpdateResource(ctx, "light", id, update)
+}
+
+// TurnOn turns on a light.
+func (a *V2Applier) TurnOn(ctx context.Context, lightID string) error {
+	log.Info().Str("light", lightID).Msg("Turning on light (V2)")
+	on := true
+	return a.Apply(ctx, lightID, Desired{Power: &on})
+}
+
+// TurnOff turns off a light.
+func (a *V2Applier) TurnOff(ctx context.Context, lightID string) error {
+	log.Info().Str("light", lightID).Msg("Turning off light (V2)")
+	off := false
+	return a.Apply(ctx, lightID, Desired{Power: &off})
+}
+
+// VersionedApplier delegates to a V1 or V2 applier, chosen per call.
+// Used for api_version "auto", where the choice is made after connecting.
+type VersionedApplier struct {
+	v1    Applier
+	v2    Applier
+	useV2 func() bool
+}
+
+// NewVersionedApplier creates an applier that uses v2 whenever useV2 returns true.
+func NewVersionedApplier(v1, v2 Applier, useV2 func() bool) *VersionedApplier {
+	return &VersionedApplier{v1: v1, v2: v2, useV2: useV2}
+}
+
+func (a *VersionedApplier) pick() Applier {
+	if a.useV2() {
+		return a.v2
+	}
+	return a.v1
+}
+
+func (a *VersionedApplier) Apply(ctx context.Context, lightID string, desired Desired) error {
+	return a.pick().Apply(ctx, lightID, desired)
+}
+
+func (a *VersionedApplier) TurnOn(ctx context.Context, lightID string) error {
+	return a.pick().TurnOn(ctx, lightID)
+}
+
+func (a *VersionedApplier) TurnOff(ctx context.Context, lightID string) error {
+	return a.pick().TurnOff(ctx, lightID)
+}
@@ -12,6 +12,9 @@ type Provider struct {
 	store   *storage.TypedStore[Desired]
 	actual  *ActualProvider
 	applier Applier
+
+	// maxBriStep enables brightness ramping on resources (0 = disabled)
+	maxBriStep int
 }
 
 // NewProvider creates a new light provider.
@@ -41,7 +44,7 @@ func (p *Provider) ListDirty(ctx context.Context, lastVersions map[string]int64)
 
 	resources := make([]reconcile.Resource, 0, len(ids))
 	for _, id := range ids {
-		resources = append(resources, NewResource(id, p.store, p.actual, p.applier))
+		resources = append(resources, p.newResource(id))
 	}
 
 	return resources, nil
@@ -49,7 +52,42 @@ func (p *Provider) ListDirty(ctx context.Context, lastVersions map[string]int64)
 
 // Get returns a specific resource by ID.
 func (p *Provider) Get(ctx context.Context, id string) (reconcile.Resource, error) {
-	return NewResource(id, p.store, p.actual, p.applier), nil
+	return p.newResource(id), nil
+}
+
+// SetMaxBriStep limits brightness changes to step per apply; larger changes
+// are ramped over several applies. 0 disables ramping.
+func (p *Provider) SetMaxBriStep(step int) {
+	p.maxBriStep = step
+}
+
+// BeginPass implements reconcile.PassAware.
+func (p *Provider) BeginPass() {
+	p.actual.BeginPass()
+}
+
+// Hold sets a light's desired brightness to its current brightness, so a
+// ramp in progress stops where it is. Lights that are off or have no desired
+// brightness are left alone.
+func (p *Provider) Hold(ctx context.Context, lightID string) error {
+	desired, _, err := p.store.Get(lightID)
+	if err != nil || desired.Bri == nil {
+		return err
+	}
+	actual, err := p.actual.Get(ctx, lightID)
+	if err != nil || !actual.On {
+		return err
+	}
+
+	desired.Bri = &actual.Bri
+	return p.store.Set(lightID, desired)
+}
+
+// newResource creates a resource carrying the provider's ramp setting.
+func (p *Provider) newResource(id string) *Resource {
+	r := NewResource(id, p.store, p.actual, p.applier)
+	r.ramp.MaxStep = p.maxBriStep
+	return r
 }
 
 // ListAllIDs returns all resource IDs that have desired state.
@@ -66,8 +104,11 @@ func (p *Provider) ListAllIDs(ctx context.Context) ([]string, error) {
 	return ids, nil
 }
 
-// ClearCaches is a no-op for light provider (no caches).
-func (p *Provider) ClearCaches() {}
+// ClearCaches drops the states recorded for the stabilization window, so a
+// forced reconcile compares against the bridge.
+func (p *Provider) ClearCaches() {
+	p.actual.Forget()
+}
 
 // Store returns the typed store for direct access.
 func (p *Provider) Store() *storage.TypedStore[Desired] {
@@ -3,6 +3,8 @@ package light
 import (
 	"context"
 
+	"github.com/rs/zerolog/log"
+
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
@@ -18,6 +20,9 @@ type Resource struct {
 	desired        Desired
 	desiredVersion int64
 	actualState    Actual
+
+	// ramp steps brightness toward the target across ReconcileStep calls
+	ramp reconcile.BriRamp
 }
 
 // NewResource creates a new light resource.
@@ -61,9 +66,11 @@ func (r *Resource) Load(ctx context.Context) error {
 
 // NeedsReconcile returns true if actual != desired.
 func (r *Resource) NeedsReconcile() bool {
-	d := r.desired
-	a := r.actualState
+	return NeedsUpdate(r.desired, r.actualState)
+}
 
+// NeedsUpdate reports whether a light in state a differs from desired d.
+func NeedsUpdate(d Desired, a Actual) bool {
 	// Power transitions
 	if d.Power != nil {
 		if *d.Power && !a.On {
@@ -105,9 +112,17 @@ func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
 	case d.Power != nil && *d.Power && !a.On:
 		// OFF -> ON
 		// Apply all desired state at once (power + properties)
+		if step, ok := r.rampStep(0); ok {
+			if err := r.applier.Apply(ctx, r.lightID, step); err != nil {
+				return false, err
+			}
+			r.actual.Remember(r.lightID, ExpectedActual(a, step))
+			return false, nil
+		}
 		if err := r.applier.Apply(ctx, r.lightID, d); err != nil {
 			return false, err
 		}
+		r.actual.Remember(r.lightID, ExpectedActual(a, d))
 		return true, nil
 
 	case d.Power != nil && !*d.Power && a.On:
@@ -115,14 +130,25 @@ func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
 		if err := r.applier.TurnOff(ctx, r.lightID); err != nil {
 			return false, err
 		}
+		off := a
+		off.On = false
+		r.actual.Remember(r.lightID, off)
 		return true, nil
 
 	case a.On:
 		// Light is on, apply property changes
 		if r.needsPropertyUpdate() {
+			if step, ok := r.rampStep(a.Bri); ok {
+				if err := r.applier.Apply(ctx, r.lightID, step); err != nil {
+					return false, err
+				}
+				r.actual.Remember(r.lightID, ExpectedActual(a, step))
+				return false, nil
+			}
 			if err := r.applier.Apply(ctx, r.lightID, d); err != nil {
 				return false, err
 			}
+			r.actual.Remember(r.lightID, ExpectedActual(a, d))
 		}
 		return true, nil
 	}
@@ -130,6 +156,30 @@ func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
 	return true, nil // Nothing to do
 }
 
+// rampStep returns the desired state for an intermediate ramp step from
+// current, or false when the target brightness can be applied directly.
+func (r *Resource) rampStep(current uint8) (Desired, bool) {
+	if r.desired.Bri == nil {
+		return Desired{}, false
+	}
+
+	bri, final := r.ramp.Next(current, *r.desired.Bri)
+	if final {
+		return Desired{}, false
+	}
+
+	log.Debug().
+		Str("light", r.lightID).
+		Uint8("from", current).
+		Uint8("to", bri).
+		Uint8("target", *r.desired.Bri).
+		Msg("Ramping light brightness")
+
+	step := r.desired
+	step.Bri = &bri
+	return step, true
+}
+
 // needsPropertyUpdate checks if any property needs updating.
 func (r *Resource) needsPropertyUpdate() bool {
 	d := r.desired
@@ -153,6 +203,11 @@ func (r *Resource) needsPropertyUpdate() bool {
 	return false
 }
 
+// Reachable returns whether the light responded as reachable on last Load().
+func (r *Resource) Reachable() bool {
+	return r.actualState.Reachable
+}
+
 // DesiredVersion returns the version of the desired state.
 func (r *Resource) DesiredVersion() int64 {
 	return r.desiredVersion
@@ -14,11 +14,12 @@ type Desired struct {
 
 // Actual is the actual state of a light (from Hue).
 type Actual struct {
-	On  bool
-	Bri uint8
-	Hue uint16
-	Sat uint8
-	Xy  []float32
-	Ct  uint16
+	On        bool
+	Bri       uint8
+	Hue       uint16
+	Sat       uint8
+	Xy        []float32
+	Ct        uint16
+	Reachable bool
 }
 
@@ -0,0 +1,80 @@
+package reconcile
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OffDelays holds the pending delayed turn-offs of one resource kind. A
+// turn-off is held back for delay; if the desired state stops asking for it
+// in the meantime (e.g. a button press sets power on again), it is dropped.
+// A nil *OffDelays turns resources off immediately.
+type OffDelays struct {
+	mu      sync.Mutex
+	delay   time.Duration
+	trigger func(id string) // re-queues the resource when its delay expires
+	pending map[string]*pendingOff
+}
+
+type pendingOff struct {
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// NewOffDelays creates the delayed turn-off tracker for one resource kind.
+// trigger is called with the resource ID when its delay ends.
+func NewOffDelays(delay time.Duration, trigger func(id string)) *OffDelays {
+	return &OffDelays{
+		delay:   delay,
+		trigger: trigger,
+		pending: make(map[string]*pendingOff),
+	}
+}
+
+// Ready reports whether a turn-off of the resource may be applied now. The
+// first call starts the delay and returns false; the resource is re-queued
+// when it expires. Once the delay has passed, it returns true and forgets it.
+func (d *OffDelays) Ready(id string) bool {
+	if d == nil || d.delay <= 0 {
+		return true
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p, ok := d.pending[id]
+	if !ok {
+		d.pending[id] = &pendingOff{
+			deadline: time.Now().Add(d.delay),
+			timer:    time.AfterFunc(d.delay, func() { d.trigger(id) }),
+		}
+		log.Info().Str("resource", id).Dur("delay", d.delay).Msg("Delaying turn-off")
+		return false
+	}
+	if time.Now().Before(p.deadline) {
+		return false
+	}
+
+	delete(d.pending, id)
+	return true
+}
+
+// Cancel drops a pending turn-off of the resource, if any.
+func (d *OffDelays) Cancel(id string) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p, ok := d.pending[id]
+	if !ok {
+		return
+	}
+	p.timer.Stop()
+	delete(d.pending, id)
+	log.Info().Str("resource", id).Msg("Delayed turn-off cancelled")
+}
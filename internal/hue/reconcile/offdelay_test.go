@@ -0,0 +1,70 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOffDelays_ExpiryTriggersAndAllows(t *testing.T) {
+	triggered := make(chan string, 1)
+	d := NewOffDelays(20*time.Millisecond, func(id string) { triggered <- id })
+
+	if d.Ready("1") {
+		t.Fatal("first Ready should start the delay and return false")
+	}
+	if d.Ready("1") {
+		t.Fatal("Ready within the delay should return false")
+	}
+
+	select {
+	case id := <-triggered:
+		if id != "1" {
+			t.Errorf("triggered %q, want \"1\"", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("trigger not called after the delay")
+	}
+
+	if !d.Ready("1") {
+		t.Fatal("Ready after the delay should return true")
+	}
+	// The expired entry is forgotten: the next turn-off is delayed again
+	if d.Ready("1") {
+		t.Fatal("a new turn-off should start a new delay")
+	}
+	d.Cancel("1")
+}
+
+func TestOffDelays_CancelOnNewDesired(t *testing.T) {
+	triggered := make(chan string, 1)
+	d := NewOffDelays(20*time.Millisecond, func(id string) { triggered <- id })
+
+	if d.Ready("1") {
+		t.Fatal("first Ready should start the delay and return false")
+	}
+	d.Cancel("1")
+
+	select {
+	case id := <-triggered:
+		t.Fatalf("trigger called for %q after cancel", id)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// After cancelling, a later turn-off starts over instead of applying at once
+	if d.Ready("1") {
+		t.Fatal("Ready after cancel should start a new delay")
+	}
+	d.Cancel("1")
+}
+
+func TestOffDelays_Disabled(t *testing.T) {
+	var d *OffDelays
+	if !d.Ready("1") {
+		t.Error("nil OffDelays should allow turn-offs immediately")
+	}
+	d.Cancel("1") // must not panic
+
+	if !NewOffDelays(0, nil).Ready("1") {
+		t.Error("zero delay should allow turn-offs immediately")
+	}
+}
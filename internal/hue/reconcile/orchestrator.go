@@ -2,24 +2,50 @@ package reconcile
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/time/rate"
+
+	"github.com/dokzlo13/lightd/internal/events"
 )
 
+// maxConcurrentReconciles bounds how many resources of a kind are reconciled
+// at once in a pass, so a pass over many dirty resources doesn't open that
+// many simultaneous bridge requests.
+const maxConcurrentReconciles = 4
+
 // Orchestrator coordinates reconciliation across all resource types.
 // It's domain-agnostic - all resource-specific logic lives in providers.
 type Orchestrator struct {
 	providers map[Kind]ResourceProvider
-	limiter   *rate.Limiter
+	limiter   *rate.Limiter // global limiter, shared by every resource
+
+	limitersMu sync.RWMutex
+	limiters   map[string]*rate.Limiter // extra limits keyed by "kind" or "kind:id"
 
 	mu           sync.Mutex
 	lastVersions map[ResourceKey]int64    // tracks last reconciled version per resource
 	pending      map[ResourceKey]struct{} // manual triggers awaiting reconcile
+	traces       map[ResourceKey]string   // trace ID of the latest traced change per resource
+	unreachable  map[ResourceKey]struct{} // parked until re-queued
+	offline      map[ResourceKey]struct{} // reported unreachable, cleared on next success
 	trigger      chan struct{}
 
+	// passSem serializes reconcile passes with synchronous ReconcileNow calls
+	passSem chan struct{}
+
+	// paused stops passes from running; triggers keep accumulating in pending
+	paused atomic.Bool
+
+	// Lifecycle: stopped is closed when Run returns
+	running atomic.Bool
+	stopped chan struct{}
+
 	// Configuration
 	periodicInterval time.Duration
 	debounceMs       int
@@ -37,9 +63,15 @@ func NewOrchestrator(periodicInterval time.Duration, debounceMs int, rateLimitRP
 	return &Orchestrator{
 		providers:        make(map[Kind]ResourceProvider),
 		limiter:          limiter,
+		limiters:         make(map[string]*rate.Limiter),
 		lastVersions:     make(map[ResourceKey]int64),
 		pending:          make(map[ResourceKey]struct{}),
+		traces:           make(map[ResourceKey]string),
+		unreachable:      make(map[ResourceKey]struct{}),
+		offline:          make(map[ResourceKey]struct{}),
 		trigger:          make(chan struct{}, 1),
+		passSem:          make(chan struct{}, 1),
+		stopped:          make(chan struct{}),
 		periodicInterval: periodicInterval,
 		debounceMs:       debounceMs,
 	}
@@ -50,6 +82,41 @@ func (o *Orchestrator) Register(provider ResourceProvider) {
 	o.providers[provider.Kind()] = provider
 }
 
+// SetRateLimit sets an extra rate limit for a kind ("group") or a single
+// resource ("group:3"), applied on top of the global limit. Resources are
+// reconciled concurrently within a pass, so a throttled resource doesn't hold
+// up the others. rps <= 0 removes the override.
+func (o *Orchestrator) SetRateLimit(key string, rps float64) {
+	o.limitersMu.Lock()
+	defer o.limitersMu.Unlock()
+
+	if rps <= 0 {
+		delete(o.limiters, key)
+		return
+	}
+	burst := int(rps)
+	if burst < 1 {
+		burst = 1
+	}
+	o.limiters[key] = rate.NewLimiter(rate.Limit(rps), burst)
+	log.Debug().Str("key", key).Float64("rps", rps).Msg("Rate limit override registered")
+}
+
+// limitersFor returns the limiters a resource waits on: the most specific
+// override (per-resource, then per-kind), if any, followed by the global one.
+func (o *Orchestrator) limitersFor(key ResourceKey) []*rate.Limiter {
+	o.limitersMu.RLock()
+	defer o.limitersMu.RUnlock()
+
+	if l, ok := o.limiters[string(key.Kind)+":"+key.ID]; ok {
+		return []*rate.Limiter{l, o.limiter}
+	}
+	if l, ok := o.limiters[string(key.Kind)]; ok {
+		return []*rate.Limiter{l, o.limiter}
+	}
+	return []*rate.Limiter{o.limiter}
+}
+
 // Trigger signals that reconciliation should run.
 func (o *Orchestrator) Trigger() {
 	select {
@@ -67,6 +134,19 @@ func (o *Orchestrator) TriggerResource(key ResourceKey) {
 	o.Trigger()
 }
 
+// Trace records the trace ID of the event that changed the given resources'
+// desired state. The next pass reconciling them logs under that trace.
+func (o *Orchestrator) Trace(keys []ResourceKey, traceID string) {
+	if traceID == "" {
+		return
+	}
+	o.mu.Lock()
+	for _, key := range keys {
+		o.traces[key] = traceID
+	}
+	o.mu.Unlock()
+}
+
 // TriggerGroup is a convenience method for triggering group reconciliation.
 // Implements the Reconciler interface used by actions.
 func (o *Orchestrator) TriggerGroup(groupID string) {
@@ -79,6 +159,9 @@ func (o *Orchestrator) TriggerAll(ctx context.Context) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
+	// Forced reconcile re-probes parked resources too
+	o.unreachable = make(map[ResourceKey]struct{})
+
 	for kind, provider := range o.providers {
 		// Clear any cached state so reconciler will re-apply
 		provider.ClearCaches()
@@ -101,8 +184,111 @@ func (o *Orchestrator) TriggerAll(ctx context.Context) {
 	}
 }
 
+// Pause stops reconciliation without stopping the daemon. Triggers and
+// desired state changes keep accumulating and are applied on Resume.
+func (o *Orchestrator) Pause() {
+	if !o.paused.Swap(true) {
+		log.Info().Msg("Reconciliation paused")
+	}
+}
+
+// Resume re-enables reconciliation and runs a pass for everything that
+// accumulated while paused.
+func (o *Orchestrator) Resume() {
+	if o.paused.Swap(false) {
+		log.Info().Msg("Reconciliation resumed")
+		o.Trigger()
+	}
+}
+
+// Paused reports whether reconciliation is paused.
+func (o *Orchestrator) Paused() bool {
+	return o.paused.Load()
+}
+
+// RequeueUnreachable moves all parked unreachable resources back to pending
+// and triggers reconciliation. Called when a device reports connectivity again.
+func (o *Orchestrator) RequeueUnreachable() {
+	o.mu.Lock()
+	count := len(o.unreachable)
+	for key := range o.unreachable {
+		o.pending[key] = struct{}{}
+	}
+	o.unreachable = make(map[ResourceKey]struct{})
+	o.mu.Unlock()
+
+	if count > 0 {
+		log.Info().Int("count", count).Msg("Re-queued unreachable resources")
+		o.Trigger()
+	}
+}
+
+// ReconcileNow reconciles the given resources synchronously and returns once
+// they have been applied, failed, or ctx expired. It waits for any in-flight
+// background pass first, so the two never drive the same resource at once.
+// Errors for individual resources are joined; an unreachable resource is
+// parked as in a regular pass and reported as ErrUnreachable. Returns
+// ErrPaused without touching anything while paused.
+func (o *Orchestrator) ReconcileNow(ctx context.Context, keys []ResourceKey) error {
+	if o.paused.Load() {
+		return ErrPaused
+	}
+
+	select {
+	case o.passSem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-o.passSem }()
+
+	var errs []error
+	for _, key := range keys {
+		provider, ok := o.providers[key.Kind]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s %s: no provider for kind", key.Kind, key.ID))
+			continue
+		}
+		if pa, ok := provider.(PassAware); ok {
+			pa.BeginPass()
+		}
+
+		r, err := provider.Get(ctx, key.ID)
+		if err == nil {
+			err = o.reconcileOne(ctx, r)
+		}
+		if err != nil {
+			if errors.Is(err, ErrUnreachable) {
+				o.park(key)
+			}
+			errs = append(errs, fmt.Errorf("%s %s: %w", key.Kind, key.ID, err))
+			continue
+		}
+
+		o.markReconciled(key, r.DesiredVersion())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Wait blocks until Run has returned, letting an in-flight reconcile pass
+// finish, or until ctx expires. Returns immediately if Run was never started.
+func (o *Orchestrator) Wait(ctx context.Context) error {
+	if !o.running.Load() {
+		return nil
+	}
+	select {
+	case <-o.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Run starts the reconciliation loop.
 func (o *Orchestrator) Run(ctx context.Context) error {
+	o.running.Store(true)
+	defer close(o.stopped)
+
 	log.Info().
 		Dur("periodic_interval", o.periodicInterval).
 		Int("debounce_ms", o.debounceMs).
@@ -156,18 +342,35 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 			o.reconcileAll(ctx)
 
 		case <-tickerC:
-			// Periodic reconciliation
+			// Periodic reconciliation, re-probing parked resources in case
+			// a connectivity event was missed
+			o.RequeueUnreachable()
 			o.reconcileAll(ctx)
 		}
 	}
 }
 
 func (o *Orchestrator) reconcileAll(ctx context.Context) {
+	// Leave pending untouched; Resume triggers a pass that picks it up
+	if o.paused.Load() {
+		log.Debug().Msg("Reconciliation paused, skipping pass")
+		return
+	}
+
+	select {
+	case o.passSem <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-o.passSem }()
+
 	// 1. Snapshot and clear pending (under lock, once)
 	log.Debug().Msg("Reconciliation started")
 	o.mu.Lock()
 	pendingSnapshot := o.pending
 	o.pending = make(map[ResourceKey]struct{})
+	traceSnapshot := o.traces
+	o.traces = make(map[ResourceKey]string)
 	// log.Debug().Int("pending_count", len(pendingSnapshot)).Msg("snapshotted pending resources")
 
 	// Build lastVersions per kind for dirty queries
@@ -179,12 +382,20 @@ func (o *Orchestrator) reconcileAll(ctx context.Context) {
 		lastByKind[key.Kind][key.ID] = ver
 	}
 	// log.Debug().Int("tracked_versions", len(o.lastVersions)).Msg("built lastVersions map")
+	parked := make(map[ResourceKey]struct{}, len(o.unreachable))
+	for key := range o.unreachable {
+		parked[key] = struct{}{}
+	}
 	o.mu.Unlock()
 
 	// 2. For each provider, get dirty + pending resources
 	for kind, provider := range o.providers {
 		// log.Debug().Str("kind", string(kind)).Msg("processing kind")
 
+		if pa, ok := provider.(PassAware); ok {
+			pa.BeginPass()
+		}
+
 		// Get dirty from store
 		dirty, err := provider.ListDirty(ctx, lastByKind[kind])
 		if err != nil {
@@ -216,40 +427,97 @@ func (o *Orchestrator) reconcileAll(ctx context.Context) {
 			log.Debug().Str("kind", string(kind)).Int("merged_pending", pendingForKind).Int("total", len(dirty)).Msg("merged pending resources")
 		}
 
-		// 3. Reconcile each resource
+		// 3. Reconcile each resource, concurrently so one waiting on its own
+		// rate limit doesn't hold up the rest (all share the global limiter),
+		// at most maxConcurrentReconciles at a time
 		log.Debug().Str("kind", string(kind)).Int("total_resources", len(dirty)).Msg("starting reconciliation")
-		successCount := 0
+		var successCount atomic.Int64
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, maxConcurrentReconciles)
 		for _, r := range dirty {
-			log.Debug().Str("kind", string(kind)).Str("id", r.Key().ID).Int64("version", r.DesiredVersion()).Msg("reconciling resource")
-
-			if err := o.reconcileOne(ctx, r); err != nil {
-				log.Error().Err(err).
-					Str("kind", string(kind)).
-					Str("id", r.Key().ID).
-					Msg("Reconcile failed")
+			if _, ok := parked[r.Key()]; ok {
+				log.Debug().Str("kind", string(kind)).Str("id", r.Key().ID).Msg("skipping unreachable resource")
 				continue
 			}
 
-			// Update last version on success
-			o.mu.Lock()
-			o.lastVersions[r.Key()] = r.DesiredVersion()
-			o.mu.Unlock()
+			traceID := traceSnapshot[r.Key()]
+			log.Debug().Str("kind", string(kind)).Str("id", r.Key().ID).Int64("version", r.DesiredVersion()).Str("trace_id", traceID).Msg("reconciling resource")
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(r Resource) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				if err := o.reconcileOne(events.WithTraceID(ctx, traceID), r); err != nil {
+					if errors.Is(err, ErrUnreachable) {
+						o.park(r.Key())
+						return
+					}
+
+					log.Error().Err(err).
+						Str("kind", string(kind)).
+						Str("id", r.Key().ID).
+						Str("trace_id", traceID).
+						Msg("Reconcile failed")
+					return
+				}
+
+				// Update last version on success
+				o.markReconciled(r.Key(), r.DesiredVersion())
 
-			successCount++
-			log.Debug().Str("kind", string(kind)).Str("id", r.Key().ID).Int64("version", r.DesiredVersion()).Msg("resource reconciled successfully")
+				successCount.Add(1)
+				log.Debug().Str("kind", string(kind)).Str("id", r.Key().ID).Int64("version", r.DesiredVersion()).Str("trace_id", traceID).Msg("resource reconciled successfully")
+			}(r)
 		}
+		wg.Wait()
 
-		log.Debug().Str("kind", string(kind)).Int("success", successCount).Int("total", len(dirty)).Msg("completed reconciliation for kind")
+		log.Debug().Str("kind", string(kind)).Int64("success", successCount.Load()).Int("total", len(dirty)).Msg("completed reconciliation for kind")
 	}
 
 	log.Debug().Msg("reconcileAll completed")
 }
 
+// markReconciled records the version a resource was reconciled at and
+// clears its offline flag, logging when it comes back.
+func (o *Orchestrator) markReconciled(key ResourceKey, version int64) {
+	o.mu.Lock()
+	o.lastVersions[key] = version
+	_, wasOffline := o.offline[key]
+	delete(o.offline, key)
+	o.mu.Unlock()
+
+	if wasOffline {
+		log.Info().Str("kind", string(key.Kind)).Str("id", key.ID).Msg("Resource reachable again")
+	}
+}
+
+// park marks a resource as unreachable, logging only on the first transition.
+// Its last version is left untouched so it stays dirty until re-queued.
+func (o *Orchestrator) park(key ResourceKey) {
+	o.mu.Lock()
+	_, already := o.offline[key]
+	o.unreachable[key] = struct{}{}
+	o.offline[key] = struct{}{}
+	o.mu.Unlock()
+
+	if !already {
+		log.Warn().
+			Str("kind", string(key.Kind)).
+			Str("id", key.ID).
+			Msg("Resource unreachable, skipping until it comes back online")
+	}
+}
+
 func (o *Orchestrator) reconcileOne(ctx context.Context, r Resource) error {
+	limiters := o.limitersFor(r.Key())
 	for {
-		// Rate limit
-		if err := o.limiter.Wait(ctx); err != nil {
-			return err
+		// Rate limit: the override first, so a throttled resource doesn't
+		// take global tokens it can't use yet
+		for _, limiter := range limiters {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
 		}
 
 		// Load current state
@@ -263,6 +531,12 @@ func (o *Orchestrator) reconcileOne(ctx context.Context, r Resource) error {
 			return nil
 		}
 
+		// Skip devices that can't be controlled right now; one already in
+		// its desired state (e.g. off and cut at the wall) is left alone
+		if rr, ok := r.(Reachability); ok && !rr.Reachable() {
+			return ErrUnreachable
+		}
+
 		// Perform one reconciliation step
 		done, err := r.ReconcileStep(ctx)
 		if err != nil {
@@ -0,0 +1,153 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimitersFor_OverridesAddToGlobal(t *testing.T) {
+	o := NewOrchestrator(0, 0, 10)
+	o.SetRateLimit("group", 5)
+	o.SetRateLimit("group:3", 2)
+
+	tests := []struct {
+		key      ResourceKey
+		wantRate float64 // rate of the override, 0 if none
+	}{
+		{ResourceKey{Kind: KindGroup, ID: "3"}, 2},
+		{ResourceKey{Kind: KindGroup, ID: "1"}, 5},
+		{ResourceKey{Kind: KindLight, ID: "1"}, 0},
+	}
+
+	for _, tt := range tests {
+		limiters := o.limitersFor(tt.key)
+		if last := limiters[len(limiters)-1]; last != o.limiter {
+			t.Errorf("%v: global limiter not last in %d limiters", tt.key, len(limiters))
+		}
+		if tt.wantRate == 0 {
+			if len(limiters) != 1 {
+				t.Errorf("%v: got %d limiters, want only the global one", tt.key, len(limiters))
+			}
+			continue
+		}
+		if len(limiters) != 2 || float64(limiters[0].Limit()) != tt.wantRate {
+			t.Errorf("%v: want override at %v rps before the global limiter", tt.key, tt.wantRate)
+		}
+	}
+
+	o.SetRateLimit("group", 0)
+	if got := len(o.limitersFor(ResourceKey{Kind: KindGroup, ID: "1"})); got != 1 {
+		t.Errorf("after removing the kind override: got %d limiters, want 1", got)
+	}
+}
+
+// fakeResource is a resource whose state is set directly by the test
+type fakeResource struct {
+	needs     bool
+	reachable bool
+	steps     int
+}
+
+func (r *fakeResource) Key() ResourceKey               { return ResourceKey{Kind: KindLight, ID: "1"} }
+func (r *fakeResource) Load(ctx context.Context) error { return nil }
+func (r *fakeResource) NeedsReconcile() bool           { return r.needs }
+func (r *fakeResource) DesiredVersion() int64          { return 1 }
+func (r *fakeResource) Reachable() bool                { return r.reachable }
+func (r *fakeResource) ReconcileStep(ctx context.Context) (bool, error) {
+	r.steps++
+	r.needs = false
+	return true, nil
+}
+
+func TestReconcileOne_Reachability(t *testing.T) {
+	tests := []struct {
+		name      string
+		needs     bool
+		reachable bool
+		wantErr   error
+		wantSteps int
+	}{
+		{"unreachable and converged", false, false, nil, 0},
+		{"unreachable and drifted", true, false, ErrUnreachable, 0},
+		{"reachable and drifted", true, true, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewOrchestrator(0, 0, 0)
+			r := &fakeResource{needs: tt.needs, reachable: tt.reachable}
+			if err := o.reconcileOne(context.Background(), r); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("reconcileOne() error = %v, want %v", err, tt.wantErr)
+			}
+			if r.steps != tt.wantSteps {
+				t.Errorf("ReconcileStep calls = %d, want %d", r.steps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+// loadTracker records the peak number of concurrent Load calls
+type loadTracker struct {
+	active, peak atomic.Int32
+}
+
+// slowResource takes a while to load and always needs one reconcile step
+type slowResource struct {
+	id      string
+	tracker *loadTracker
+}
+
+func (r *slowResource) Key() ResourceKey      { return ResourceKey{Kind: KindLight, ID: r.id} }
+func (r *slowResource) NeedsReconcile() bool  { return false }
+func (r *slowResource) DesiredVersion() int64 { return 1 }
+func (r *slowResource) ReconcileStep(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *slowResource) Load(ctx context.Context) error {
+	n := r.tracker.active.Add(1)
+	defer r.tracker.active.Add(-1)
+	for {
+		peak := r.tracker.peak.Load()
+		if n <= peak || r.tracker.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+// slowProvider reports every one of its resources as dirty
+type slowProvider struct {
+	resources []Resource
+}
+
+func (p *slowProvider) Kind() Kind { return KindLight }
+func (p *slowProvider) ListDirty(ctx context.Context, lastVersions map[string]int64) ([]Resource, error) {
+	return p.resources, nil
+}
+func (p *slowProvider) ListAllIDs(ctx context.Context) ([]string, error) { return nil, nil }
+func (p *slowProvider) Get(ctx context.Context, id string) (Resource, error) {
+	return nil, errors.New("not found")
+}
+func (p *slowProvider) ClearCaches() {}
+
+func TestReconcileAll_BoundsConcurrency(t *testing.T) {
+	tracker := &loadTracker{}
+	provider := &slowProvider{}
+	for i := 0; i < 3*maxConcurrentReconciles; i++ {
+		provider.resources = append(provider.resources, &slowResource{id: strconv.Itoa(i), tracker: tracker})
+	}
+
+	o := NewOrchestrator(0, 0, 1000)
+	o.Register(provider)
+	o.reconcileAll(context.Background())
+
+	if peak := tracker.peak.Load(); peak > maxConcurrentReconciles {
+		t.Errorf("peak concurrent loads = %d, want at most %d", peak, maxConcurrentReconciles)
+	}
+}
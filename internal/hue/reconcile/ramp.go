@@ -0,0 +1,31 @@
+package reconcile
+
+// BriRamp limits how far brightness moves in one apply, so large jumps are
+// spread over several rate-limited steps (an automatic fade). The zero value
+// is disabled. A ramp is used for a single reconcileOne loop.
+type BriRamp struct {
+	MaxStep int // max brightness change per apply (1-253), 0 = disabled
+
+	steps int
+}
+
+// Next returns the brightness to apply next when moving from current to
+// target, and whether that is the target itself. After enough steps to cover
+// the full range it always returns the target, so a bridge that doesn't
+// report the stepped value cannot stall reconciliation.
+func (r *BriRamp) Next(current, target uint8) (uint8, bool) {
+	if r.MaxStep <= 0 || r.steps > 254/r.MaxStep+1 {
+		return target, true
+	}
+
+	diff := int(target) - int(current)
+	switch {
+	case diff > r.MaxStep:
+		r.steps++
+		return uint8(int(current) + r.MaxStep), false
+	case -diff > r.MaxStep:
+		r.steps++
+		return uint8(int(current) - r.MaxStep), false
+	}
+	return target, true
+}
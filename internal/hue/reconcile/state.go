@@ -0,0 +1,99 @@
+package reconcile
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LightState is the state of one light as read from the bridge.
+type LightState struct {
+	On        bool
+	Bri       uint8 // 1-254
+	Hue       uint16
+	Sat       uint8
+	Xy        []float32
+	Ct        uint16
+	Reachable bool
+}
+
+// GroupState is a group (room or zone) as read from the bridge.
+type GroupState struct {
+	Lights []string // member light IDs (V1)
+}
+
+// BridgeState is a snapshot of all lights and groups, keyed by V1 ID.
+type BridgeState struct {
+	Lights map[string]LightState
+	Groups map[string]GroupState
+}
+
+// StateSource fetches a BridgeState from the bridge.
+type StateSource interface {
+	FetchState(ctx context.Context) (*BridgeState, error)
+}
+
+// maxStateAge bounds how long a snapshot is reused outside reconcile passes
+// (e.g. by ctx.actual or health endpoints).
+const maxStateAge = time.Second
+
+// StateCache shares one bridge snapshot between the actual-state providers,
+// so a reconcile pass lists lights and groups once instead of per resource.
+// The snapshot is dropped at the start of each pass (BeginPass), after every
+// write (Invalidate), and once older than maxStateAge.
+type StateCache struct {
+	source StateSource
+
+	mu      sync.Mutex
+	state   *BridgeState
+	fetched time.Time
+
+	// Last snapshot fetched, kept when state is dropped (see Last)
+	last *BridgeState
+}
+
+// NewStateCache creates a cache that fetches snapshots from source.
+func NewStateCache(source StateSource) *StateCache {
+	return &StateCache{source: source}
+}
+
+// State returns the current snapshot, fetching a new one if needed.
+func (c *StateCache) State(ctx context.Context) (*BridgeState, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.state != nil && time.Since(c.fetched) < maxStateAge {
+		return c.state, nil
+	}
+
+	state, err := c.source.FetchState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.state = state
+	c.last = state
+	c.fetched = time.Now()
+	return state, nil
+}
+
+// Last returns the most recently fetched snapshot and when it was fetched,
+// without contacting the bridge, even if it has since been dropped. Returns
+// nil before the first fetch.
+func (c *StateCache) Last() (*BridgeState, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.last, c.fetched
+}
+
+// Invalidate drops the snapshot so the next State call refetches it.
+func (c *StateCache) Invalidate() {
+	c.mu.Lock()
+	c.state = nil
+	c.mu.Unlock()
+}
+
+// BeginPass implements PassAware: each pass starts from a fresh snapshot.
+func (c *StateCache) BeginPass() {
+	c.Invalidate()
+}
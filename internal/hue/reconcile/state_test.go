@@ -0,0 +1,43 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+)
+
+// countingSource returns an empty snapshot and counts fetches.
+type countingSource struct{ fetches int }
+
+func (s *countingSource) FetchState(ctx context.Context) (*BridgeState, error) {
+	s.fetches++
+	return &BridgeState{}, nil
+}
+
+func TestStateCache_FetchesOncePerPass(t *testing.T) {
+	source := &countingSource{}
+	cache := NewStateCache(source)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := cache.State(ctx); err != nil {
+			t.Fatalf("State error = %v", err)
+		}
+	}
+	if source.fetches != 1 {
+		t.Errorf("fetches = %d, want 1 within a pass", source.fetches)
+	}
+
+	cache.BeginPass()
+	cache.State(ctx)
+	if source.fetches != 2 {
+		t.Errorf("fetches = %d, want 2 after BeginPass", source.fetches)
+	}
+
+	// A write invalidates the snapshot
+	cache.Invalidate()
+	cache.State(ctx)
+	cache.State(ctx)
+	if source.fetches != 3 {
+		t.Errorf("fetches = %d, want 3 after Invalidate", source.fetches)
+	}
+}
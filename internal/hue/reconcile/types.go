@@ -2,7 +2,17 @@
 // actual state match desired state.
 package reconcile
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrUnreachable is returned when a resource's device does not respond
+// (e.g. powered off at the wall). Such resources are parked, not retried.
+var ErrUnreachable = errors.New("resource unreachable")
+
+// ErrPaused is returned by synchronous reconciliation while the orchestrator is paused.
+var ErrPaused = errors.New("reconciler paused")
 
 // Kind identifies a type of reconcilable resource.
 type Kind string
@@ -11,6 +21,7 @@ type Kind string
 const (
 	KindGroup Kind = "group"
 	KindLight Kind = "light"
+	KindZone  Kind = "zone"
 )
 
 // ResourceKey uniquely identifies a reconcilable resource.
@@ -41,6 +52,19 @@ type Resource interface {
 	DesiredVersion() int64
 }
 
+// Reachability is optionally implemented by resources that can report whether
+// the underlying device currently responds. Checked after Load(), and only
+// when the resource needs reconciling.
+type Reachability interface {
+	Reachable() bool
+}
+
+// PassAware is optionally implemented by providers that cache data for the
+// duration of one reconcile pass. BeginPass is called before ListDirty.
+type PassAware interface {
+	BeginPass()
+}
+
 // ResourceProvider creates and manages resources of a specific kind.
 type ResourceProvider interface {
 	// Kind returns the resource type this provider handles.
@@ -0,0 +1,52 @@
+package reconcile
+
+import (
+	"context"
+	"math"
+)
+
+// V2Client is the subset of the Hue V2 client used by V2 appliers.
+// Defined here to avoid an import cycle between hue/v2 and the resource packages.
+type V2Client interface {
+	ResolveV1(ctx context.Context, rtype, v1Path string) (string, error)
+	UpdateResource(ctx context.Context, rtype, id string, update map[string]interface{}) error
+	UpdateGroupedLight(ctx context.Context, groupedLightID string, update map[string]interface{}) error
+	RecallScene(ctx context.Context, sceneID string) error
+}
+
+// V2StateUpdate builds a light/grouped_light update body from V1-style values.
+// Brightness (1-254) is converted to a percentage; nil/empty values are omitted.
+// V1 hue/sat have no V2 equivalent and must be handled by the caller.
+func V2StateUpdate(on *bool, bri *uint8, xy []float32, ct *uint16) map[string]interface{} {
+	update := map[string]interface{}{}
+
+	if on != nil {
+		update["on"] = map[string]interface{}{"on": *on}
+	}
+	if bri != nil {
+		update["dimming"] = map[string]interface{}{"brightness": BriToPercent(*bri)}
+	}
+	if len(xy) == 2 {
+		update["color"] = map[string]interface{}{
+			"xy": map[string]interface{}{"x": xy[0], "y": xy[1]},
+		}
+	}
+	if ct != nil {
+		update["color_temperature"] = map[string]interface{}{"mirek": *ct}
+	}
+
+	return update
+}
+
+// BriToPercent converts V1 brightness (1-254) to the V2 percentage (0-100),
+// rounded to two decimals.
+func BriToPercent(bri uint8) float64 {
+	return math.Round(float64(bri)/254*10000) / 100
+}
+
+// PercentToBri converts a V2 percentage to V1 brightness (1-254), clamping it
+// to 0-100 first. 0% is the dimmest setting (bri 1), not off.
+func PercentToBri(pct float64) uint8 {
+	pct = math.Min(100, math.Max(0, pct))
+	return uint8(math.Max(1, math.Round(pct*254/100)))
+}
@@ -0,0 +1,202 @@
+package zone
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// Provider provides zone resources for reconciliation.
+//
+// Zone membership is resolved once per reconcile pass. When zones overlap,
+// each light belongs to the zone whose desired state was written most
+// recently, so a pass never applies conflicting state to the same light.
+// Member groups a zone only partly owns are driven light by light when a
+// light applier is set (see SetLights), and left alone otherwise.
+type Provider struct {
+	store    *storage.TypedStore[Desired]
+	topology TopologySource
+	actual   *group.ActualProvider
+	applier  group.Applier
+
+	// lightActual and lightApplier drive partly owned member groups per
+	// light (nil = skip those groups)
+	lightActual  *light.ActualProvider
+	lightApplier light.Applier
+
+	// offDelays holds back scheduled turn-offs (nil = turn off immediately)
+	offDelays *reconcile.OffDelays
+
+	mu   sync.Mutex
+	pass *passState // nil until first needed in a pass
+}
+
+// passState is the membership snapshot shared by a pass's resources.
+type passState struct {
+	topology *Topology
+	owners   map[string]string // light ID -> owning zone ID
+}
+
+// NewProvider creates a new zone provider. Zones are applied through the
+// group actual provider and applier.
+func NewProvider(
+	store *storage.TypedStore[Desired],
+	topology TopologySource,
+	actual *group.ActualProvider,
+	applier group.Applier,
+) *Provider {
+	return &Provider{
+		store:    store,
+		topology: topology,
+		actual:   actual,
+		applier:  applier,
+	}
+}
+
+// SetOffDelay holds back turning a zone off for delay when the power-off was
+// set by a scheduled action, like group.Provider.SetOffDelay. trigger is
+// called with the zone ID when the delay ends. 0 disables the delay.
+func (p *Provider) SetOffDelay(delay time.Duration, trigger func(zoneID string)) {
+	if delay <= 0 {
+		p.offDelays = nil
+		return
+	}
+	p.offDelays = reconcile.NewOffDelays(delay, trigger)
+}
+
+// SetLights lets zones drive the lights they own in a member group that
+// another zone partly owns, through the light actual provider and applier.
+func (p *Provider) SetLights(actual *light.ActualProvider, applier light.Applier) {
+	p.lightActual = actual
+	p.lightApplier = applier
+}
+
+// Kind returns the resource kind.
+func (p *Provider) Kind() reconcile.Kind {
+	return reconcile.KindZone
+}
+
+// BeginPass drops the membership and bridge snapshots so they are reloaded
+// for this pass.
+func (p *Provider) BeginPass() {
+	p.mu.Lock()
+	p.pass = nil
+	p.mu.Unlock()
+	p.actual.BeginPass()
+	if p.lightActual != nil {
+		p.lightActual.BeginPass()
+	}
+}
+
+// ListDirty returns resources that have changed since last reconcile.
+func (p *Provider) ListDirty(ctx context.Context, lastVersions map[string]int64) ([]reconcile.Resource, error) {
+	ids, err := p.store.GetDirty(lastVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]reconcile.Resource, 0, len(ids))
+	for _, id := range ids {
+		resources = append(resources, newResource(id, p))
+	}
+	return resources, nil
+}
+
+// Get returns a specific resource by ID.
+func (p *Provider) Get(ctx context.Context, id string) (reconcile.Resource, error) {
+	return newResource(id, p), nil
+}
+
+// ListAllIDs returns all zone names that have desired state.
+func (p *Provider) ListAllIDs(ctx context.Context) ([]string, error) {
+	_, versions, err := p.store.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(versions))
+	for id := range versions {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ClearCaches drops the membership snapshot.
+func (p *Provider) ClearCaches() {
+	p.BeginPass()
+}
+
+// Store returns the typed store for direct access.
+func (p *Provider) Store() *storage.TypedStore[Desired] {
+	return p.store
+}
+
+// currentPass returns the pass snapshot, loading it on first use.
+func (p *Provider) currentPass(ctx context.Context) (*passState, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pass != nil {
+		return p.pass, nil
+	}
+
+	topology, err := p.topology.Topology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := p.store.GetUpdatedAt()
+	if err != nil {
+		return nil, err
+	}
+
+	p.pass = &passState{topology: topology, owners: lightOwners(topology, updated)}
+	return p.pass, nil
+}
+
+// lightOwners assigns every light in a zone with desired state to the most
+// recently written such zone (ties go to the alphabetically first name).
+// updated holds when each zone's desired state was written.
+func lightOwners(topology *Topology, updated map[string]time.Time) map[string]string {
+	ids := make([]string, 0, len(updated))
+	for id := range updated {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if !updated[ids[i]].Equal(updated[ids[j]]) {
+			return updated[ids[i]].After(updated[ids[j]])
+		}
+		return ids[i] < ids[j]
+	})
+
+	owners := make(map[string]string)
+	for _, id := range ids {
+		z, ok := topology.Zone(id)
+		if !ok {
+			continue
+		}
+		for _, lightID := range z.Lights {
+			if _, taken := owners[lightID]; !taken {
+				owners[lightID] = id
+			}
+		}
+	}
+	return owners
+}
+
+// ownedLights returns the lights of g that zoneID controls, and whether that
+// is all of them. Lights no zone claims belong to zoneID.
+func ownedLights(zoneID string, g Group, owners map[string]string) ([]string, bool) {
+	var owned []string
+	for _, lightID := range g.Lights {
+		if owner, ok := owners[lightID]; !ok || owner == zoneID {
+			owned = append(owned, lightID)
+		}
+	}
+	return owned, len(owned) == len(g.Lights)
+}
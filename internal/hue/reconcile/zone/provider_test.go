@@ -0,0 +1,104 @@
+package zone
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLightOwners_OverlapResolution(t *testing.T) {
+	topology := &Topology{
+		Zones: map[string]Group{
+			"downstairs": {ID: "10", Lights: []string{"1", "2", "3"}},
+			"kitchen":    {ID: "11", Lights: []string{"3", "4"}},
+			"everything": {ID: "12", Lights: []string{"1", "2", "3", "4", "5"}},
+		},
+	}
+	base := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name    string
+		updated map[string]time.Time
+		want    map[string]string
+	}{
+		{
+			name:    "single zone owns all its lights",
+			updated: map[string]time.Time{"downstairs": base},
+			want:    map[string]string{"1": "downstairs", "2": "downstairs", "3": "downstairs"},
+		},
+		{
+			name: "newest zone wins shared lights",
+			updated: map[string]time.Time{
+				"downstairs": base,
+				"kitchen":    base.Add(time.Second),
+			},
+			want: map[string]string{"1": "downstairs", "2": "downstairs", "3": "kitchen", "4": "kitchen"},
+		},
+		{
+			name: "sub-second writes keep their order",
+			updated: map[string]time.Time{
+				"kitchen":    base.Add(time.Millisecond),
+				"downstairs": base.Add(2 * time.Millisecond),
+			},
+			want: map[string]string{"1": "downstairs", "2": "downstairs", "3": "downstairs", "4": "kitchen"},
+		},
+		{
+			name: "equal times go to the first name",
+			updated: map[string]time.Time{
+				"kitchen":    base,
+				"downstairs": base,
+			},
+			want: map[string]string{"1": "downstairs", "2": "downstairs", "3": "downstairs", "4": "kitchen"},
+		},
+		{
+			name: "older superset only keeps unclaimed lights",
+			updated: map[string]time.Time{
+				"everything": base,
+				"kitchen":    base.Add(time.Second),
+			},
+			want: map[string]string{"1": "everything", "2": "everything", "3": "kitchen", "4": "kitchen", "5": "everything"},
+		},
+		{
+			name: "zones missing on the bridge are ignored",
+			updated: map[string]time.Time{
+				"attic":   base.Add(time.Second),
+				"kitchen": base,
+			},
+			want: map[string]string{"3": "kitchen", "4": "kitchen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lightOwners(topology, tt.updated)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lightOwners = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnedLights_PartialOverlap(t *testing.T) {
+	room := Group{ID: "1", Lights: []string{"1", "2", "3"}}
+
+	tests := []struct {
+		name    string
+		owners  map[string]string
+		want    []string
+		wantAll bool
+	}{
+		{"owns every light", map[string]string{"1": "a", "2": "a", "3": "a"}, []string{"1", "2", "3"}, true},
+		{"unclaimed lights count as owned", map[string]string{"1": "a"}, []string{"1", "2", "3"}, true},
+		{"partly owned", map[string]string{"1": "a", "2": "b", "3": "a"}, []string{"1", "3"}, false},
+		{"owned by another zone", map[string]string{"1": "b", "2": "b", "3": "b"}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, all := ownedLights("a", room, tt.owners)
+			if !reflect.DeepEqual(got, tt.want) || all != tt.wantAll {
+				t.Errorf("ownedLights = %v, %v; want %v, %v", got, all, tt.want, tt.wantAll)
+			}
+		})
+	}
+}
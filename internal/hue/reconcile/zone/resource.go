@@ -0,0 +1,250 @@
+package zone
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
+)
+
+// Resource reconciles a zone by driving each member group through the group
+// FSM, and the lights it owns in partly owned groups one by one.
+type Resource struct {
+	zoneID   string
+	provider *Provider
+
+	// Internal state populated by Load()
+	desired        Desired
+	desiredVersion int64
+	members        []member
+	lights         []lightMember
+}
+
+// member is a group driven by this zone and its actual state.
+type member struct {
+	groupID string
+	actual  group.Actual
+}
+
+// lightMember is a light driven by this zone on its own, because another
+// zone owns part of its group.
+type lightMember struct {
+	lightID string
+	actual  light.Actual
+}
+
+func newResource(zoneID string, provider *Provider) *Resource {
+	return &Resource{zoneID: zoneID, provider: provider}
+}
+
+// Key returns the resource key.
+func (r *Resource) Key() reconcile.ResourceKey {
+	return reconcile.ResourceKey{Kind: reconcile.KindZone, ID: r.zoneID}
+}
+
+// Load fetches desired state, resolves member groups and loads their actual state.
+func (r *Resource) Load(ctx context.Context) error {
+	var err error
+
+	r.desired, r.desiredVersion, err = r.provider.store.Get(r.zoneID)
+	if err != nil {
+		return err
+	}
+
+	pass, err := r.provider.currentPass(ctx)
+	if err != nil {
+		return err
+	}
+
+	z, ok := pass.topology.Zone(r.zoneID)
+	if !ok {
+		return fmt.Errorf("zone %q not found on bridge", r.zoneID)
+	}
+
+	r.members = r.members[:0]
+	r.lights = r.lights[:0]
+	for _, g := range pass.topology.Members(z) {
+		owned, all := ownedLights(r.zoneID, g, pass.owners)
+		if all {
+			actual, err := r.provider.actual.Get(ctx, g.ID)
+			if err != nil {
+				return err
+			}
+			r.members = append(r.members, member{groupID: g.ID, actual: actual})
+			continue
+		}
+
+		if len(owned) == 0 || r.provider.lightApplier == nil {
+			log.Debug().
+				Str("zone", r.zoneID).
+				Str("group", g.ID).
+				Int("owned_lights", len(owned)).
+				Msg("Skipping zone member controlled by a more recently set zone")
+			continue
+		}
+		for _, lightID := range owned {
+			actual, err := r.provider.lightActual.Get(ctx, lightID)
+			if err != nil {
+				return err
+			}
+			r.lights = append(r.lights, lightMember{lightID: lightID, actual: actual})
+		}
+	}
+
+	// A delayed turn-off is dropped once desired state no longer calls for it
+	if !r.desired.DelayOff || !r.turnsOff() {
+		r.provider.offDelays.Cancel(r.zoneID)
+	}
+
+	return nil
+}
+
+// turnsOff reports whether reconciling would turn off any reachable member.
+func (r *Resource) turnsOff() bool {
+	for _, m := range r.members {
+		if m.actual.Reachable && group.DetermineAction(r.desired, m.actual) == group.ActionTurnOff {
+			return true
+		}
+	}
+	if r.desired.Power != nil && !*r.desired.Power {
+		for _, l := range r.lights {
+			if l.actual.Reachable && l.actual.On {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lightDesired is the part of the zone's desired state that applies to a
+// single light. Scenes belong to groups, so lights only get power and color.
+func (r *Resource) lightDesired() light.Desired {
+	d := r.desired
+	return light.Desired{Power: d.Power, Bri: d.Bri, Hue: d.Hue, Sat: d.Sat, Xy: d.Xy, Ct: d.Ct}
+}
+
+// NeedsReconcile returns true if any reachable member differs from desired.
+func (r *Resource) NeedsReconcile() bool {
+	for _, m := range r.members {
+		if m.actual.Reachable && group.DetermineAction(r.desired, m.actual) != group.ActionNone {
+			return true
+		}
+	}
+	desired := r.lightDesired()
+	for _, l := range r.lights {
+		if l.actual.Reachable && light.NeedsUpdate(desired, l.actual) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReconcileStep applies the FSM action for every reachable member.
+// Unreachable members are skipped; errors are collected so one failing
+// member doesn't block the others.
+func (r *Resource) ReconcileStep(ctx context.Context) (done bool, err error) {
+	// Hold back scheduled turn-offs during the off delay; the zone is
+	// re-queued when it ends
+	if r.desired.DelayOff && r.turnsOff() && !r.provider.offDelays.Ready(r.zoneID) {
+		return true, nil
+	}
+
+	var errs []error
+	for _, m := range r.members {
+		if !m.actual.Reachable {
+			continue
+		}
+
+		action, reason := group.ExplainAction(r.desired, m.actual)
+		log.Debug().
+			Str("zone", r.zoneID).
+			Str("group", m.groupID).
+			Interface("desired", r.desired).
+			Interface("actual", m.actual).
+			Str("action", action.String()).
+			Str("reason", reason).
+			Str("trace_id", events.TraceIDFromContext(ctx)).
+			Msg("Zone reconcile step")
+
+		if action == group.ActionNone {
+			continue
+		}
+		if err := group.ExecuteAction(ctx, r.provider.applier, m.groupID, r.desired, action); err != nil {
+			errs = append(errs, fmt.Errorf("group %s: %w", m.groupID, err))
+			continue
+		}
+		r.provider.actual.Remember(m.groupID, group.ExpectedActual(m.actual, r.desired, action))
+	}
+
+	desired := r.lightDesired()
+	for _, l := range r.lights {
+		if !l.actual.Reachable || !light.NeedsUpdate(desired, l.actual) {
+			continue
+		}
+		if err := r.applyLight(ctx, l, desired); err != nil {
+			errs = append(errs, fmt.Errorf("light %s: %w", l.lightID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, errors.Join(errs...)
+	}
+	return true, nil
+}
+
+// applyLight moves a single light member to desired.
+func (r *Resource) applyLight(ctx context.Context, l lightMember, desired light.Desired) error {
+	log.Debug().
+		Str("zone", r.zoneID).
+		Str("light", l.lightID).
+		Interface("desired", desired).
+		Interface("actual", l.actual).
+		Str("trace_id", events.TraceIDFromContext(ctx)).
+		Msg("Zone light reconcile step")
+
+	if desired.Power != nil && !*desired.Power {
+		if err := r.provider.lightApplier.TurnOff(ctx, l.lightID); err != nil {
+			return err
+		}
+		off := l.actual
+		off.On = false
+		r.provider.lightActual.Remember(l.lightID, off)
+		return nil
+	}
+
+	if err := r.provider.lightApplier.Apply(ctx, l.lightID, desired); err != nil {
+		return err
+	}
+	r.provider.lightActual.Remember(l.lightID, light.ExpectedActual(l.actual, desired))
+	return nil
+}
+
+// Reachable returns whether any member group or light is reachable.
+// A zone whose members are all controlled by other zones is reachable.
+func (r *Resource) Reachable() bool {
+	if len(r.members) == 0 && len(r.lights) == 0 {
+		return true
+	}
+	for _, m := range r.members {
+		if m.actual.Reachable {
+			return true
+		}
+	}
+	for _, l := range r.lights {
+		if l.actual.Reachable {
+			return true
+		}
+	}
+	return false
+}
+
+// DesiredVersion returns the version of the desired state.
+func (r *Resource) DesiredVersion() int64 {
+	return r.desiredVersion
+}
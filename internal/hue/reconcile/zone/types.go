@@ -0,0 +1,80 @@
+// Package zone provides the reconciliation resource for Hue zones: named
+// sets of lights that span rooms, such as "everything" or "downstairs".
+package zone
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+)
+
+// Desired is the desired state for a zone. It has the same shape as a group's
+// and is applied to each member group through the group FSM.
+type Desired = group.Desired
+
+// Group is a V1 group (room or zone) and the lights it contains.
+type Group struct {
+	ID     string   // V1 group ID
+	Lights []string // light resource IDs
+}
+
+// Topology is a snapshot of the bridge's zones and rooms.
+type Topology struct {
+	Zones map[string]Group // keyed by lowercase zone name
+	Rooms []Group
+}
+
+// Zone returns the zone with the given name (case-insensitive).
+func (t *Topology) Zone(name string) (Group, bool) {
+	z, ok := t.Zones[strings.ToLower(name)]
+	return z, ok
+}
+
+// Members returns the groups that make up a zone. A zone made of whole rooms
+// is driven room by room, so per-room scenes apply; any other zone is driven
+// through its own group.
+func (t *Topology) Members(zone Group) []Group {
+	inZone := make(map[string]bool, len(zone.Lights))
+	for _, id := range zone.Lights {
+		inZone[id] = true
+	}
+
+	var rooms []Group
+	covered := 0
+	for _, room := range t.Rooms {
+		if len(room.Lights) == 0 || !containsAll(inZone, room.Lights) {
+			continue
+		}
+		rooms = append(rooms, room)
+		covered += len(room.Lights)
+	}
+
+	// Rooms partition lights, so equal counts mean the rooms cover the zone
+	if len(rooms) == 0 || covered != len(inZone) {
+		return []Group{zone}
+	}
+	return rooms
+}
+
+func containsAll(set map[string]bool, ids []string) bool {
+	for _, id := range ids {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// TopologySource loads the current zone/room topology from the bridge.
+type TopologySource interface {
+	Topology(ctx context.Context) (*Topology, error)
+}
+
+// TopologyFunc adapts a function to TopologySource.
+type TopologyFunc func(ctx context.Context) (*Topology, error)
+
+// Topology calls f.
+func (f TopologyFunc) Topology(ctx context.Context) (*Topology, error) {
+	return f(ctx)
+}
@@ -2,9 +2,11 @@ package hue
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/amimof/huego"
+	"github.com/rs/zerolog/log"
 )
 
 // SceneIndex provides efficient lookup for Hue scenes.
@@ -12,9 +14,10 @@ import (
 // This is a pure storage - caller is responsible for fetching and loading data.
 type SceneIndex struct {
 	mu        sync.RWMutex
-	scenes    []huego.Scene  // source of truth, stored once
-	byNameKey map[string]int // "groupID:name" -> index into scenes
-	byID      map[string]int // sceneID -> index into scenes
+	scenes    []huego.Scene    // source of truth, stored once
+	byNameKey map[string]int   // "groupID:name" -> index into scenes
+	byID      map[string]int   // sceneID -> index into scenes
+	byName    map[string][]int // name -> indexes into scenes, any group
 }
 
 // NewSceneIndex creates a new empty scene index.
@@ -22,6 +25,7 @@ func NewSceneIndex() *SceneIndex {
 	return &SceneIndex{
 		byNameKey: make(map[string]int),
 		byID:      make(map[string]int),
+		byName:    make(map[string][]int),
 	}
 }
 
@@ -34,6 +38,7 @@ func (s *SceneIndex) Load(scenes []huego.Scene) {
 	s.scenes = scenes
 	s.byNameKey = make(map[string]int, len(scenes))
 	s.byID = make(map[string]int, len(scenes))
+	s.byName = make(map[string][]int, len(scenes))
 
 	for i := range scenes {
 		// Index by groupID:name
@@ -42,6 +47,9 @@ func (s *SceneIndex) Load(scenes []huego.Scene) {
 
 		// Index by ID
 		s.byID[scenes[i].ID] = i
+
+		// Index by name alone (for group-less lookups)
+		s.byName[scenes[i].Name] = append(s.byName[scenes[i].Name], i)
 	}
 }
 
@@ -60,6 +68,71 @@ func (s *SceneIndex) FindByName(name, groupID string) (*huego.Scene, error) {
 	return &s.scenes[idx], nil
 }
 
+// FindByNameAny looks up a scene by name alone, ignoring its group.
+// When several scenes share the name, group scenes are preferred over light
+// scenes, then the lowest ID wins; the ambiguity is logged so the user can
+// rename or address the scene by group. Returns error if not found.
+func (s *SceneIndex) FindByNameAny(name string) (*huego.Scene, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idxs := s.byName[name]
+	if len(idxs) == 0 {
+		return nil, fmt.Errorf("scene '%s' not found", name)
+	}
+
+	best := idxs[0]
+	for _, idx := range idxs[1:] {
+		if betterScene(&s.scenes[idx], &s.scenes[best]) {
+			best = idx
+		}
+	}
+
+	if len(idxs) > 1 {
+		candidates := make([]string, 0, len(idxs))
+		for _, idx := range idxs {
+			candidates = append(candidates, s.scenes[idx].ID+" (group "+s.scenes[idx].Group+")")
+		}
+		log.Warn().
+			Str("scene", name).
+			Strs("candidates", candidates).
+			Str("chosen", s.scenes[best].ID).
+			Msg("Ambiguous scene name, multiple scenes match")
+	}
+
+	return &s.scenes[best], nil
+}
+
+// FindForGroup looks up a scene by name in the group, falling back to
+// FindByNameAny for scenes the bridge doesn't report under that group
+// (e.g. scenes spanning zones).
+func (s *SceneIndex) FindForGroup(name, groupID string) (*huego.Scene, error) {
+	scene, err := s.FindByName(name, groupID)
+	if err == nil {
+		return scene, nil
+	}
+
+	scene, anyErr := s.FindByNameAny(name)
+	if anyErr != nil {
+		return nil, err
+	}
+	log.Debug().
+		Str("scene", name).
+		Str("group", groupID).
+		Str("scene_group", scene.Group).
+		Msg("Scene not in group, using scene matched by name")
+	return scene, nil
+}
+
+// betterScene reports whether a is a better name-only match than b.
+func betterScene(a, b *huego.Scene) bool {
+	aGroup, bGroup := a.Type == "GroupScene", b.Type == "GroupScene"
+	if aGroup != bGroup {
+		return aGroup
+	}
+	return a.ID < b.ID
+}
+
 // FindByID looks up a scene by its ID.
 // Returns error if not found.
 func (s *SceneIndex) FindByID(sceneID string) (*huego.Scene, error) {
@@ -74,6 +147,21 @@ func (s *SceneIndex) FindByID(sceneID string) (*huego.Scene, error) {
 	return &s.scenes[idx], nil
 }
 
+// ForGroup returns the scenes the bridge reports under the group, sorted by name.
+func (s *SceneIndex) ForGroup(groupID string) []huego.Scene {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []huego.Scene
+	for _, scene := range s.scenes {
+		if scene.Group == groupID {
+			result = append(result, scene)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
 // GetAll returns all indexed scenes.
 func (s *SceneIndex) GetAll() []huego.Scene {
 	s.mu.RLock()
@@ -100,5 +188,5 @@ func (s *SceneIndex) Clear() {
 	s.scenes = nil
 	s.byNameKey = make(map[string]int)
 	s.byID = make(map[string]int)
+	s.byName = make(map[string][]int)
 }
-
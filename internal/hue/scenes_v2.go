@@ -0,0 +1,49 @@
+package hue
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amimof/huego"
+
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// FetchScenesV2 loads scenes through the V2 API and converts them to the
+// V1 shape used by SceneIndex. Scene IDs are V1 IDs where the bridge reports
+// one, otherwise V2 IDs; groups are V1 group IDs of the owning room or zone.
+func FetchScenesV2(ctx context.Context, client *v2.Client) ([]huego.Scene, error) {
+	// Map room/zone V2 IDs to V1 group IDs
+	groupIDs := make(map[string]string)
+	for _, rtype := range []string{"room", "zone"} {
+		resources, err := client.GetResources(ctx, rtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			if id, ok := strings.CutPrefix(r.IDV1, "/groups/"); ok {
+				groupIDs[r.ID] = id
+			}
+		}
+	}
+
+	scenes, err := client.GetScenes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]huego.Scene, 0, len(scenes))
+	for _, s := range scenes {
+		id := s.ID
+		if v1ID, ok := strings.CutPrefix(s.IDV1, "/scenes/"); ok {
+			id = v1ID
+		}
+		result = append(result, huego.Scene{
+			ID:    id,
+			Name:  s.Metadata.Name,
+			Group: groupIDs[s.Group.RID],
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,71 @@
+package hue
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// SensorReading is the latest reading of a sensor device or service.
+// Fields are nil when the sensor doesn't provide them or the value is invalid.
+type SensorReading struct {
+	Temperature *float64 // degrees Celsius
+	Motion      *bool
+	LightLevel  *int // 10000*log10(lux)+1
+}
+
+// sensorTypes are the V2 resource types read by ReadSensorV2
+var sensorTypes = []string{"temperature", "motion", "light_level"}
+
+// ReadSensorV2 reads the current values of a sensor. id may be a device ID
+// (all of its sensor services are read) or the ID of a single sensor service.
+func ReadSensorV2(ctx context.Context, client *v2.Client, id string) (*SensorReading, error) {
+	reading := &SensorReading{}
+	found := false
+
+	for _, rtype := range sensorTypes {
+		sensors, err := client.GetSensors(ctx, rtype)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range sensors {
+			if s.ID != id && s.Owner.RID != id {
+				continue
+			}
+			found = true
+			readSensor(s, reading)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("sensor %q not found", id)
+	}
+	return reading, nil
+}
+
+// readSensor copies the valid values of one sensor service into reading,
+// preferring the report block when present.
+func readSensor(s v2.Sensor, reading *SensorReading) {
+	if t := s.Temperature; t != nil && t.TemperatureValid {
+		value := t.Temperature
+		if t.TemperatureReport != nil {
+			value = t.TemperatureReport.Temperature
+		}
+		reading.Temperature = &value
+	}
+	if m := s.Motion; m != nil && m.MotionValid {
+		value := m.Motion
+		if m.MotionReport != nil {
+			value = m.MotionReport.Motion
+		}
+		reading.Motion = &value
+	}
+	if l := s.Light; l != nil && l.LightLevelValid {
+		value := l.LightLevel
+		if l.LightLevelReport != nil {
+			value = l.LightLevelReport.LightLevel
+		}
+		reading.LightLevel = &value
+	}
+}
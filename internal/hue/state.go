@@ -0,0 +1,159 @@
+package hue
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+// StateSource fetches bridge snapshots for the actual-state providers.
+type StateSource struct {
+	client *Client
+}
+
+// NewStateSource creates a state source reading through client.
+func NewStateSource(client *Client) *StateSource {
+	return &StateSource{client: client}
+}
+
+// FetchState lists all lights and groups through the API in use.
+func (s *StateSource) FetchState(ctx context.Context) (*reconcile.BridgeState, error) {
+	if s.client.V2Only() {
+		return s.fetchV2(ctx)
+	}
+	return s.fetchV1(ctx)
+}
+
+// fetchV1 lists lights and groups through the V1 API (two requests).
+func (s *StateSource) fetchV1(ctx context.Context) (*reconcile.BridgeState, error) {
+	bridge := s.client.V1()
+
+	lights, err := bridge.GetLightsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := bridge.GetGroupsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &reconcile.BridgeState{
+		Lights: make(map[string]reconcile.LightState, len(lights)),
+		Groups: make(map[string]reconcile.GroupState, len(groups)+1),
+	}
+	all := make([]string, 0, len(lights))
+	for _, l := range lights {
+		id := strconv.Itoa(l.ID)
+		all = append(all, id)
+		if l.State == nil {
+			state.Lights[id] = reconcile.LightState{}
+			continue
+		}
+		state.Lights[id] = reconcile.LightState{
+			On:        l.State.On,
+			Bri:       l.State.Bri,
+			Hue:       l.State.Hue,
+			Sat:       l.State.Sat,
+			Xy:        l.State.Xy,
+			Ct:        l.State.Ct,
+			Reachable: l.State.Reachable,
+		}
+	}
+	for _, g := range groups {
+		state.Groups[strconv.Itoa(g.ID)] = reconcile.GroupState{Lights: g.Lights}
+	}
+
+	// Group 0 is every light; the bridge does not list it
+	state.Groups["0"] = reconcile.GroupState{Lights: all}
+	return state, nil
+}
+
+// fetchV2 lists lights, their connectivity, rooms and zones through the V2
+// API, keyed by the V1 IDs the reconciler uses. V2 has no hue/sat, so those
+// are left zero.
+func (s *StateSource) fetchV2(ctx context.Context) (*reconcile.BridgeState, error) {
+	client := s.client.V2()
+
+	lights, err := client.GetLights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connectivity, err := client.GetConnectivity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := client.GetResources(ctx, "room")
+	if err != nil {
+		return nil, err
+	}
+	zones, err := client.GetResources(ctx, "zone")
+	if err != nil {
+		return nil, err
+	}
+
+	// Devices without a zigbee_connectivity service are assumed reachable
+	connected := make(map[string]bool, len(connectivity))
+	for _, c := range connectivity {
+		connected[c.Owner.RID] = c.Status == "connected"
+	}
+
+	state := &reconcile.BridgeState{
+		Lights: make(map[string]reconcile.LightState, len(lights)),
+		Groups: make(map[string]reconcile.GroupState, len(rooms)+len(zones)+1),
+	}
+	v1ByV2 := make(map[string]string, len(lights))
+	deviceLights := make(map[string][]string)
+	all := make([]string, 0, len(lights))
+	for _, l := range lights {
+		id, ok := strings.CutPrefix(l.IDV1, "/lights/")
+		if !ok {
+			continue
+		}
+		v1ByV2[l.ID] = id
+		deviceLights[l.Owner.RID] = append(deviceLights[l.Owner.RID], id)
+		all = append(all, id)
+
+		ls := reconcile.LightState{Reachable: true}
+		if ok, known := connected[l.Owner.RID]; known {
+			ls.Reachable = ok
+		}
+		if l.On != nil {
+			ls.On = l.On.On
+		}
+		if l.Dimming != nil {
+			ls.Bri = reconcile.PercentToBri(l.Dimming.Brightness)
+		}
+		if l.Color != nil {
+			ls.Xy = []float32{float32(l.Color.XY.X), float32(l.Color.XY.Y)}
+		}
+		if l.ColorTemperature != nil && l.ColorTemperature.MirekValid {
+			ls.Ct = uint16(l.ColorTemperature.Mirek)
+		}
+		state.Lights[id] = ls
+	}
+
+	// Rooms list devices; zones list lights or devices
+	for _, r := range append(rooms, zones...) {
+		id, ok := strings.CutPrefix(r.IDV1, "/groups/")
+		if !ok {
+			continue
+		}
+		var members []string
+		for _, child := range r.Children {
+			switch child.RType {
+			case "light":
+				if v1, ok := v1ByV2[child.RID]; ok {
+					members = append(members, v1)
+				}
+			case "device":
+				members = append(members, deviceLights[child.RID]...)
+			}
+		}
+		state.Groups[id] = reconcile.GroupState{Lights: members}
+	}
+
+	state.Groups["0"] = reconcile.GroupState{Lights: all}
+	return state, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/zone"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
 
@@ -13,6 +14,7 @@ type StoreRegistry struct {
 	base       *storage.Store
 	groupStore *storage.TypedStore[group.Desired]
 	lightStore *storage.TypedStore[light.Desired]
+	zoneStore  *storage.TypedStore[zone.Desired]
 }
 
 // NewStoreRegistry creates a new store registry with typed stores for each resource kind.
@@ -21,9 +23,15 @@ func NewStoreRegistry(base *storage.Store) *StoreRegistry {
 		base:       base,
 		groupStore: storage.NewTypedStore[group.Desired](base, string(reconcile.KindGroup)),
 		lightStore: storage.NewTypedStore[light.Desired](base, string(reconcile.KindLight)),
+		zoneStore:  storage.NewTypedStore[zone.Desired](base, string(reconcile.KindZone)),
 	}
 }
 
+// Base returns the untyped store shared by all typed stores.
+func (r *StoreRegistry) Base() *storage.Store {
+	return r.base
+}
+
 // Groups returns the typed store for group desired state.
 func (r *StoreRegistry) Groups() *storage.TypedStore[group.Desired] {
 	return r.groupStore
@@ -34,11 +42,19 @@ func (r *StoreRegistry) Lights() *storage.TypedStore[light.Desired] {
 	return r.lightStore
 }
 
+// Zones returns the typed store for zone desired state.
+func (r *StoreRegistry) Zones() *storage.TypedStore[zone.Desired] {
+	return r.zoneStore
+}
+
 // Clear removes all state from all stores.
 func (r *StoreRegistry) Clear() error {
 	if err := r.groupStore.Clear(); err != nil {
 		return err
 	}
-	return r.lightStore.Clear()
+	if err := r.lightStore.Clear(); err != nil {
+		return err
+	}
+	return r.zoneStore.Clear()
 }
 
@@ -0,0 +1,112 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MinEventStreamVersion is the oldest bridge firmware (software_version of
+// the bridge device) whose event stream is considered reliable. Earlier
+// firmware shipped the first V2 API releases, whose streams could stall or
+// drop events.
+const MinEventStreamVersion = "1.50"
+
+// BridgeInfo describes the bridge hardware and firmware
+type BridgeInfo struct {
+	BridgeID        string
+	Model           string
+	ProductName     string
+	SoftwareVersion string // e.g. "1.66.1966060010"
+}
+
+// GetBridgeInfo reads the bridge resource and the device that owns it, which
+// carries the model and software version.
+func (c *Client) GetBridgeInfo(ctx context.Context) (*BridgeInfo, error) {
+	resp, err := c.Request(ctx, "GET", "resource/bridge", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var bridges struct {
+		Data []struct {
+			BridgeID string      `json:"bridge_id"`
+			Owner    ResourceRef `json:"owner"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bridges); err != nil {
+		return nil, err
+	}
+	if len(bridges.Data) == 0 {
+		return nil, fmt.Errorf("bridge resource not found")
+	}
+	bridge := bridges.Data[0]
+	info := &BridgeInfo{BridgeID: bridge.BridgeID}
+
+	resp, err = c.Request(ctx, "GET", "resource/device/"+bridge.Owner.RID, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var devices struct {
+		Data []struct {
+			ProductData struct {
+				ModelID         string `json:"model_id"`
+				ProductName     string `json:"product_name"`
+				SoftwareVersion string `json:"software_version"`
+			} `json:"product_data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
+		return nil, err
+	}
+	if len(devices.Data) == 0 {
+		return nil, fmt.Errorf("bridge device %s not found", bridge.Owner.RID)
+	}
+	product := devices.Data[0].ProductData
+	info.Model = product.ModelID
+	info.ProductName = product.ProductName
+	info.SoftwareVersion = product.SoftwareVersion
+	return info, nil
+}
+
+// Outdated reports whether the firmware is older than MinEventStreamVersion.
+// An unparsable version is not reported as outdated.
+func (i *BridgeInfo) Outdated() bool {
+	return versionLess(i.SoftwareVersion, MinEventStreamVersion)
+}
+
+// versionLess compares dotted numeric versions component by component.
+// Missing components count as zero; returns false if either is unparsable.
+func versionLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		var err error
+		if i < len(pa) {
+			if x, err = strconv.Atoi(pa[i]); err != nil {
+				return false
+			}
+		}
+		if i < len(pb) {
+			if y, err = strconv.Atoi(pb[i]); err != nil {
+				return false
+			}
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
@@ -1,12 +1,14 @@
 package v2
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 )
 
 // Client provides access to Hue V2 API (CLIP API).
@@ -14,8 +16,10 @@ import (
 // Used for SSE events and individual light control.
 type Client struct {
 	address    string
-	token      string
 	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
 }
 
 // NewClient creates a new V2 API client.
@@ -35,9 +39,25 @@ func (c *Client) Address() string {
 
 // Token returns the application key (for SSE)
 func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.token
 }
 
+// SetToken replaces the application key used for subsequent requests.
+// An already open event stream keeps the old key until it reconnects.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// WithToken returns a client for the same bridge using a different
+// application key. It shares the HTTP client (and its connections).
+func (c *Client) WithToken(token string) *Client {
+	return NewClient(c.address, token, c.httpClient)
+}
+
 // Close closes idle connections
 func (c *Client) Close() {
 	c.httpClient.CloseIdleConnections()
@@ -63,13 +83,65 @@ func (c *Client) Request(ctx context.Context, method, path string, body io.Reade
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("hue-application-key", c.token)
+	req.Header.Set("hue-application-key", c.Token())
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 	return c.httpClient.Do(req)
 }
 
+// Raw performs a request against any CLIP v2 path (relative to /clip/v2/,
+// e.g. "resource/light/<id>") and returns the decoded JSON response. body is
+// sent as JSON when non-nil. Non-2xx responses are returned as errors.
+func (c *Client) Raw(ctx context.Context, method, path string, body any) (any, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), "clip/v2/")
+
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	resp, err := c.Request(ctx, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result any
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Ping performs a lightweight request to check the bridge is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	resp, err := c.Request(ctx, "GET", "resource/bridge", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // GetLight returns a light by ID
 func (c *Client) GetLight(ctx context.Context, lightID string) (*Light, error) {
 	resp, err := c.Request(ctx, "GET", fmt.Sprintf("resource/light/%s", lightID), nil)
@@ -131,6 +203,116 @@ func (c *Client) UpdateLight(ctx context.Context, lightID string, update map[str
 	return nil
 }
 
+// LightIDForV1 resolves a V1 light ID (e.g. 5) to its V2 resource ID
+func (c *Client) LightIDForV1(ctx context.Context, v1ID int) (string, error) {
+	return c.ResolveV1(ctx, "light", fmt.Sprintf("/lights/%d", v1ID))
+}
+
+// GetResources returns all resources of the given type (e.g. "grouped_light")
+func (c *Client) GetResources(ctx context.Context, rtype string) ([]Resource, error) {
+	resp, err := c.Request(ctx, "GET", "resource/"+rtype, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []Resource `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetSensors returns all sensor services of the given type
+// ("temperature", "motion" or "light_level")
+func (c *Client) GetSensors(ctx context.Context, rtype string) ([]Sensor, error) {
+	resp, err := c.Request(ctx, "GET", "resource/"+rtype, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []Sensor `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// GetConnectivity returns all zigbee_connectivity services
+func (c *Client) GetConnectivity(ctx context.Context) ([]Connectivity, error) {
+	resp, err := c.Request(ctx, "GET", "resource/zigbee_connectivity", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data []Connectivity `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return result.Data, nil
+}
+
+// ResolveV1 finds the V2 ID of the rtype resource whose id_v1 equals v1Path
+// (e.g. ResolveV1(ctx, "grouped_light", "/groups/3")).
+func (c *Client) ResolveV1(ctx context.Context, rtype, v1Path string) (string, error) {
+	resources, err := c.GetResources(ctx, rtype)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range resources {
+		if r.IDV1 == v1Path {
+			return r.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("no V2 %s for %s", rtype, v1Path)
+}
+
+// UpdateResource updates a resource of the given type
+func (c *Client) UpdateResource(ctx context.Context, rtype, id string, update map[string]interface{}) error {
+	bodyBytes, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.Request(ctx, "PUT", fmt.Sprintf("resource/%s/%s", rtype, id), strings.NewReader(string(bodyBytes)))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update %s: %s", rtype, string(body))
+	}
+
+	return nil
+}
+
+// UpdateGroupedLight updates a grouped_light (the light state of a room or zone)
+func (c *Client) UpdateGroupedLight(ctx context.Context, groupedLightID string, update map[string]interface{}) error {
+	return c.UpdateResource(ctx, "grouped_light", groupedLightID, update)
+}
+
+// RecallScene activates a scene
+func (c *Client) RecallScene(ctx context.Context, sceneID string) error {
+	return c.UpdateResource(ctx, "scene", sceneID, map[string]interface{}{
+		"recall": map[string]interface{}{"action": "active"},
+	})
+}
+
 // GetScenes returns all scenes
 func (c *Client) GetScenes(ctx context.Context) ([]Scene, error) {
 	resp, err := c.Request(ctx, "GET", "resource/scene", nil)
@@ -7,8 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -20,12 +23,18 @@ import (
 // ErrMaxReconnectsExceeded is returned when the maximum number of reconnect attempts is exceeded.
 var ErrMaxReconnectsExceeded = errors.New("max reconnects exceeded")
 
+// initialEventBufferSize is the scanner's starting buffer; it grows up to MaxEventSize.
+const initialEventBufferSize = 64 * 1024
+
 // EventStreamConfig contains configuration for event stream reconnection.
 type EventStreamConfig struct {
-	MinBackoff    time.Duration // Minimum backoff between reconnects
-	MaxBackoff    time.Duration // Maximum backoff between reconnects
-	Multiplier    float64       // Backoff multiplier
-	MaxReconnects int           // Max reconnect attempts, 0 = infinite
+	MinBackoff    time.Duration   // Minimum backoff between reconnects
+	MaxBackoff    time.Duration   // Maximum backoff between reconnects
+	Multiplier    float64         // Backoff multiplier
+	MaxReconnects int             // Max reconnect attempts, 0 = infinite
+	MaxEventSize  int             // Max bytes per stream line, 0 = bufio.MaxScanTokenSize
+	RecentEvents  int             // Items kept for RecentEvents(), 0 = disabled
+	Types         map[string]bool // Event types published to the bus (button, rotary, connectivity, light_change), nil = all
 }
 
 // EventStream listens to the Hue event stream (SSE) via V2 API.
@@ -35,6 +44,19 @@ type EventStream struct {
 	v2Client   *Client
 	httpClient *http.Client
 	config     EventStreamConfig
+
+	// lastActivity is the unix-nano time of the last line read from the stream
+	lastActivity atomic.Int64
+
+	// retryHint is the reconnect delay last suggested by a "retry:" line.
+	// Only touched from the Run goroutine.
+	retryHint time.Duration
+
+	// recent holds the last received items (nil when disabled)
+	recent *recentEvents
+
+	// stats holds the cumulative counters reported by Stats()
+	stats streamStats
 }
 
 // NewEventStreamWithConfig creates a new event stream listener with custom configuration
@@ -50,9 +72,26 @@ func NewEventStreamWithConfig(v2Client *Client, config EventStreamConfig) *Event
 			// No timeout for SSE - it's a long-lived connection
 		},
 		config: config,
+		recent: newRecentEvents(config.RecentEvents),
 	}
 }
 
+// LastActivity returns when data was last received from the stream,
+// or the zero time if nothing has been received yet.
+func (e *EventStream) LastActivity() time.Time {
+	ns := e.lastActivity.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// RecentEvents returns the last received event items, oldest first.
+// Returns nil if EventStreamConfig.RecentEvents is 0.
+func (e *EventStream) RecentEvents() []RecentEvent {
+	return e.recent.snapshot()
+}
+
 // Run starts listening to the event stream with automatic reconnection.
 // Returns ErrMaxReconnectsExceeded if max reconnects is exceeded.
 func (e *EventStream) Run(ctx context.Context, bus *events.Bus) error {
@@ -73,6 +112,13 @@ func (e *EventStream) Run(ctx context.Context, bus *events.Bus) error {
 			}
 
 			retryCount++
+			e.stats.addReconnect()
+
+			// A retry hint from the bridge replaces the current delay, capped at max
+			if hint := e.takeRetryHint(); hint > 0 {
+				currentBackoff = min(hint, e.config.MaxBackoff)
+				log.Debug().Dur("retry", hint).Msg("Event stream: using bridge-suggested reconnect delay")
+			}
 
 			// Check if we exceeded max reconnects
 			if e.config.MaxReconnects > 0 && retryCount > e.config.MaxReconnects {
@@ -108,9 +154,28 @@ func (e *EventStream) Run(ctx context.Context, bus *events.Bus) error {
 		// Reset retry count and backoff on successful connection
 		retryCount = 0
 		currentBackoff = e.config.MinBackoff
+
+		// The stream ended cleanly; a retry hint still delays the reconnect
+		if hint := e.takeRetryHint(); hint > 0 {
+			delay := min(hint, e.config.MaxBackoff)
+			log.Debug().Dur("retry", delay).Msg("Event stream: using bridge-suggested reconnect delay")
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
 	}
 }
 
+// takeRetryHint returns and clears the last "retry:" delay sent by the bridge.
+func (e *EventStream) takeRetryHint() time.Duration {
+	hint := e.retryHint
+	e.retryHint = 0
+	return hint
+}
+
 func (e *EventStream) connect(ctx context.Context, bus *events.Bus) error {
 	url := fmt.Sprintf("https://%s/eventstream/clip/v2", e.v2Client.Address())
 
@@ -133,12 +198,31 @@ func (e *EventStream) connect(ctx context.Context, bus *events.Bus) error {
 	}
 
 	log.Info().Msg("Connected to Hue event stream")
+	now := time.Now()
+	e.lastActivity.Store(now.UnixNano())
+	e.stats.setConnected(now)
+	defer e.stats.setConnected(time.Time{})
+
+	return e.readEvents(resp.Body, bus)
+}
+
+// readEvents parses the SSE stream from r and publishes events to bus until
+// r is exhausted or a read fails.
+func (e *EventStream) readEvents(r io.Reader, bus *events.Bus) error {
+	maxSize := e.config.MaxEventSize
+	if maxSize <= 0 {
+		maxSize = bufio.MaxScanTokenSize
+	}
 
-	scanner := bufio.NewScanner(resp.Body)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(initialEventBufferSize, maxSize)), maxSize)
 	var dataBuffer strings.Builder
+	hasData := false
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		e.lastActivity.Store(time.Now().UnixNano())
+		e.stats.addBytes(len(line) + 1) // count the stripped newline
 
 		// Handle intro message
 		if line == ": hi" {
@@ -148,20 +232,37 @@ func (e *EventStream) connect(ctx context.Context, bus *events.Bus) error {
 
 		// Empty line marks end of event
 		if line == "" {
-			if dataBuffer.Len() > 0 {
+			if hasData {
 				e.processEvent(dataBuffer.String(), bus)
 				dataBuffer.Reset()
+				hasData = false
 			}
 			continue
 		}
 
-		// Collect data lines
-		if strings.HasPrefix(line, "data: ") {
-			dataBuffer.WriteString(strings.TrimPrefix(line, "data: "))
+		// Reconnect delay suggestion in milliseconds
+		if value, ok := strings.CutPrefix(line, "retry:"); ok {
+			if ms, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && ms > 0 {
+				e.retryHint = time.Duration(ms) * time.Millisecond
+			}
+			continue
+		}
+
+		// Collect data lines; per the SSE spec, one optional space follows the
+		// colon and multiple data lines in an event are joined with newlines
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if hasData {
+				dataBuffer.WriteByte('\n')
+			}
+			dataBuffer.WriteString(strings.TrimPrefix(value, " "))
+			hasData = true
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("event larger than max_event_size (%d bytes): %w", maxSize, err)
+		}
 		return err
 	}
 
@@ -193,21 +294,48 @@ func (e *EventStream) handleEvent(event map[string]interface{}, bus *events.Bus)
 		itemType, _ := itemMap["type"].(string)
 		itemID, _ := itemMap["id"].(string)
 
+		e.stats.addEvent(itemType)
+		e.recent.add(RecentEvent{
+			Time:      time.Now(),
+			EventType: eventType,
+			Type:      itemType,
+			ID:        itemID,
+		})
+
 		switch itemType {
 		case "button":
-			e.handleButtonEvent(itemID, itemMap, bus)
+			if e.publishes(events.EventTypeButton) {
+				e.handleButtonEvent(itemID, itemMap, bus)
+			}
 
 		case "relative_rotary":
-			e.handleRotaryEvent(itemID, itemMap, bus)
+			if e.publishes(events.EventTypeRotary) {
+				e.handleRotaryEvent(itemID, itemMap, bus)
+			}
 
 		case "zigbee_connectivity":
-			e.handleConnectivityEvent(itemID, itemMap, bus)
+			if e.publishes(events.EventTypeConnectivity) {
+				e.handleConnectivityEvent(itemID, itemMap, bus)
+			}
+
+		case "device":
+			bus.Publish(events.Event{
+				Type: events.EventTypeDeviceChange,
+				Data: map[string]interface{}{
+					"resource_id": itemID,
+					"event_type":  eventType,
+				},
+			})
 
 		case string(sse.LightResourceTypeLight):
-			e.handleLightChangeEvent(itemID, itemMap, sse.LightResourceTypeLight, bus)
+			if e.publishes(events.EventTypeLightChange) {
+				e.handleLightChangeEvent(itemID, itemMap, sse.LightResourceTypeLight, bus)
+			}
 
 		case string(sse.LightResourceTypeGroupedLight):
-			e.handleLightChangeEvent(itemID, itemMap, sse.LightResourceTypeGroupedLight, bus)
+			if e.publishes(events.EventTypeLightChange) {
+				e.handleLightChangeEvent(itemID, itemMap, sse.LightResourceTypeGroupedLight, bus)
+			}
 
 		default:
 			log.Trace().
@@ -219,6 +347,12 @@ func (e *EventStream) handleEvent(event map[string]interface{}, bus *events.Bus)
 	}
 }
 
+// publishes reports whether events of the given type are enabled. Device
+// changes are always published since the device index depends on them.
+func (e *EventStream) publishes(t events.EventType) bool {
+	return e.config.Types == nil || e.config.Types[string(t)]
+}
+
 func (e *EventStream) handleButtonEvent(id string, data map[string]interface{}, bus *events.Bus) {
 	button, ok := data["button"].(map[string]interface{})
 	if !ok {
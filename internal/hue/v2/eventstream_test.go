@@ -0,0 +1,170 @@
+package v2
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/events"
+)
+
+// lightEventLine builds an SSE data line for a light update padded to at least size bytes.
+func lightEventLine(id string, size int) string {
+	padding := strings.Repeat("x", size)
+	return fmt.Sprintf(`data: [{"type":"update","data":[{"type":"light","id":%q,"on":{"on":true},"padding":%q}]}]`, id, padding)
+}
+
+// collectLightChanges subscribes to light change events and returns a channel of resource IDs.
+func collectLightChanges(bus *events.Bus) <-chan string {
+	ids := make(chan string, 10)
+	bus.Subscribe(events.EventTypeLightChange, func(event events.Event) {
+		id, _ := event.Data["resource_id"].(string)
+		ids <- id
+	})
+	return ids
+}
+
+func waitForID(t *testing.T, ids <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-ids:
+		if got != want {
+			t.Errorf("resource_id = %q, want %q", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for light change %q", want)
+	}
+}
+
+func TestReadEvents_OversizedLine(t *testing.T) {
+	bus := events.NewBus()
+	ids := collectLightChanges(bus)
+
+	// Larger than bufio.Scanner's default 64KB token limit
+	stream := ": hi\n\n" + lightEventLine("big", 200*1024) + "\n\n"
+
+	e := &EventStream{config: EventStreamConfig{MaxEventSize: 1 << 20}}
+	if err := e.readEvents(strings.NewReader(stream), bus); err != nil {
+		t.Fatalf("readEvents() error = %v", err)
+	}
+
+	waitForID(t, ids, "big")
+}
+
+func TestReadEvents_ExceedsMaxEventSize(t *testing.T) {
+	bus := events.NewBus()
+
+	stream := lightEventLine("too-big", 8*1024) + "\n\n"
+
+	e := &EventStream{config: EventStreamConfig{MaxEventSize: 4 * 1024}}
+	err := e.readEvents(strings.NewReader(stream), bus)
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("readEvents() error = %v, want bufio.ErrTooLong", err)
+	}
+}
+
+func TestReadEvents_MultiLineData(t *testing.T) {
+	bus := events.NewBus()
+	ids := collectLightChanges(bus)
+
+	// Pretty-printed payload split across data lines, mixing "data: " and "data:"
+	stream := strings.Join([]string{
+		`data: [`,
+		`data:   {`,
+		`data:     "type": "update",`,
+		`data:     "data": [{"type": "light", "id": "multi",`,
+		`data:"on": {"on": true}}]`,
+		`data:   }`,
+		`data: ]`,
+		``,
+		``,
+	}, "\n")
+
+	e := &EventStream{}
+	if err := e.readEvents(strings.NewReader(stream), bus); err != nil {
+		t.Fatalf("readEvents() error = %v", err)
+	}
+
+	waitForID(t, ids, "multi")
+}
+
+func TestReadEvents_RecentEventsRing(t *testing.T) {
+	bus := events.NewBus()
+
+	var stream strings.Builder
+	for _, id := range []string{"a", "b", "c"} {
+		stream.WriteString(lightEventLine(id, 0) + "\n\n")
+	}
+
+	e := &EventStream{recent: newRecentEvents(2)}
+	if err := e.readEvents(strings.NewReader(stream.String()), bus); err != nil {
+		t.Fatalf("readEvents() error = %v", err)
+	}
+
+	recent := e.RecentEvents()
+	if len(recent) != 2 || recent[0].ID != "b" || recent[1].ID != "c" {
+		t.Fatalf("RecentEvents() = %+v, want items b, c", recent)
+	}
+	if recent[1].Type != "light" || recent[1].EventType != "update" {
+		t.Errorf("RecentEvents()[1] = %+v, want light update", recent[1])
+	}
+}
+
+func TestRun_RetryHintAfterCleanEOF(t *testing.T) {
+	const hint = 300 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var connects []time.Time
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connects = append(connects, time.Now())
+		n := len(connects)
+		mu.Unlock()
+
+		if n == 1 {
+			// Suggest a reconnect delay, then end the stream cleanly
+			fmt.Fprintf(w, "retry: %d\n\n", hint.Milliseconds())
+			return
+		}
+		cancel()
+	}))
+	defer srv.Close()
+
+	client := NewClient(strings.TrimPrefix(srv.URL, "https://"), "token", nil)
+	e := NewEventStreamWithConfig(client, EventStreamConfig{
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 5 * time.Second,
+		Multiplier: 2,
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- e.Run(ctx, events.NewBus()) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reconnect")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(connects) < 2 {
+		t.Fatalf("connects = %d, want at least 2", len(connects))
+	}
+	if gap := connects[1].Sub(connects[0]); gap < hint {
+		t.Errorf("reconnect after %v, want at least the %v retry hint", gap, hint)
+	}
+}
@@ -13,7 +13,8 @@ type Light struct {
 		Name      string `json:"name"`
 		Archetype string `json:"archetype"`
 	} `json:"metadata"`
-	On *struct {
+	Owner ResourceRef `json:"owner"` // the device exposing the light
+	On    *struct {
 		On bool `json:"on"`
 	} `json:"on,omitempty"`
 	Dimming *struct {
@@ -73,3 +74,66 @@ type ActionData struct {
 	} `json:"color,omitempty"`
 }
 
+// ResourceRef references another V2 resource
+type ResourceRef struct {
+	RID   string `json:"rid"`
+	RType string `json:"rtype"`
+}
+
+// Connectivity represents a zigbee_connectivity service (V2 API): whether the
+// owning device currently responds.
+type Connectivity struct {
+	ID     string      `json:"id"`
+	Owner  ResourceRef `json:"owner"`
+	Status string      `json:"status"` // "connected", "disconnected", ...
+}
+
+// Resource is the common shape of any V2 resource (room, zone, grouped_light, ...).
+// Only identity fields are decoded; use typed models for state.
+type Resource struct {
+	ID       string        `json:"id"`
+	IDV1     string        `json:"id_v1,omitempty"`
+	Type     string        `json:"type"`
+	Services []ResourceRef `json:"services,omitempty"`
+	Children []ResourceRef `json:"children,omitempty"`
+	Metadata *struct {
+		Name string `json:"name"`
+	} `json:"metadata,omitempty"`
+	ProductData *struct {
+		ProductName string `json:"product_name"`
+		ModelID     string `json:"model_id"`
+	} `json:"product_data,omitempty"`
+}
+
+// Sensor represents a sensor service (V2 API): temperature, motion or light_level.
+// Only the block matching Type is set. Reports are absent on older firmware,
+// where the plain value fields carry the reading.
+type Sensor struct {
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	Owner       ResourceRef `json:"owner"`
+	Temperature *struct {
+		Temperature       float64 `json:"temperature"`
+		TemperatureValid  bool    `json:"temperature_valid"`
+		TemperatureReport *struct {
+			Changed     string  `json:"changed"`
+			Temperature float64 `json:"temperature"`
+		} `json:"temperature_report,omitempty"`
+	} `json:"temperature,omitempty"`
+	Motion *struct {
+		Motion       bool `json:"motion"`
+		MotionValid  bool `json:"motion_valid"`
+		MotionReport *struct {
+			Changed string `json:"changed"`
+			Motion  bool   `json:"motion"`
+		} `json:"motion_report,omitempty"`
+	} `json:"motion,omitempty"`
+	Light *struct {
+		LightLevel       int  `json:"light_level"`
+		LightLevelValid  bool `json:"light_level_valid"`
+		LightLevelReport *struct {
+			Changed    string `json:"changed"`
+			LightLevel int    `json:"light_level"`
+		} `json:"light_level_report,omitempty"`
+	} `json:"light,omitempty"`
+}
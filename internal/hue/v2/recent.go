@@ -0,0 +1,60 @@
+package v2
+
+import (
+	"sync"
+	"time"
+)
+
+// RecentEvent is a decoded event stream item kept for troubleshooting.
+type RecentEvent struct {
+	Time      time.Time `json:"time"`       // when the item was received
+	EventType string    `json:"event_type"` // "update", "add", "delete", ...
+	Type      string    `json:"type"`       // resource type, e.g. "button", "light"
+	ID        string    `json:"id"`         // resource ID
+}
+
+// recentEvents is a fixed-size ring buffer of the last received items.
+// A nil *recentEvents records nothing.
+type recentEvents struct {
+	mu   sync.Mutex
+	buf  []RecentEvent
+	next int
+	full bool
+}
+
+func newRecentEvents(size int) *recentEvents {
+	if size <= 0 {
+		return nil
+	}
+	return &recentEvents{buf: make([]RecentEvent, size)}
+}
+
+func (r *recentEvents) add(ev RecentEvent) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf[r.next] = ev
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// snapshot returns the buffered items, oldest first.
+func (r *recentEvents) snapshot() []RecentEvent {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]RecentEvent(nil), r.buf[:r.next]...)
+	}
+	result := make([]RecentEvent, 0, len(r.buf))
+	result = append(result, r.buf[r.next:]...)
+	return append(result, r.buf[:r.next]...)
+}
@@ -0,0 +1,94 @@
+package v2
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// StreamStats are cumulative event stream counters. They only grow, across
+// restarts too when persisted and passed back through RestoreStats.
+type StreamStats struct {
+	EventsByType  map[string]int64 `json:"events_by_type"` // data items by resource type
+	BytesReceived int64            `json:"bytes_received"`
+	Reconnects    int64            `json:"reconnects"`
+}
+
+// streamStats guards the counters and the current connection start.
+// The zero value is ready to use.
+type streamStats struct {
+	mu          sync.Mutex
+	totals      StreamStats
+	connectedAt time.Time // zero while disconnected
+}
+
+func (s *streamStats) addEvent(itemType string) {
+	s.mu.Lock()
+	if s.totals.EventsByType == nil {
+		s.totals.EventsByType = make(map[string]int64)
+	}
+	s.totals.EventsByType[itemType]++
+	s.mu.Unlock()
+}
+
+func (s *streamStats) addBytes(n int) {
+	s.mu.Lock()
+	s.totals.BytesReceived += int64(n)
+	s.mu.Unlock()
+}
+
+func (s *streamStats) addReconnect() {
+	s.mu.Lock()
+	s.totals.Reconnects++
+	s.mu.Unlock()
+}
+
+func (s *streamStats) setConnected(at time.Time) {
+	s.mu.Lock()
+	s.connectedAt = at
+	s.mu.Unlock()
+}
+
+// Stats returns a copy of the cumulative event stream counters.
+func (e *EventStream) Stats() StreamStats {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+
+	stats := e.stats.totals
+	stats.EventsByType = maps.Clone(e.stats.totals.EventsByType)
+	if stats.EventsByType == nil {
+		stats.EventsByType = map[string]int64{}
+	}
+	return stats
+}
+
+// ConnectedSince returns when the current connection was established,
+// or the zero time if the stream is not connected.
+func (e *EventStream) ConnectedSince() time.Time {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+	return e.stats.connectedAt
+}
+
+// RestoreStats adds previously persisted totals to the counters, so totals
+// keep growing from where the last run stopped.
+func (e *EventStream) RestoreStats(saved StreamStats) {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+
+	if e.stats.totals.EventsByType == nil {
+		e.stats.totals.EventsByType = make(map[string]int64)
+	}
+	for itemType, n := range saved.EventsByType {
+		e.stats.totals.EventsByType[itemType] += n
+	}
+	e.stats.totals.BytesReceived += saved.BytesReceived
+	e.stats.totals.Reconnects += saved.Reconnects
+}
+
+// ResetStats zeroes the cumulative counters.
+func (e *EventStream) ResetStats() {
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+	e.stats.totals = StreamStats{}
+}
@@ -0,0 +1,70 @@
+package hue
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/zone"
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// FetchZoneTopologyV2 loads zones and rooms through the V2 API. Zone children
+// are lights; room children are devices, which are resolved to their light
+// services. Groups the bridge reports without a V1 ID are skipped.
+func FetchZoneTopologyV2(ctx context.Context, client *v2.Client) (*zone.Topology, error) {
+	devices, err := client.GetResources(ctx, "device")
+	if err != nil {
+		return nil, err
+	}
+	deviceLights := make(map[string][]string, len(devices))
+	for _, d := range devices {
+		for _, svc := range d.Services {
+			if svc.RType == "light" {
+				deviceLights[d.ID] = append(deviceLights[d.ID], svc.RID)
+			}
+		}
+	}
+
+	zones, err := client.GetResources(ctx, "zone")
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := client.GetResources(ctx, "room")
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &zone.Topology{Zones: make(map[string]zone.Group, len(zones))}
+	for _, r := range zones {
+		id, ok := strings.CutPrefix(r.IDV1, "/groups/")
+		if !ok || r.Metadata == nil {
+			continue
+		}
+		g := zone.Group{ID: id}
+		for _, child := range r.Children {
+			switch child.RType {
+			case "light":
+				g.Lights = append(g.Lights, child.RID)
+			case "device":
+				g.Lights = append(g.Lights, deviceLights[child.RID]...)
+			}
+		}
+		topology.Zones[strings.ToLower(r.Metadata.Name)] = g
+	}
+
+	for _, r := range rooms {
+		id, ok := strings.CutPrefix(r.IDV1, "/groups/")
+		if !ok {
+			continue
+		}
+		g := zone.Group{ID: id}
+		for _, child := range r.Children {
+			if child.RType == "device" {
+				g.Lights = append(g.Lights, deviceLights[child.RID]...)
+			}
+		}
+		topology.Rooms = append(topology.Rooms, g)
+	}
+
+	return topology, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
 
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
 )
 
@@ -47,6 +48,9 @@ func (m *ActualModule) Install(L *lua.LState, ctx *lua.LTable) {
 }
 
 // group is a Lua function that fetches fresh group state from the bridge.
+// The state is read at call time, so a decision based on it reflects the
+// bridge when the action runs; the desired state written from that decision
+// is applied later by the reconciler without re-reading the condition.
 // Uses L.Context() for cancellation support.
 func (m *ActualModule) group(L *lua.LState) int {
 	L.CheckTable(1) // self
@@ -67,6 +71,8 @@ func (m *ActualModule) group(L *lua.LState) int {
 	tbl := L.NewTable()
 	L.SetField(tbl, "all_on", lua.LBool(state.AllOn))
 	L.SetField(tbl, "any_on", lua.LBool(state.AnyOn))
+	L.SetField(tbl, "bri", lua.LNumber(state.Bri))
+	L.SetField(tbl, "bri_pct", lua.LNumber(reconcile.BriToPercent(state.Bri)))
 	L.Push(tbl)
 	L.Push(lua.LNil) // no error
 	return 2
@@ -1,40 +1,68 @@
 package context
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
 
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/zone"
 	"github.com/dokzlo13/lightd/internal/storage"
 )
 
+// IDResolver maps a light or group reference (alias or numeric ID) to its ID.
+// kind is "light" or "group".
+type IDResolver func(kind, ref string) (string, error)
+
 // DesiredModule provides ctx.desired for accessing/modifying desired state.
 //
 // Chainable builder API:
 //
 //	ctx.desired:group("1"):on():set_scene("Relax")
 //	ctx.desired:light("5"):on():set_bri(254)
+//	ctx.desired:zone("Downstairs"):off()
 //	ctx:reconcile()  -- flushes pending and triggers reconciler
+//	ctx.desired:rollback()  -- discards pending changes instead
+//
+// Flushes are transactional: all pending group, light and zone changes from
+// an action are committed together or not at all.
 type DesiredModule struct {
 	groupStore *storage.TypedStore[group.Desired]
 	lightStore *storage.TypedStore[light.Desired]
+	zoneStore  *storage.TypedStore[zone.Desired]
+
+	// resolveID resolves hue aliases for group and light builders (nil = IDs as given)
+	resolveID IDResolver
+
+	// source is the invocation source of the running action
+	source string
 
 	// Pending builders (keyed by ID)
 	pendingGroups map[string]*GroupDesiredBuilder
 	pendingLights map[string]*LightDesiredBuilder
+	pendingZones  map[string]*GroupDesiredBuilder
 }
 
 // NewDesiredModule creates a new desired state module.
 func NewDesiredModule(
 	groupStore *storage.TypedStore[group.Desired],
 	lightStore *storage.TypedStore[light.Desired],
+	zoneStore *storage.TypedStore[zone.Desired],
+	resolveID IDResolver,
 ) *DesiredModule {
 	return &DesiredModule{
 		groupStore:    groupStore,
 		lightStore:    lightStore,
+		zoneStore:     zoneStore,
+		resolveID:     resolveID,
 		pendingGroups: make(map[string]*GroupDesiredBuilder),
 		pendingLights: make(map[string]*LightDesiredBuilder),
+		pendingZones:  make(map[string]*GroupDesiredBuilder),
 	}
 }
 
@@ -45,6 +73,11 @@ func (m *DesiredModule) Name() string {
 
 // Install adds ctx.desired to the context table.
 func (m *DesiredModule) Install(L *lua.LState, ctx *lua.LTable) {
+	m.source = ""
+	if goCtx := L.Context(); goCtx != nil {
+		m.source = actions.SourceFromContext(goCtx)
+	}
+
 	// Register builder metatables
 	RegisterGroupBuilderType(L)
 	RegisterLightBuilderType(L)
@@ -54,12 +87,26 @@ func (m *DesiredModule) Install(L *lua.LState, ctx *lua.LTable) {
 	// Chainable builder factories
 	L.SetField(desired, "group", L.NewFunction(m.getGroupBuilder()))
 	L.SetField(desired, "light", L.NewFunction(m.getLightBuilder()))
+	L.SetField(desired, "zone", L.NewFunction(m.getZoneBuilder()))
+
+	// Discard pending changes without flushing
+	L.SetField(desired, "rollback", L.NewFunction(m.rollback))
 
 	L.SetField(ctx, m.Name(), desired)
 }
 
-// markGroupPending marks a group builder as having pending changes.
+// scheduled reports whether the running action was invoked by a schedule,
+// whose power-offs may be held back by the reconciler's off delay.
+func (m *DesiredModule) scheduled() bool {
+	return m.source == "scheduler" || m.source == "boot_recovery"
+}
+
+// markGroupPending marks a group or zone builder as having pending changes.
 func (m *DesiredModule) markGroupPending(builder *GroupDesiredBuilder) {
+	if builder.zone {
+		m.pendingZones[builder.groupID] = builder
+		return
+	}
 	m.pendingGroups[builder.groupID] = builder
 }
 
@@ -68,83 +115,121 @@ func (m *DesiredModule) markLightPending(builder *LightDesiredBuilder) {
 	m.pendingLights[builder.lightID] = builder
 }
 
+// PendingKeys returns the resources with pending (unflushed) changes.
+func (m *DesiredModule) PendingKeys() []reconcile.ResourceKey {
+	keys := make([]reconcile.ResourceKey, 0, len(m.pendingGroups)+len(m.pendingLights)+len(m.pendingZones))
+	for id := range m.pendingGroups {
+		keys = append(keys, reconcile.ResourceKey{Kind: reconcile.KindGroup, ID: id})
+	}
+	for id := range m.pendingLights {
+		keys = append(keys, reconcile.ResourceKey{Kind: reconcile.KindLight, ID: id})
+	}
+	for id := range m.pendingZones {
+		keys = append(keys, reconcile.ResourceKey{Kind: reconcile.KindZone, ID: id})
+	}
+	return keys
+}
+
 // Flush writes all pending builder states to stores and clears pending.
+// All pending groups, lights and zones are written in a single transaction:
+// either every resource commits or none does. Pending state is cleared either way.
 func (m *DesiredModule) Flush() error {
-	if len(m.pendingGroups) == 0 && len(m.pendingLights) == 0 {
+	if len(m.pendingGroups) == 0 && len(m.pendingLights) == 0 && len(m.pendingZones) == 0 {
 		return nil
 	}
 
 	log.Debug().
 		Int("groups", len(m.pendingGroups)).
 		Int("lights", len(m.pendingLights)).
+		Int("zones", len(m.pendingZones)).
 		Msg("Flushing desired state")
 
-	// Flush pending groups
-	for id, b := range m.pendingGroups {
-		err := m.groupStore.Update(id, func(current group.Desired) group.Desired {
-			// Merge builder state into current state
-			if b.state.Power != nil {
-				current.Power = b.state.Power
-			}
-			if b.state.SceneName != "" {
-				current.SceneName = b.state.SceneName
-			}
-			if b.state.Bri != nil {
-				current.Bri = b.state.Bri
-			}
-			if b.state.Hue != nil {
-				current.Hue = b.state.Hue
-			}
-			if b.state.Sat != nil {
-				current.Sat = b.state.Sat
-			}
-			if b.state.Xy != nil {
-				current.Xy = b.state.Xy
-			}
-			if b.state.Ct != nil {
-				current.Ct = b.state.Ct
+	err := m.groupStore.Base().WithTx(func(tx *storage.Store) error {
+		groups := m.groupStore.Bind(tx)
+		lights := m.lightStore.Bind(tx)
+		zones := m.zoneStore.Bind(tx)
+
+		// Flush pending groups
+		for id, b := range m.pendingGroups {
+			if err := groups.Update(id, b.merge); err != nil {
+				return fmt.Errorf("group %s: %w", id, err)
 			}
-			return current
-		})
-		if err != nil {
-			log.Error().Err(err).Str("group", id).Msg("Failed to flush group desired state")
 		}
-	}
 
-	// Flush pending lights
-	for id, b := range m.pendingLights {
-		err := m.lightStore.Update(id, func(current light.Desired) light.Desired {
-			// Merge builder state into current state
-			if b.state.Power != nil {
-				current.Power = b.state.Power
-			}
-			if b.state.Bri != nil {
-				current.Bri = b.state.Bri
-			}
-			if b.state.Hue != nil {
-				current.Hue = b.state.Hue
+		// Flush pending zones
+		for id, b := range m.pendingZones {
+			if err := zones.Update(id, b.merge); err != nil {
+				return fmt.Errorf("zone %s: %w", id, err)
 			}
-			if b.state.Sat != nil {
-				current.Sat = b.state.Sat
-			}
-			if b.state.Xy != nil {
-				current.Xy = b.state.Xy
-			}
-			if b.state.Ct != nil {
-				current.Ct = b.state.Ct
+		}
+
+		// Flush pending lights
+		for id, b := range m.pendingLights {
+			err := lights.Update(id, func(current light.Desired) light.Desired {
+				// Merge builder state into current state
+				if b.state.Power != nil {
+					current.Power = b.state.Power
+				}
+				if b.state.Bri != nil {
+					current.Bri = b.state.Bri
+				}
+				if b.state.Hue != nil {
+					current.Hue = b.state.Hue
+				}
+				if b.state.Sat != nil {
+					current.Sat = b.state.Sat
+				}
+				if b.state.Xy != nil {
+					current.Xy = b.state.Xy
+				}
+				if b.state.Ct != nil {
+					current.Ct = b.state.Ct
+				}
+				return current
+			})
+			if err != nil {
+				return fmt.Errorf("light %s: %w", id, err)
 			}
-			return current
-		})
-		if err != nil {
-			log.Error().Err(err).Str("light", id).Msg("Failed to flush light desired state")
 		}
+
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to flush desired state, rolled back")
 	}
 
 	// Clear pending
 	m.pendingGroups = make(map[string]*GroupDesiredBuilder)
 	m.pendingLights = make(map[string]*LightDesiredBuilder)
+	m.pendingZones = make(map[string]*GroupDesiredBuilder)
 
-	return nil
+	return err
+}
+
+// Rollback discards all pending builder states without writing them.
+// Returns the number of discarded resources.
+func (m *DesiredModule) Rollback() int {
+	discarded := len(m.pendingGroups) + len(m.pendingLights) + len(m.pendingZones)
+	if discarded > 0 {
+		log.Debug().
+			Int("groups", len(m.pendingGroups)).
+			Int("lights", len(m.pendingLights)).
+			Int("zones", len(m.pendingZones)).
+			Msg("Rolling back desired state")
+	}
+
+	m.pendingGroups = make(map[string]*GroupDesiredBuilder)
+	m.pendingLights = make(map[string]*LightDesiredBuilder)
+	m.pendingZones = make(map[string]*GroupDesiredBuilder)
+	return discarded
+}
+
+// rollback is the Lua binding for Rollback.
+// ctx.desired:rollback() -> number of discarded resources
+func (m *DesiredModule) rollback(L *lua.LState) int {
+	L.CheckTable(1) // self
+	L.Push(lua.LNumber(m.Rollback()))
+	return 1
 }
 
 // Cleanup implements CleanupModule interface.
@@ -153,22 +238,49 @@ func (m *DesiredModule) Cleanup() {
 	m.Flush()
 }
 
+// resolve maps ref to a light or group ID, raising a Lua error for an
+// unknown alias.
+func (m *DesiredModule) resolve(L *lua.LState, kind, ref string) string {
+	if m.resolveID == nil {
+		return ref
+	}
+	id, err := m.resolveID(kind, ref)
+	if err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	return id
+}
+
 // getGroupBuilder returns a Lua function that creates a group builder.
+// The group may be given by alias or ID, as with hue.group().
 func (m *DesiredModule) getGroupBuilder() lua.LGFunction {
 	return func(L *lua.LState) int {
 		L.CheckTable(1) // self
-		groupID := L.CheckString(2)
+		groupID := m.resolve(L, "group", L.CheckString(2))
+
+		pushGroupBuilder(L, groupID, false, m)
+		return 1
+	}
+}
+
+// getZoneBuilder returns a Lua function that creates a zone builder.
+// Zones share the group builder methods; the name is matched case-insensitively.
+func (m *DesiredModule) getZoneBuilder() lua.LGFunction {
+	return func(L *lua.LState) int {
+		L.CheckTable(1) // self
+		name := L.CheckString(2)
 
-		pushGroupBuilder(L, groupID, m)
+		pushGroupBuilder(L, strings.ToLower(name), true, m)
 		return 1
 	}
 }
 
 // getLightBuilder returns a Lua function that creates a light builder.
+// The light may be given by alias or ID, as with hue.light().
 func (m *DesiredModule) getLightBuilder() lua.LGFunction {
 	return func(L *lua.LState) int {
 		L.CheckTable(1) // self
-		lightID := L.CheckString(2)
+		lightID := m.resolve(L, "light", L.CheckString(2))
 
 		pushLightBuilder(L, lightID, m)
 		return 1
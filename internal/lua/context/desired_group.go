@@ -3,15 +3,18 @@ package context
 import (
 	lua "github.com/yuin/gopher-lua"
 
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
 )
 
 const groupBuilderTypeName = "desired.group"
 
 // GroupDesiredBuilder accumulates desired state changes for a group.
-// Used by ctx.desired:group(id) to provide chainable methods.
+// Used by ctx.desired:group(id) and ctx.desired:zone(name) to provide
+// chainable methods.
 type GroupDesiredBuilder struct {
 	groupID string
+	zone    bool // groupID is a zone name, stored under the zone kind
 	state   group.Desired
 	module  *DesiredModule // reference back for pending tracking
 }
@@ -23,22 +26,24 @@ func RegisterGroupBuilderType(L *lua.LState) {
 }
 
 var groupBuilderMethods = map[string]lua.LGFunction{
-	"on":        groupBuilderOn,
-	"off":       groupBuilderOff,
-	"toggle":    groupBuilderToggle,
-	"set_bri":   groupBuilderSetBri,
-	"set_scene": groupBuilderSetScene,
-	"set_color": groupBuilderSetColorXY,
-	"set_ct":    groupBuilderSetCt,
-	"set_hue":   groupBuilderSetHue,
-	"set_sat":   groupBuilderSetSat,
+	"on":          groupBuilderOn,
+	"off":         groupBuilderOff,
+	"toggle":      groupBuilderToggle,
+	"set_bri":     groupBuilderSetBri,
+	"set_bri_pct": groupBuilderSetBriPct,
+	"set_scene":   groupBuilderSetScene,
+	"set_color":   groupBuilderSetColorXY,
+	"set_ct":      groupBuilderSetCt,
+	"set_hue":     groupBuilderSetHue,
+	"set_sat":     groupBuilderSetSat,
 }
 
 // pushGroupBuilder creates a new GroupDesiredBuilder userdata and pushes it onto the stack.
-func pushGroupBuilder(L *lua.LState, groupID string, module *DesiredModule) {
+func pushGroupBuilder(L *lua.LState, groupID string, zone bool, module *DesiredModule) {
 	ud := L.NewUserData()
 	ud.Value = &GroupDesiredBuilder{
 		groupID: groupID,
+		zone:    zone,
 		state:   group.Desired{},
 		module:  module,
 	}
@@ -46,6 +51,33 @@ func pushGroupBuilder(L *lua.LState, groupID string, module *DesiredModule) {
 	L.Push(ud)
 }
 
+// merge applies the builder's pending fields on top of current.
+func (b *GroupDesiredBuilder) merge(current group.Desired) group.Desired {
+	if b.state.Power != nil {
+		current.Power = b.state.Power
+		current.DelayOff = !*b.state.Power && b.module.scheduled()
+	}
+	if b.state.SceneName != "" {
+		current.SceneName = b.state.SceneName
+	}
+	if b.state.Bri != nil {
+		current.Bri = b.state.Bri
+	}
+	if b.state.Hue != nil {
+		current.Hue = b.state.Hue
+	}
+	if b.state.Sat != nil {
+		current.Sat = b.state.Sat
+	}
+	if b.state.Xy != nil {
+		current.Xy = b.state.Xy
+	}
+	if b.state.Ct != nil {
+		current.Ct = b.state.Ct
+	}
+	return current
+}
+
 // checkGroupBuilder retrieves the GroupDesiredBuilder from the Lua stack.
 func checkGroupBuilder(L *lua.LState) (*GroupDesiredBuilder, *lua.LUserData) {
 	ud := L.CheckUserData(1)
@@ -116,6 +148,16 @@ func groupBuilderSetBri(L *lua.LState) int {
 	return 1
 }
 
+// groupBuilderSetBriPct sets brightness as a percentage (0-100) (chainable).
+func groupBuilderSetBriPct(L *lua.LState) int {
+	builder, ud := checkGroupBuilder(L)
+	bri := reconcile.PercentToBri(float64(L.CheckNumber(2)))
+	builder.state.Bri = &bri
+	builder.module.markGroupPending(builder)
+	L.Push(ud)
+	return 1
+}
+
 // groupBuilderSetScene sets the scene name (chainable).
 func groupBuilderSetScene(L *lua.LState) int {
 	builder, ud := checkGroupBuilder(L)
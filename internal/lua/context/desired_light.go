@@ -3,6 +3,7 @@ package context
 import (
 	lua "github.com/yuin/gopher-lua"
 
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile/light"
 )
 
@@ -23,14 +24,15 @@ func RegisterLightBuilderType(L *lua.LState) {
 }
 
 var lightBuilderMethods = map[string]lua.LGFunction{
-	"on":        lightBuilderOn,
-	"off":       lightBuilderOff,
-	"toggle":    lightBuilderToggle,
-	"set_bri":   lightBuilderSetBri,
-	"set_color": lightBuilderSetColorXY,
-	"set_ct":    lightBuilderSetCt,
-	"set_hue":   lightBuilderSetHue,
-	"set_sat":   lightBuilderSetSat,
+	"on":          lightBuilderOn,
+	"off":         lightBuilderOff,
+	"toggle":      lightBuilderToggle,
+	"set_bri":     lightBuilderSetBri,
+	"set_bri_pct": lightBuilderSetBriPct,
+	"set_color":   lightBuilderSetColorXY,
+	"set_ct":      lightBuilderSetCt,
+	"set_hue":     lightBuilderSetHue,
+	"set_sat":     lightBuilderSetSat,
 }
 
 // pushLightBuilder creates a new LightDesiredBuilder userdata and pushes it onto the stack.
@@ -111,6 +113,16 @@ func lightBuilderSetBri(L *lua.LState) int {
 	return 1
 }
 
+// lightBuilderSetBriPct sets brightness as a percentage (0-100) (chainable).
+func lightBuilderSetBriPct(L *lua.LState) int {
+	builder, ud := checkLightBuilder(L)
+	bri := reconcile.PercentToBri(float64(L.CheckNumber(2)))
+	builder.state.Bri = &bri
+	builder.module.markLightPending(builder)
+	L.Push(ud)
+	return 1
+}
+
 // lightBuilderSetColorXY sets CIE xy color coordinates (chainable).
 func lightBuilderSetColorXY(L *lua.LState) int {
 	builder, ud := checkLightBuilder(L)
@@ -2,13 +2,20 @@ package context
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	lua "github.com/yuin/gopher-lua"
 
+	"github.com/dokzlo13/lightd/internal/events"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
-// ReconcilerModule provides ctx:reconcile() and ctx:force_reconcile() for triggering reconciliation.
+// defaultReconcileSyncTimeout bounds ctx:reconcile_sync() when no timeout is given
+const defaultReconcileSyncTimeout = 10 * time.Second
+
+// ReconcilerModule provides ctx:reconcile(), ctx:reconcile_sync() and
+// ctx:force_reconcile() for triggering reconciliation.
 //
 // This is installed at the root level (not nested), so it's called as ctx:reconcile().
 // Before triggering, it flushes any pending desired state changes from builders.
@@ -18,6 +25,7 @@ import (
 //	ctx.desired:group("1"):on():set_scene("Relax")
 //	ctx.desired:light("5"):set_bri(254)
 //	ctx:reconcile() -- flushes pending and triggers the orchestrator (dirty resources only)
+//	ctx:reconcile_sync("5s") -- flushes pending and applies those resources before returning
 //	ctx:force_reconcile() -- forces reconciliation of ALL resources with desired state
 type ReconcilerModule struct {
 	orchestrator  *reconcile.Orchestrator
@@ -37,10 +45,12 @@ func (m *ReconcilerModule) Name() string {
 	return ""
 }
 
-// Install adds ctx:reconcile() and ctx:force_reconcile() to the context table.
+// Install adds ctx:reconcile(), ctx:reconcile_sync() and ctx:force_reconcile() to the context table.
 func (m *ReconcilerModule) Install(L *lua.LState, ctx *lua.LTable) {
 	// reconcile() - method syntax, arg 1 is self (ctx table itself)
 	L.SetField(ctx, "reconcile", L.NewFunction(m.reconcile()))
+	// reconcile_sync(timeout) - applies the touched resources before returning
+	L.SetField(ctx, "reconcile_sync", L.NewFunction(m.reconcileSync()))
 	// force_reconcile() - forces reconciliation of ALL resources
 	L.SetField(ctx, "force_reconcile", L.NewFunction(m.forceReconcile()))
 }
@@ -51,18 +61,75 @@ func (m *ReconcilerModule) reconcile() lua.LGFunction {
 		// L.CheckTable(1) // self - optional, ctx:reconcile() passes ctx as self
 
 		// Flush all pending desired state changes from builders
+		var keys []reconcile.ResourceKey
 		if m.desiredModule != nil {
+			keys = m.desiredModule.PendingKeys()
 			m.desiredModule.Flush()
 		}
 
 		// Trigger orchestrator (MarkApplied is called after reconciliation completes)
 		if m.orchestrator != nil {
+			if ctx := L.Context(); ctx != nil {
+				m.orchestrator.Trace(keys, events.TraceIDFromContext(ctx))
+			}
 			m.orchestrator.Trigger()
 		}
 		return 0
 	}
 }
 
+// reconcileSync returns a Lua function that flushes pending changes and
+// reconciles exactly those resources synchronously.
+// ctx:reconcile_sync(timeout?) -> (ok, err); timeout is a duration string (default "10s").
+func (m *ReconcilerModule) reconcileSync() lua.LGFunction {
+	return func(L *lua.LState) int {
+		timeout := defaultReconcileSyncTimeout
+		if s := L.OptString(2, ""); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				L.ArgError(2, fmt.Sprintf("invalid timeout %q (expected a positive duration like \"5s\")", s))
+				return 0
+			}
+			timeout = d
+		}
+
+		if m.desiredModule == nil || m.orchestrator == nil {
+			L.Push(lua.LFalse)
+			L.Push(lua.LString("reconciler not available"))
+			return 2
+		}
+
+		keys := m.desiredModule.PendingKeys()
+		if err := m.desiredModule.Flush(); err != nil {
+			L.Push(lua.LFalse)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+		if len(keys) == 0 {
+			L.Push(lua.LTrue)
+			L.Push(lua.LNil)
+			return 2
+		}
+
+		parent := L.Context()
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+
+		if err := m.orchestrator.ReconcileNow(ctx, keys); err != nil {
+			L.Push(lua.LFalse)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+
+		L.Push(lua.LTrue)
+		L.Push(lua.LNil)
+		return 2
+	}
+}
+
 // forceReconcile returns a Lua function that forces reconciliation of ALL resources.
 // This clears caches and re-applies desired state to all resources.
 func (m *ReconcilerModule) forceReconcile() lua.LGFunction {
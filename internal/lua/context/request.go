@@ -30,7 +30,8 @@ type RequestData struct {
 //   - headers: Table of request headers
 //   - path_params: Table of path parameters (e.g., {id = "123"} for "/group/{id}")
 //
-// For non-webhook actions, ctx.request is nil.
+// For non-webhook actions (manual, scheduled, etc.), ctx.request is nil.
+// What invoked the action is exposed separately as ctx.source.
 //
 // Example Lua usage:
 //
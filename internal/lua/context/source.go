@@ -0,0 +1,37 @@
+package context
+
+import (
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+)
+
+// SourceModule provides ctx.source, naming what invoked the action:
+// "scheduler", "boot_recovery", "run_closest", "mode", etc., or "" for
+// webhooks, buttons and manual runs.
+//
+// Example Lua usage:
+//
+//	if ctx.source == "boot_recovery" then
+//	    return -- skip notifications on replay
+//	end
+type SourceModule struct{}
+
+// NewSourceModule creates a new source module.
+func NewSourceModule() *SourceModule {
+	return &SourceModule{}
+}
+
+// Name returns "source" - the field name in ctx.
+func (m *SourceModule) Name() string {
+	return "source"
+}
+
+// Install sets ctx.source from the invocation source in L.Context().
+func (m *SourceModule) Install(L *lua.LState, ctx *lua.LTable) {
+	source := ""
+	if goCtx := L.Context(); goCtx != nil {
+		source = actions.SourceFromContext(goCtx)
+	}
+	L.SetField(ctx, m.Name(), lua.LString(source))
+}
@@ -1,6 +1,8 @@
 package lua
 
 import (
+	"context"
+
 	"github.com/amimof/huego"
 
 	"github.com/dokzlo13/lightd/internal/actions"
@@ -8,7 +10,10 @@ import (
 	"github.com/dokzlo13/lightd/internal/geo"
 	"github.com/dokzlo13/lightd/internal/hue"
 	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile/group"
+	"github.com/dokzlo13/lightd/internal/mode"
 	"github.com/dokzlo13/lightd/internal/scheduler"
+	"github.com/dokzlo13/lightd/internal/storage"
 	"github.com/dokzlo13/lightd/internal/storage/kv"
 )
 
@@ -19,10 +24,26 @@ type RuntimeDeps struct {
 	Registry     *actions.Registry
 	Invoker      *actions.Invoker
 	Scheduler    *scheduler.Scheduler
-	Bridge       *huego.Bridge
+	Bridge       func() *huego.Bridge   // current V1 bridge (replaced on key rotation)
+	V2Client     *hue.ResolvingV2Client // resolves V1 IDs through Devices
 	SceneIndex   *hue.SceneIndex
+	Devices      *hue.DeviceIndex
 	Stores       *hue.StoreRegistry
 	Orchestrator *reconcile.Orchestrator
+	GroupActual  *group.ActualProvider // shared with the reconciler for the stabilization window
 	GeoCalc      *geo.Calculator
 	KVManager    *kv.Manager
+	Ledger       *storage.Ledger
+	Modes        *mode.Manager
+	RotateKey    func(ctx context.Context) error // rotates the Hue application key; nil if unsupported
+	RefreshCache func(ctx context.Context) error // reloads scene and device caches; nil if unsupported
+	ClientKey    func() string                   // returns the entertainment client key; nil if unsupported
+
+	// HoldBri writes a resource's current brightness back as desired,
+	// stopping reconciler brightness ramps; nil if unsupported
+	HoldBri func(ctx context.Context, kind reconcile.Kind, id string) error
+
+	// Dashboard returns mode, schedule, group and event stream state from
+	// caches (system.dashboard_state); nil if unsupported
+	Dashboard func() map[string]any
 }
@@ -46,6 +46,51 @@ func CallReducer(L *glua.LState, reducer *glua.LFunction, events []map[string]an
 	return make(map[string]any)
 }
 
+// CallFilter calls a Lua event filter with the event data and reports whether
+// the event should be dispatched. Only an explicit false consumes the event;
+// errors let it through.
+// MUST be called from within an Executor.Do() callback to ensure thread safety.
+func CallFilter(L *glua.LState, filter *glua.LFunction, data map[string]any) bool {
+	L.Push(filter)
+	L.Push(mapToLuaTable(L, data))
+
+	if err := L.PCall(1, 1, nil); err != nil {
+		log.Error().Err(err).Msg("Lua event filter failed")
+		return true
+	}
+
+	result := L.Get(-1)
+	L.Pop(1)
+
+	return result != glua.LFalse
+}
+
+// CallResponder calls a Lua webhook responder with the request table. It
+// returns the first result converted to Go (nil, a string, or a table as a
+// map or slice) and the second as the status code (0 if not a number).
+// MUST be called from within an Executor.Do() callback to ensure thread safety.
+func CallResponder(L *glua.LState, fn *glua.LFunction, request map[string]any) (any, int, error) {
+	L.Push(fn)
+	L.Push(mapToLuaTable(L, request))
+
+	if err := L.PCall(1, 2, nil); err != nil {
+		return nil, 0, err
+	}
+
+	body := L.Get(-2)
+	status := L.Get(-1)
+	L.Pop(2)
+
+	code := 0
+	if n, ok := status.(glua.LNumber); ok {
+		code = int(n)
+	}
+	if body == glua.LNil {
+		return nil, code, nil
+	}
+	return luaToGo(body), code, nil
+}
+
 // mapToLuaTable converts a Go map to a Lua table
 func mapToLuaTable(L *glua.LState, m map[string]any) *glua.LTable {
 	tbl := L.NewTable()
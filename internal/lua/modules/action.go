@@ -2,8 +2,8 @@ package modules
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/amimof/huego"
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
 
@@ -36,7 +36,12 @@ func (a *actionContext) createContextTable() *lua.LTable {
 	return a.contextBuilder.Build(a.L)
 }
 
-// ActionModule provides action.define() to Lua
+// ActionModule provides action.define(), action.run() and action.run_or_raise() to Lua
+//
+// ERROR HANDLING CONVENTION:
+//   - define(): Uses L.RaiseError() for registration failures
+//   - run(): Returns (ok, error_string)
+//   - run_or_raise(): Uses L.RaiseError() on failure
 type ActionModule struct {
 	registry       *actions.Registry
 	contextBuilder *luactx.Builder
@@ -46,22 +51,21 @@ type ActionModule struct {
 // It registers all context modules that will be available to Lua actions.
 func NewActionModule(
 	registry *actions.Registry,
-	bridge *huego.Bridge,
+	actualProvider *group.ActualProvider,
 	storeRegistry *hue.StoreRegistry,
 	orchestrator *reconcile.Orchestrator,
+	resolveID luactx.IDResolver,
 ) *ActionModule {
-	// Create the GroupActualProvider for actual state access
-	actualProvider := group.NewActualProvider(bridge)
-
 	// Create the desired module (shared between context and reconciler for flush)
-	desiredModule := luactx.NewDesiredModule(storeRegistry.Groups(), storeRegistry.Lights())
+	desiredModule := luactx.NewDesiredModule(storeRegistry.Groups(), storeRegistry.Lights(), storeRegistry.Zones(), resolveID)
 
 	// Build the context builder with all modules
 	builder := luactx.NewBuilder().
 		Register(luactx.NewActualModule(actualProvider)).
 		Register(desiredModule).
 		Register(luactx.NewReconcilerModule(orchestrator, desiredModule)).
-		Register(luactx.NewRequestModule())
+		Register(luactx.NewRequestModule()).
+		Register(luactx.NewSourceModule())
 
 	return &ActionModule{
 		registry:       registry,
@@ -75,22 +79,49 @@ func (m *ActionModule) Loader(L *lua.LState) int {
 
 	L.SetField(mod, "define", L.NewFunction(m.define))
 	L.SetField(mod, "run", L.NewFunction(m.run))
+	L.SetField(mod, "run_or_raise", L.NewFunction(m.runOrRaise))
 
 	L.Push(mod)
 	return 1
 }
 
-// run(name, args) - Run an action immediately (useful for startup)
+// run(name, args) -> (ok, err)
+// Runs an action immediately and reports failure instead of raising.
 // Note: This bypasses the ledger/deduplication, use for initialization only
 func (m *ActionModule) run(L *lua.LState) int {
 	name := L.CheckString(1)
 	argsTable := L.OptTable(2, L.NewTable())
-	args := LuaTableToMap(argsTable)
 
+	if err := m.execute(L, name, LuaTableToMap(argsTable)); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// run_or_raise(name, args)
+// Like run(), but raises a Lua error on failure (fail-fast style).
+func (m *ActionModule) runOrRaise(L *lua.LState) int {
+	name := L.CheckString(1)
+	argsTable := L.OptTable(2, L.NewTable())
+
+	if err := m.execute(L, name, LuaTableToMap(argsTable)); err != nil {
+		L.RaiseError("%s", err.Error())
+		return 0
+	}
+
+	return 0
+}
+
+// execute runs a registered action directly with a minimal context.
+func (m *ActionModule) execute(L *lua.LState, name string, args map[string]any) error {
 	action, exists := m.registry.Get(name)
 	if !exists {
-		L.RaiseError("action %q not found", name)
-		return 0
+		return fmt.Errorf("action %q not found", name)
 	}
 
 	// Ensure L has a valid context (may be nil during script loading)
@@ -108,25 +139,38 @@ func (m *ActionModule) run(L *lua.LState) int {
 	log.Info().Str("trigger", "lua").Str("action", name).Msg("Action triggered by Lua script")
 
 	if err := action.Execute(actx, args); err != nil {
-		L.RaiseError("action %q failed: %s", name, err.Error())
-		return 0
+		return fmt.Errorf("action %q failed: %w", name, err)
 	}
 
-	return 0
+	return nil
 }
 
-// define(name, function) - Define an action
+// define(name, function, opts) - Define an action
+// opts.args = {group = "string", bri = "number?"} declares the arguments; each
+// invocation is validated (and strings coerced) against it before the function
+// runs, failing with every missing or mistyped argument listed.
 func (m *ActionModule) define(L *lua.LState) int {
 	name := L.CheckString(1)
 	fn := L.CheckFunction(2)
+	optsTable := L.OptTable(3, L.NewTable())
+
+	var schema argSchema
+	if argsTable, ok := optsTable.RawGetString("args").(*lua.LTable); ok {
+		var err error
+		if schema, err = parseArgSchema(argsTable); err != nil {
+			L.RaiseError("action %q: %s", name, err.Error())
+			return 0
+		}
+	}
 
 	action := &luaAction{
 		actionContext: actionContext{
 			L:              L,
 			contextBuilder: m.contextBuilder,
 		},
-		name: name,
-		fn:   fn,
+		name:   name,
+		fn:     fn,
+		schema: schema,
 	}
 
 	if err := m.registry.Register(action); err != nil {
@@ -140,13 +184,21 @@ func (m *ActionModule) define(L *lua.LState) int {
 // luaAction wraps a Lua function as an action
 type luaAction struct {
 	actionContext
-	name string
-	fn   *lua.LFunction
+	name   string
+	fn     *lua.LFunction
+	schema argSchema // nil when the action declares no args
 }
 
 func (a *luaAction) Name() string { return a.name }
 
 func (a *luaAction) Execute(ctx *actions.Context, args map[string]any) error {
+	if a.schema != nil {
+		var err error
+		if args, err = a.schema.apply(args); err != nil {
+			return err
+		}
+	}
+
 	// Update LState context to include request data from webhook triggers
 	a.L.SetContext(ctx.Ctx())
 
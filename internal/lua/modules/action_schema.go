@@ -0,0 +1,145 @@
+package modules
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Argument types accepted in an action schema. A trailing "?" marks the
+// argument optional, e.g. "number?".
+var argKinds = []string{"string", "number", "boolean", "table", "any"}
+
+// argField is one declared argument of an action
+type argField struct {
+	name     string
+	kind     string
+	optional bool
+}
+
+// argSchema validates and coerces the args an action is invoked with
+type argSchema []argField
+
+// parseArgSchema reads {name = "type", ...} from action.define's opts.args.
+func parseArgSchema(tbl *lua.LTable) (argSchema, error) {
+	var schema argSchema
+	var err error
+	tbl.ForEach(func(k, v lua.LValue) {
+		if err != nil {
+			return
+		}
+		name, ok := k.(lua.LString)
+		if !ok {
+			err = fmt.Errorf("argument names must be strings, got %s", k.Type())
+			return
+		}
+		spec, ok := v.(lua.LString)
+		if !ok {
+			err = fmt.Errorf("argument %q: type must be a string, got %s", name, v.Type())
+			return
+		}
+		kind, optional := strings.CutSuffix(string(spec), "?")
+		if !slices.Contains(argKinds, kind) {
+			err = fmt.Errorf("argument %q: unknown type %q (expected one of %s)", name, kind, strings.Join(argKinds, ", "))
+			return
+		}
+		schema = append(schema, argField{name: string(name), kind: kind, optional: optional})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].name < schema[j].name })
+	return schema, nil
+}
+
+// apply checks args against the schema and returns a copy with values
+// coerced to their declared types (e.g. "50" from a query string to 50).
+// Every problem is reported, not just the first. Undeclared args pass through.
+func (s argSchema) apply(args map[string]any) (map[string]any, error) {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+
+	var problems []string
+	for _, f := range s {
+		v, ok := args[f.name]
+		if !ok || v == nil {
+			if !f.optional {
+				problems = append(problems, fmt.Sprintf("missing required %q (%s)", f.name, f.kind))
+			}
+			continue
+		}
+		coerced, ok := coerceArg(f.kind, v)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q must be a %s, got %s", f.name, f.kind, describeArg(v)))
+			continue
+		}
+		out[f.name] = coerced
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid args: %s", strings.Join(problems, "; "))
+	}
+	return out, nil
+}
+
+// coerceArg converts v to kind where the conversion is lossless
+func coerceArg(kind string, v any) (any, bool) {
+	switch kind {
+	case "string":
+		switch val := v.(type) {
+		case string:
+			return val, true
+		case float64:
+			return strconv.FormatFloat(val, 'f', -1, 64), true
+		}
+	case "number":
+		switch val := v.(type) {
+		case float64:
+			return val, true
+		case int:
+			return float64(val), true
+		case string:
+			if n, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				return n, true
+			}
+		}
+	case "boolean":
+		switch val := v.(type) {
+		case bool:
+			return val, true
+		case string:
+			if b, err := strconv.ParseBool(val); err == nil {
+				return b, true
+			}
+		}
+	case "table":
+		switch v.(type) {
+		case map[string]any, []any, []string:
+			return v, true
+		}
+	case "any":
+		return v, true
+	}
+	return nil, false
+}
+
+// describeArg names the type and value of an arg, for error messages
+func describeArg(v any) string {
+	switch val := v.(type) {
+	case string:
+		return fmt.Sprintf("string %q", val)
+	case float64, int:
+		return fmt.Sprintf("number %v", val)
+	case bool:
+		return fmt.Sprintf("boolean %v", val)
+	case map[string]any, []any, []string:
+		return "table"
+	}
+	return fmt.Sprintf("%T", v)
+}
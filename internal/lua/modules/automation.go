@@ -0,0 +1,85 @@
+package modules
+
+import (
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+)
+
+// AutomationModule provides automation.* controls over automated invocations.
+//
+// ERROR HANDLING CONVENTION:
+//   - suppress(), resume(): Return (ok, error_string)
+//   - is_suppressed(): Always returns (active, until)
+type AutomationModule struct {
+	suppression *actions.Suppression
+}
+
+// NewAutomationModule creates a new automation module
+func NewAutomationModule(suppression *actions.Suppression) *AutomationModule {
+	return &AutomationModule{suppression: suppression}
+}
+
+// Loader is the module loader for Lua
+func (m *AutomationModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "suppress", L.NewFunction(m.suppress))
+	L.SetField(mod, "resume", L.NewFunction(m.resume))
+	L.SetField(mod, "is_suppressed", L.NewFunction(m.isSuppressed))
+
+	L.Push(mod)
+	return 1
+}
+
+// suppress(duration) -> (ok, err)
+// Skips scheduled and sensor-triggered actions for duration (e.g. "30m").
+func (m *AutomationModule) suppress(L *lua.LState) int {
+	d, err := time.ParseDuration(L.CheckString(1))
+	if err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	if _, err := m.suppression.Suppress(d); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// resume() -> (ok, err)
+// Ends the suppression window early.
+func (m *AutomationModule) resume(L *lua.LState) int {
+	if err := m.suppression.Resume(); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// is_suppressed() -> (active, until)
+// until is a Unix timestamp, or nil when no window is active.
+func (m *AutomationModule) isSuppressed(L *lua.LState) int {
+	until, active := m.suppression.Until()
+	if !active {
+		L.Push(lua.LFalse)
+		L.Push(lua.LNil)
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNumber(until.Unix()))
+	return 2
+}
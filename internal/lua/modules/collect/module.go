@@ -13,6 +13,7 @@ const collectorTypeName = "Collector"
 type CollectorFactory struct {
 	Type       string // "quiet", "count", "interval"
 	QuietMs    int
+	Adaptive   bool // quiet only: widen the quiet period while events keep arriving
 	Count      int
 	IntervalMs int
 	Reducer    *lua.LFunction
@@ -22,6 +23,9 @@ type CollectorFactory struct {
 func (f *CollectorFactory) Create(onFlush middleware.FlushFunc) middleware.Collector {
 	switch f.Type {
 	case "quiet":
+		if f.Adaptive {
+			return middleware.NewAdaptiveQuietCollector(f.QuietMs, onFlush)
+		}
 		return middleware.NewQuietCollector(f.QuietMs, onFlush)
 	case "count":
 		return middleware.NewCountCollector(f.Count, onFlush)
@@ -55,15 +59,19 @@ func (m *Module) Loader(L *lua.LState) int {
 	return 1
 }
 
-// collect.quiet(ms, reducer) - Flush after ms of no new events
+// collect.quiet(ms, reducer, opts?) - Flush after ms of no new events
+// opts.adaptive = true widens the quiet period to the gap between events while
+// they keep arriving, so a long rotary spin is flushed once when it stops
 func (m *Module) quiet(L *lua.LState) int {
 	ms := L.CheckInt(1)
 	reducer := L.CheckFunction(2)
+	opts := L.OptTable(3, L.NewTable())
 
 	factory := &CollectorFactory{
-		Type:    "quiet",
-		QuietMs: ms,
-		Reducer: reducer,
+		Type:     "quiet",
+		QuietMs:  ms,
+		Adaptive: lua.LVAsBool(opts.RawGetString("adaptive")),
+		Reducer:  reducer,
 	}
 
 	ud := L.NewUserData()
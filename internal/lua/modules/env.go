@@ -0,0 +1,41 @@
+package modules
+
+import (
+	"os"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// EnvModule provides read access to environment variables to Lua, e.g. for
+// secrets that shouldn't live in the script
+type EnvModule struct{}
+
+// NewEnvModule creates a new env module
+func NewEnvModule() *EnvModule {
+	return &EnvModule{}
+}
+
+// Loader is the module loader for Lua
+func (m *EnvModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "get", L.NewFunction(m.get))
+
+	L.Push(mod)
+	return 1
+}
+
+// get(name, default?) -> string|default
+// Returns the variable's value, or default (nil if omitted) when it is unset
+// or empty, the same as ${VAR:default} in the config file.
+func (m *EnvModule) get(L *lua.LState) int {
+	name := L.CheckString(1)
+	def := L.Get(2)
+
+	if val := os.Getenv(name); val != "" {
+		L.Push(lua.LString(val))
+		return 1
+	}
+	L.Push(def)
+	return 1
+}
@@ -0,0 +1,117 @@
+package modules
+
+import (
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/events"
+	actionevents "github.com/dokzlo13/lightd/internal/events/action"
+	"github.com/dokzlo13/lightd/internal/events/filter"
+	"github.com/dokzlo13/lightd/internal/events/sse"
+)
+
+// filterableEvents lists the event types events.filter() accepts
+var filterableEvents = map[string]events.EventType{
+	"button":        events.EventTypeButton,
+	"rotary":        events.EventTypeRotary,
+	"connectivity":  events.EventTypeConnectivity,
+	"light_change":  events.EventTypeLightChange,
+	"schedule":      events.EventTypeSchedule,
+	"webhook":       events.EventTypeWebhook,
+	"mode_change":   events.EventTypeModeChange,
+	"device_change": events.EventTypeDeviceChange,
+	"action_failed": events.EventTypeActionFailed,
+}
+
+// EventsModule provides the events Lua module for internal lightd events.
+//
+// ERROR HANDLING CONVENTION:
+//   - action_failed(), filter(): Raise on invalid arguments (setup-time API)
+//   - sse.is_enabled(): Never fails
+type EventsModule struct {
+	failedHandlers []actionevents.FailedHandler
+	filters        []filter.Filter
+	sseEnabled     bool
+}
+
+// NewEventsModule creates a new events module. sseEnabled is reported by
+// events.sse.is_enabled() so scripts can check before requiring events.sse.
+func NewEventsModule(sseEnabled bool) *EventsModule {
+	return &EventsModule{sseEnabled: sseEnabled}
+}
+
+// Loader is the module loader for Lua
+func (m *EventsModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "action_failed", L.NewFunction(m.actionFailed))
+	L.SetField(mod, "filter", L.NewFunction(m.filter))
+
+	// require("events.sse") raises when SSE is disabled; this lets scripts check first
+	sseTbl := L.NewTable()
+	L.SetField(sseTbl, "is_enabled", L.NewFunction(m.sseIsEnabled))
+	L.SetField(mod, "sse", sseTbl)
+
+	L.Push(mod)
+	return 1
+}
+
+// action_failed(action_pattern, action_name, args) - Register an action to run when a matching action fails
+// The pattern is "*", a name, or "a|b". The action receives args.action, args.error and
+// args.source merged with the static args.
+func (m *EventsModule) actionFailed(L *lua.LState) int {
+	pattern := L.CheckString(1)
+	actionName := L.CheckString(2)
+	argsTable := L.OptTable(3, L.NewTable())
+
+	m.failedHandlers = append(m.failedHandlers, actionevents.FailedHandler{
+		Action:     sse.ParseMatcher(pattern),
+		ActionName: actionName,
+		ActionArgs: LuaTableToMap(argsTable),
+	})
+
+	log.Debug().Str("pattern", pattern).Str("action", actionName).Msg("Registered action_failed handler")
+	return 0
+}
+
+// filter(event_type, fn, priority?) - Register a filter that runs before any handler of event_type
+// fn receives the event data; returning false swallows the event. Higher priorities run first.
+func (m *EventsModule) filter(L *lua.LState) int {
+	name := L.CheckString(1)
+	fn := L.CheckFunction(2)
+	priority := L.OptInt(3, 0)
+
+	eventType, ok := filterableEvents[name]
+	if !ok {
+		L.ArgError(1, "unknown event type: "+name)
+		return 0
+	}
+
+	m.filters = append(m.filters, filter.Filter{
+		EventType: eventType,
+		Priority:  priority,
+		Fn:        fn,
+	})
+
+	log.Debug().Str("event_type", name).Int("priority", priority).Msg("Registered event filter")
+	return 0
+}
+
+// sse.is_enabled() -> bool
+// Reports whether SSE events are enabled in config.
+func (m *EventsModule) sseIsEnabled(L *lua.LState) int {
+	L.Push(lua.LBool(m.sseEnabled))
+	return 1
+}
+
+// GetActionFailedHandlers returns all registered action failure handlers.
+// Implements the actionevents.HandlerRegistry interface.
+func (m *EventsModule) GetActionFailedHandlers() []actionevents.FailedHandler {
+	return m.failedHandlers
+}
+
+// GetEventFilters returns all registered event filters.
+// Implements the filter.Registry interface.
+func (m *EventsModule) GetEventFilters() []filter.Filter {
+	return m.filters
+}
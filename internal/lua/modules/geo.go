@@ -14,14 +14,20 @@ type GeoModule struct {
 	defaultLocation string
 	defaultTimezone string
 	calculator      *geo.Calculator
+	enabled         bool // events.scheduler.geo.enabled
+	astronomical    bool // the scheduler accepts @dawn, @sunset, ... expressions
 }
 
-// NewGeoModule creates a new geo module with a shared calculator
-func NewGeoModule(defaultLocation, defaultTimezone string, calculator *geo.Calculator) *GeoModule {
+// NewGeoModule creates a new geo module with a shared calculator.
+// enabled reflects the geo config; astronomical whether the scheduler's
+// evaluator supports astronomical times.
+func NewGeoModule(defaultLocation, defaultTimezone string, calculator *geo.Calculator, enabled, astronomical bool) *GeoModule {
 	return &GeoModule{
 		defaultLocation: defaultLocation,
 		defaultTimezone: defaultTimezone,
 		calculator:      calculator,
+		enabled:         enabled,
+		astronomical:    astronomical,
 	}
 }
 
@@ -30,11 +36,33 @@ func (m *GeoModule) Loader(L *lua.LState) int {
 	mod := L.NewTable()
 
 	L.SetField(mod, "today", L.NewFunction(m.today))
+	L.SetField(mod, "is_day", L.NewFunction(m.isDay))
+	L.SetField(mod, "is_night", L.NewFunction(m.isNight))
+	L.SetField(mod, "next_event", L.NewFunction(m.nextEvent))
+	L.SetField(mod, "cache_list", L.NewFunction(m.cacheList))
+	L.SetField(mod, "cache_clear", L.NewFunction(m.cacheClear))
+	L.SetField(mod, "is_enabled", L.NewFunction(m.isEnabled))
+	L.SetField(mod, "supports_astronomical", L.NewFunction(m.supportsAstronomical))
 
 	L.Push(mod)
 	return 1
 }
 
+// is_enabled() -> bool
+// Whether geo is enabled in the config (events.scheduler.geo.enabled)
+func (m *GeoModule) isEnabled(L *lua.LState) int {
+	L.Push(lua.LBool(m.enabled))
+	return 1
+}
+
+// supports_astronomical() -> bool
+// Whether sched.define accepts astronomical expressions like "@sunset".
+// False when geo or the scheduler is disabled.
+func (m *GeoModule) supportsAstronomical(L *lua.LState) int {
+	L.Push(lua.LBool(m.astronomical))
+	return 1
+}
+
 // today(location?) -> {dawn, sunrise, noon, sunset, dusk, midnight}
 // Returns Unix timestamps for astronomical events
 func (m *GeoModule) today(L *lua.LState) int {
@@ -74,3 +102,134 @@ func (m *GeoModule) today(L *lua.LState) int {
 	return 1
 }
 
+// is_day(location?) -> (bool, err)
+// True between today's sunrise and sunset; always true during polar day and
+// false during polar night.
+func (m *GeoModule) isDay(L *lua.LState) int {
+	return m.pushDaylight(L, true)
+}
+
+// is_night(location?) -> (bool, err)
+// Complement of is_day().
+func (m *GeoModule) isNight(L *lua.LState) int {
+	return m.pushDaylight(L, false)
+}
+
+// pushDaylight pushes whether it is currently day (or night when day is false).
+func (m *GeoModule) pushDaylight(L *lua.LState, day bool) int {
+	location := L.OptString(1, m.defaultLocation)
+
+	times, err := m.calculator.GetTimesForToday(location, m.defaultTimezone)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LBool(times.IsDay(time.Now()) == day))
+	L.Push(lua.LNil)
+	return 2
+}
+
+// next_event(location?) -> ({name, time, in_seconds}, err)
+// Returns the soonest upcoming of dawn, sunrise, noon, sunset and dusk, looking
+// at today and tomorrow in the configured timezone. time is a Unix timestamp.
+// Sunrise and sunset are skipped on days the sun doesn't rise or set.
+func (m *GeoModule) nextEvent(L *lua.LState) int {
+	location := L.OptString(1, m.defaultLocation)
+
+	tz, _ := time.LoadLocation(m.defaultTimezone)
+	if tz == nil {
+		tz = time.UTC
+	}
+	now := time.Now().In(tz)
+
+	var bestName string
+	var best time.Time
+	for _, day := range []time.Time{now, now.AddDate(0, 0, 1)} {
+		times, err := m.calculator.GetTimes(location, day, m.defaultTimezone)
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+
+		polar := times.PolarDay || times.PolarNight
+		civilPolar := times.CivilPolarDay || times.CivilPolarNight
+		events := []struct {
+			name  string
+			t     time.Time
+			polar bool // meaningless during (civil) polar day or night
+		}{
+			{"dawn", times.Dawn, civilPolar},
+			{"sunrise", times.Sunrise, polar},
+			{"noon", times.Noon, false},
+			{"sunset", times.Sunset, polar},
+			{"dusk", times.Dusk, civilPolar},
+		}
+		for _, e := range events {
+			if e.polar || e.t.IsZero() || !e.t.After(now) {
+				continue
+			}
+			if best.IsZero() || e.t.Before(best) {
+				bestName, best = e.name, e.t
+			}
+		}
+	}
+
+	if best.IsZero() {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("no astronomical event today or tomorrow"))
+		return 2
+	}
+
+	result := L.NewTable()
+	L.SetField(result, "name", lua.LString(bestName))
+	L.SetField(result, "time", lua.LNumber(best.Unix()))
+	L.SetField(result, "in_seconds", lua.LNumber(int64(best.Sub(now).Seconds())))
+	L.Push(result)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// cache_list() -> {{query, name, lat, lon, created_at}, ...}
+// Returns persisted geocache entries. Returns an empty table on failure.
+func (m *GeoModule) cacheList(L *lua.LState) int {
+	entries, err := m.calculator.CachedLocations()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list geocache")
+	}
+
+	result := L.NewTable()
+	for i, e := range entries {
+		entry := L.NewTable()
+		L.SetField(entry, "query", lua.LString(e.Query))
+		L.SetField(entry, "name", lua.LString(e.Name))
+		L.SetField(entry, "lat", lua.LNumber(e.Latitude))
+		L.SetField(entry, "lon", lua.LNumber(e.Longitude))
+		L.SetField(entry, "created_at", lua.LNumber(e.CreatedAt.Unix()))
+		result.RawSetInt(i+1, entry)
+	}
+
+	L.Push(result)
+	return 1
+}
+
+// cache_clear(name?) -> (count, err)
+// Forgets a geocoded location so it is resolved again on next use.
+// Without a name, clears every cached location.
+func (m *GeoModule) cacheClear(L *lua.LState) int {
+	name := L.OptString(1, "")
+
+	removed, err := m.calculator.ClearCachedLocation(name)
+	if err != nil {
+		L.Push(lua.LNumber(0))
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	log.Info().Str("query", name).Int64("removed", removed).Msg("Geocache cleared")
+	L.Push(lua.LNumber(removed))
+	L.Push(lua.LNil)
+	return 2
+}
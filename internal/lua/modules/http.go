@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Outbound request limits
+const (
+	httpRequestTimeout = 10 * time.Second
+	httpMaxBodySize    = 1 << 20 // response bytes returned to Lua
+)
+
+// HTTPModule provides http.* outbound requests to Lua, e.g. to push
+// notifications to Home Assistant, ntfy or a chat bot.
+//
+// Requests block the Lua worker until they complete or time out.
+//
+// ERROR HANDLING CONVENTION:
+//   - get(), post(): Return (status, body, error_string)
+//   - render(): Always returns a string; raises on an invalid escape mode
+//   - Any HTTP status is a successful request; err is set only when no
+//     response was received (bad URL, network error, timeout)
+type HTTPModule struct {
+	client *http.Client
+}
+
+// NewHTTPModule creates a new http module with a shared client
+func NewHTTPModule() *HTTPModule {
+	return &HTTPModule{
+		client: &http.Client{Timeout: httpRequestTimeout},
+	}
+}
+
+// Loader is the module loader for Lua
+func (m *HTTPModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "get", L.NewFunction(m.get))
+	L.SetField(mod, "post", L.NewFunction(m.post))
+	L.SetField(mod, "render", L.NewFunction(m.render))
+
+	L.Push(mod)
+	return 1
+}
+
+// get(url, headers?) -> (status, body, err)
+func (m *HTTPModule) get(L *lua.LState) int {
+	url := L.CheckString(1)
+	headers := L.OptTable(2, nil)
+
+	return m.do(L, http.MethodGet, url, nil, "", headers)
+}
+
+// post(url, body?, headers?) -> (status, body, err)
+// body may be a string (sent as-is) or a table (sent as JSON).
+func (m *HTTPModule) post(L *lua.LState) int {
+	url := L.CheckString(1)
+	headers := L.OptTable(3, nil)
+
+	var body io.Reader
+	contentType := ""
+	switch v := L.Get(2).(type) {
+	case *lua.LNilType:
+	case lua.LString:
+		body = strings.NewReader(string(v))
+	case *lua.LTable:
+		data, err := json.Marshal(LuaToGo(v))
+		if err != nil {
+			return pushHTTPError(L, fmt.Errorf("failed to encode body: %w", err))
+		}
+		body = strings.NewReader(string(data))
+		contentType = "application/json"
+	default:
+		L.ArgError(2, "body must be a string or table")
+		return 0
+	}
+
+	return m.do(L, http.MethodPost, url, body, contentType, headers)
+}
+
+func (m *HTTPModule) do(L *lua.LState, method, url string, body io.Reader, contentType string, headers *lua.LTable) int {
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return pushHTTPError(L, err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers != nil {
+		headers.ForEach(func(k, v lua.LValue) {
+			req.Header.Set(k.String(), v.String())
+		})
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return pushHTTPError(L, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, httpMaxBodySize))
+	if err != nil {
+		return pushHTTPError(L, fmt.Errorf("failed to read response: %w", err))
+	}
+
+	L.Push(lua.LNumber(resp.StatusCode))
+	L.Push(lua.LString(data))
+	L.Push(lua.LNil)
+	return 3
+}
+
+func pushHTTPError(L *lua.LState, err error) int {
+	L.Push(lua.LNil)
+	L.Push(lua.LNil)
+	L.Push(lua.LString(err.Error()))
+	return 3
+}
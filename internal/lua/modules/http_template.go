@@ -0,0 +1,88 @@
+package modules
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// templatePlaceholder matches {{key}} or {{ key.path }}
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// Escaping modes for http.render()
+const (
+	templateEscapeNone = ""
+	templateEscapeJSON = "json"
+	templateEscapeHTML = "html"
+)
+
+// render(template, vars, escape?) -> string
+// Replaces {{key}} with vars.key; dotted keys ({{event.device}}) walk nested
+// tables. Unknown keys are left as-is so they show up in the output.
+// escape: "json" (for use inside a JSON string literal) or "html".
+func (m *HTTPModule) render(L *lua.LState) int {
+	tpl := L.CheckString(1)
+	vars := L.OptTable(2, L.NewTable())
+	escape := L.OptString(3, templateEscapeNone)
+
+	switch escape {
+	case templateEscapeNone, templateEscapeJSON, templateEscapeHTML:
+	default:
+		L.ArgError(3, `escape must be "json" or "html"`)
+		return 0
+	}
+
+	L.Push(lua.LString(renderTemplate(tpl, vars, escape)))
+	return 1
+}
+
+func renderTemplate(tpl string, vars *lua.LTable, escape string) string {
+	return templatePlaceholder.ReplaceAllStringFunc(tpl, func(match string) string {
+		key := templatePlaceholder.FindStringSubmatch(match)[1]
+		value, ok := lookupTemplateVar(vars, key)
+		if !ok {
+			return match
+		}
+		return escapeTemplateValue(templateValueString(value), escape)
+	})
+}
+
+// lookupTemplateVar resolves a dotted key against nested tables.
+func lookupTemplateVar(vars *lua.LTable, key string) (lua.LValue, bool) {
+	var current lua.LValue = vars
+	for _, part := range strings.Split(key, ".") {
+		tbl, ok := current.(*lua.LTable)
+		if !ok {
+			return lua.LNil, false
+		}
+		current = tbl.RawGetString(part)
+	}
+	return current, current != lua.LNil
+}
+
+// templateValueString formats a value for substitution; tables become JSON.
+func templateValueString(v lua.LValue) string {
+	if tbl, ok := v.(*lua.LTable); ok {
+		data, err := json.Marshal(LuaToGo(tbl))
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+	return v.String()
+}
+
+func escapeTemplateValue(s, escape string) string {
+	switch escape {
+	case templateEscapeJSON:
+		data, _ := json.Marshal(s)
+		return string(data[1 : len(data)-1]) // strip the surrounding quotes
+	case templateEscapeHTML:
+		return html.EscapeString(s)
+	default:
+		return s
+	}
+}
@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"strings"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// renderLua runs http.render with the given Lua argument list and returns the result.
+func renderLua(t *testing.T, args string) (string, error) {
+	t.Helper()
+
+	L := lua.NewState()
+	defer L.Close()
+	L.PreloadModule("http", NewHTTPModule().Loader)
+
+	if err := L.DoString(`return require("http").render(` + args + `)`); err != nil {
+		return "", err
+	}
+	return L.Get(-1).String(), nil
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{
+			name: "plain substitution",
+			args: `"{{name}} is {{ state }}", {name = "desk", state = "on"}`,
+			want: "desk is on",
+		},
+		{
+			name: "dotted keys walk nested tables",
+			args: `"{{event.device}}", {event = {device = "dimmer"}}`,
+			want: "dimmer",
+		},
+		{
+			name: "unknown keys are left as-is",
+			args: `"{{missing}} {{event.nope}} {{name.deeper}}", {event = {}, name = "desk"}`,
+			want: "{{missing}} {{event.nope}} {{name.deeper}}",
+		},
+		{
+			name: "tables become JSON",
+			args: `"{{data}}", {data = {bri = 128}}`,
+			want: `{"bri":128}`,
+		},
+		{
+			name: "no escaping by default",
+			args: `"{{v}}", {v = "<a & \"b\">"}`,
+			want: `<a & "b">`,
+		},
+		{
+			name: "json escaping",
+			args: `"{\"msg\":\"{{v}}\"}", {v = "say \"hi\"\n\\path"}, "json"`,
+			want: `{"msg":"say \"hi\"\n\\path"}`,
+		},
+		{
+			name: "html escaping",
+			args: `"<p>{{v}}</p>", {v = "<script>&'\""}, "html"`,
+			want: "<p>&lt;script&gt;&amp;&#39;&#34;</p>",
+		},
+		{
+			name: "escaping leaves the template itself alone",
+			args: `"<b>{{v}}</b>", {v = "x"}, "html"`,
+			want: "<b>x</b>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderLua(t, tt.args)
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_InvalidEscapeRaises(t *testing.T) {
+	_, err := renderLua(t, `"{{v}}", {v = "x"}, "xml"`)
+	if err == nil || !strings.Contains(err.Error(), "escape must be") {
+		t.Fatalf("render() error = %v, want invalid escape error", err)
+	}
+}
@@ -1,13 +1,17 @@
 package modules
 
 import (
+	"context"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/amimof/huego"
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
 
 	"github.com/dokzlo13/lightd/internal/hue"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
 // HueModule provides hue.* functions to Lua.
@@ -43,15 +47,42 @@ import (
 //	    hue = 40000,
 //	})
 type HueModule struct {
-	bridge     *huego.Bridge
+	bridge     func() *huego.Bridge // current V1 bridge (replaced on key rotation)
+	v2         *hue.ResolvingV2Client
 	sceneIndex *hue.SceneIndex
+	devices    *hue.DeviceIndex
+	rotateKey  func(ctx context.Context) error // nil when key rotation is unavailable
+	refresh    func(ctx context.Context) error // nil when cache refresh is unavailable
+	clientKey  func() string                   // nil when the client key is unavailable
+	rotator    *SceneRotator                   // set by NewSceneRotator; nil when unavailable
+
+	// Writes a resource's current brightness back as desired, stopping
+	// reconciler ramps (nil when the reconciler is unavailable)
+	holdBri func(ctx context.Context, kind reconcile.Kind, id string) error
+
+	// User-defined named colors (only touched from the Lua worker)
+	customColors map[string]rgbColor
+
+	// Friendly names for light and group IDs, keyed by kind (only touched from the Lua worker)
+	aliases map[string]map[string]int
 }
 
 // NewHueModule creates a new hue module
-func NewHueModule(bridge *huego.Bridge, sceneIndex *hue.SceneIndex) *HueModule {
+func NewHueModule(bridge func() *huego.Bridge, v2Client *hue.ResolvingV2Client, sceneIndex *hue.SceneIndex, devices *hue.DeviceIndex, rotateKey, refresh func(ctx context.Context) error, clientKey func() string, holdBri func(ctx context.Context, kind reconcile.Kind, id string) error) *HueModule {
 	return &HueModule{
-		bridge:     bridge,
-		sceneIndex: sceneIndex,
+		bridge:       bridge,
+		v2:           v2Client,
+		sceneIndex:   sceneIndex,
+		devices:      devices,
+		rotateKey:    rotateKey,
+		refresh:      refresh,
+		clientKey:    clientKey,
+		holdBri:      holdBri,
+		customColors: make(map[string]rgbColor),
+		aliases: map[string]map[string]int{
+			aliasKindLight: {},
+			aliasKindGroup: {},
+		},
 	}
 }
 
@@ -75,13 +106,94 @@ func (m *HueModule) Loader(L *lua.LState) int {
 	// NEW: Factory methods for userdata-based API
 	L.SetField(mod, "light", L.NewFunction(m.getLight))
 	L.SetField(mod, "lights", L.NewFunction(m.getLights))
+	L.SetField(mod, "lights_where", L.NewFunction(m.lightsWhere))
 	L.SetField(mod, "group", L.NewFunction(m.getGroup))
 	L.SetField(mod, "groups", L.NewFunction(m.getGroups))
 
+	// Ambiance scene recall: weighted random pick, or rotation through a list
+	L.SetField(mod, "random_scene", L.NewFunction(m.randomScene))
+	L.SetField(mod, "rotate_scenes", L.NewFunction(m.rotateScenesFn))
+
+	// Color and brightness unit conversion helpers
+	L.SetField(mod, "rgb_to_xy", L.NewFunction(hueRGBToXY))
+	L.SetField(mod, "kelvin_to_mirek", L.NewFunction(hueKelvinToMirek))
+	L.SetField(mod, "mirek_to_kelvin", L.NewFunction(hueMirekToKelvin))
+	L.SetField(mod, "bri254_to_pct", L.NewFunction(hueBri254ToPct))
+	L.SetField(mod, "pct_to_bri254", L.NewFunction(huePctToBri254))
+	L.SetField(mod, "color", L.NewFunction(m.color))
+	L.SetField(mod, "define_color", L.NewFunction(m.defineColor))
+
+	// Device discovery (button/rotary resource IDs)
+	L.SetField(mod, "devices", L.NewFunction(m.listDevices))
+
+	// Bridge model and firmware
+	L.SetField(mod, "bridge_info", L.NewFunction(m.bridgeInfo))
+
+	// Sensor polling (temperature, motion, light level)
+	L.SetField(mod, "sensor", L.NewFunction(m.sensor))
+
+	// Stop running effects / dynamic scenes
+	L.SetField(mod, "stop_effects", L.NewFunction(m.stopEffects))
+
+	// Friendly names for light and group IDs
+	L.SetField(mod, "alias", L.NewFunction(m.alias))
+
+	// Raw V2 access for resource types without a typed binding
+	L.SetField(mod, "v2_get", L.NewFunction(m.v2Get))
+	L.SetField(mod, "v2_put", L.NewFunction(m.v2Put))
+
+	// Administration
+	L.SetField(mod, "rotate_key", L.NewFunction(m.rotateKeyFn))
+	L.SetField(mod, "refresh", L.NewFunction(m.refreshFn))
+	L.SetField(mod, "clientkey", L.NewFunction(m.clientKeyFn))
+
 	L.Push(mod)
 	return 1
 }
 
+// =============================================================================
+// Raw V2 Access
+// =============================================================================
+
+// v2_get(path) -> (table, err)
+// GETs a CLIP v2 path (e.g. "resource/light/<id>") and returns the decoded JSON.
+func (m *HueModule) v2Get(L *lua.LState) int {
+	return m.v2Raw(L, "GET", L.CheckString(1), nil)
+}
+
+// v2_put(path, body) -> (table, err)
+// PUTs body (marshalled as JSON) to a CLIP v2 path and returns the decoded response.
+func (m *HueModule) v2Put(L *lua.LState) int {
+	path := L.CheckString(1)
+	body := LuaToGo(L.CheckTable(2))
+	return m.v2Raw(L, "PUT", path, body)
+}
+
+func (m *HueModule) v2Raw(L *lua.LState, method, path string, body any) int {
+	if m.v2 == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("V2 client not available"))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := m.v2.Raw(ctx, method, path, body)
+	if err != nil {
+		log.Error().Err(err).Str("method", method).Str("path", path).Msg("V2 request failed")
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(GoToLuaValue(L, result))
+	L.Push(lua.LNil)
+	return 2
+}
+
 // =============================================================================
 // Factory Methods (return userdata)
 // =============================================================================
@@ -92,13 +204,13 @@ func (m *HueModule) getLight(L *lua.LState) int {
 	var lightID int
 	var err error
 
-	// Accept both string and number
+	// Accept both string (alias or numeric) and number
 	switch v := L.Get(1).(type) {
 	case lua.LString:
-		lightID, err = strconv.Atoi(string(v))
+		lightID, err = m.resolveID(aliasKindLight, string(v))
 		if err != nil {
 			L.Push(lua.LNil)
-			L.Push(lua.LString("invalid light ID: " + string(v)))
+			L.Push(lua.LString(err.Error()))
 			return 2
 		}
 	case lua.LNumber:
@@ -108,7 +220,7 @@ func (m *HueModule) getLight(L *lua.LState) int {
 		return 0
 	}
 
-	light, err := m.bridge.GetLight(lightID)
+	light, err := m.bridge().GetLight(lightID)
 	if err != nil {
 		log.Error().Err(err).Int("light", lightID).Msg("Failed to get light")
 		L.Push(lua.LNil)
@@ -116,7 +228,7 @@ func (m *HueModule) getLight(L *lua.LState) int {
 		return 2
 	}
 
-	pushLight(L, light)
+	pushLight(L, light, m.v2)
 	L.Push(lua.LNil)
 	return 2
 }
@@ -124,7 +236,7 @@ func (m *HueModule) getLight(L *lua.LState) int {
 // getLights() -> (table of light_userdata, err)
 // Returns all lights as userdata
 func (m *HueModule) getLights(L *lua.LState) int {
-	lights, err := m.bridge.GetLights()
+	lights, err := m.bridge().GetLights()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get lights")
 		L.Push(lua.LNil)
@@ -134,7 +246,7 @@ func (m *HueModule) getLights(L *lua.LState) int {
 
 	tbl := L.NewTable()
 	for i := range lights {
-		pushLight(L, &lights[i])
+		pushLight(L, &lights[i], m.v2)
 		tbl.RawSetInt(i+1, L.Get(-1))
 		L.Pop(1)
 	}
@@ -144,19 +256,180 @@ func (m *HueModule) getLights(L *lua.LState) int {
 	return 2
 }
 
+// lightFilter holds the criteria accepted by lights_where; nil fields match anything.
+type lightFilter struct {
+	room      *string
+	on        *bool
+	reachable *bool
+	color     *bool
+	ct        *bool
+}
+
+// checkLightFilter reads a lights_where filter table.
+func checkLightFilter(tbl *lua.LTable) (*lightFilter, error) {
+	f := &lightFilter{}
+	var err error
+	tbl.ForEach(func(k, v lua.LValue) {
+		if err != nil {
+			return
+		}
+		key := k.String()
+		if key == "room" {
+			s, ok := v.(lua.LString)
+			if !ok {
+				err = fmt.Errorf("lights_where: room must be a string")
+				return
+			}
+			room := string(s)
+			f.room = &room
+			return
+		}
+
+		var dst **bool
+		switch key {
+		case "on":
+			dst = &f.on
+		case "reachable":
+			dst = &f.reachable
+		case "color":
+			dst = &f.color
+		case "ct":
+			dst = &f.ct
+		default:
+			err = fmt.Errorf("lights_where: unknown filter %q (expected room, on, reachable, color or ct)", key)
+			return
+		}
+		b, ok := v.(lua.LBool)
+		if !ok {
+			err = fmt.Errorf("lights_where: %s must be a boolean", key)
+			return
+		}
+		val := bool(b)
+		*dst = &val
+	})
+	return f, err
+}
+
+// matches reports whether light satisfies every non-room criterion.
+func (f *lightFilter) matches(light *huego.Light) bool {
+	if light.State != nil {
+		if f.on != nil && light.State.On != *f.on {
+			return false
+		}
+		if f.reachable != nil && light.State.Reachable != *f.reachable {
+			return false
+		}
+	} else if f.on != nil || f.reachable != nil {
+		return false
+	}
+
+	color, ct := lightCapabilities(light)
+	if f.color != nil && color != *f.color {
+		return false
+	}
+	if f.ct != nil && ct != *f.ct {
+		return false
+	}
+	return true
+}
+
+// roomLightIDs returns the V1 IDs of the lights in a room, resolved through
+// the V2 device and room data.
+func (m *HueModule) roomLightIDs(ctx context.Context, room string) (map[int]bool, error) {
+	if m.v2 == nil || m.devices == nil {
+		return nil, fmt.Errorf("V2 client not available")
+	}
+
+	v2IDs, err := m.devices.LightsInRoom(ctx, room)
+	if err != nil {
+		return nil, err
+	}
+	inRoom := make(map[string]bool, len(v2IDs))
+	for _, id := range v2IDs {
+		inRoom[id] = true
+	}
+
+	lights, err := m.v2.GetLights(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[int]bool)
+	for _, l := range lights {
+		if !inRoom[l.ID] {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(l.IDV1, "/lights/")); err == nil {
+			ids[n] = true
+		}
+	}
+	return ids, nil
+}
+
+// lightsWhere(filter) -> (table of light_userdata, err)
+// Returns the lights matching every criterion in filter:
+// room (name, case-insensitive), on, reachable, color and ct (capabilities).
+func (m *HueModule) lightsWhere(L *lua.LState) int {
+	filter, err := checkLightFilter(L.CheckTable(1))
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var roomIDs map[int]bool
+	if filter.room != nil {
+		roomIDs, err = m.roomLightIDs(ctx, *filter.room)
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+	}
+
+	lights, err := m.bridge().GetLightsContext(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get lights")
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	tbl := L.NewTable()
+	for i := range lights {
+		if roomIDs != nil && !roomIDs[lights[i].ID] {
+			continue
+		}
+		if !filter.matches(&lights[i]) {
+			continue
+		}
+		pushLight(L, &lights[i], m.v2)
+		tbl.Append(L.Get(-1))
+		L.Pop(1)
+	}
+
+	L.Push(tbl)
+	L.Push(lua.LNil)
+	return 2
+}
+
 // getGroup(id) -> (group_userdata, err)
 // id can be string or number
 func (m *HueModule) getGroup(L *lua.LState) int {
 	var groupID int
 	var err error
 
-	// Accept both string and number
+	// Accept both string (alias or numeric) and number
 	switch v := L.Get(1).(type) {
 	case lua.LString:
-		groupID, err = strconv.Atoi(string(v))
+		groupID, err = m.resolveID(aliasKindGroup, string(v))
 		if err != nil {
 			L.Push(lua.LNil)
-			L.Push(lua.LString("invalid group ID: " + string(v)))
+			L.Push(lua.LString(err.Error()))
 			return 2
 		}
 	case lua.LNumber:
@@ -166,7 +439,7 @@ func (m *HueModule) getGroup(L *lua.LState) int {
 		return 0
 	}
 
-	group, err := m.bridge.GetGroup(groupID)
+	group, err := m.bridge().GetGroup(groupID)
 	if err != nil {
 		log.Error().Err(err).Int("group", groupID).Msg("Failed to get group")
 		L.Push(lua.LNil)
@@ -174,7 +447,7 @@ func (m *HueModule) getGroup(L *lua.LState) int {
 		return 2
 	}
 
-	pushGroup(L, group, m.sceneIndex)
+	pushGroup(L, group, m.sceneIndex, m.v2)
 	L.Push(lua.LNil)
 	return 2
 }
@@ -182,7 +455,7 @@ func (m *HueModule) getGroup(L *lua.LState) int {
 // getGroups() -> (table of group_userdata, err)
 // Returns all groups as userdata
 func (m *HueModule) getGroups(L *lua.LState) int {
-	groups, err := m.bridge.GetGroups()
+	groups, err := m.bridge().GetGroups()
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get groups")
 		L.Push(lua.LNil)
@@ -192,7 +465,7 @@ func (m *HueModule) getGroups(L *lua.LState) int {
 
 	tbl := L.NewTable()
 	for i := range groups {
-		pushGroup(L, &groups[i], m.sceneIndex)
+		pushGroup(L, &groups[i], m.sceneIndex, m.v2)
 		tbl.RawSetInt(i+1, L.Get(-1))
 		L.Pop(1)
 	}
@@ -218,7 +491,7 @@ func (m *HueModule) getGroupState(L *lua.LState) int {
 		return 2
 	}
 
-	group, err := m.bridge.GetGroup(id)
+	group, err := m.bridge().GetGroup(id)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Msg("Failed to get group state")
 		L.Push(lua.LNil)
@@ -262,7 +535,7 @@ func (m *HueModule) setGroupBrightness(L *lua.LState) int {
 		return 2
 	}
 
-	group, err := m.bridge.GetGroup(id)
+	group, err := m.bridge().GetGroup(id)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Int("bri", brightness).Msg("Failed to get group")
 		L.Push(lua.LBool(false))
@@ -298,7 +571,7 @@ func (m *HueModule) adjustGroupBrightness(L *lua.LState) int {
 	}
 
 	// Fetch current brightness
-	group, err := m.bridge.GetGroup(id)
+	group, err := m.bridge().GetGroup(id)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Msg("Failed to get group for brightness adjustment")
 		L.Push(lua.LBool(false))
@@ -346,7 +619,7 @@ func (m *HueModule) getGroupBrightness(L *lua.LState) int {
 		return 2
 	}
 
-	group, err := m.bridge.GetGroup(id)
+	group, err := m.bridge().GetGroup(id)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Msg("Failed to get group brightness")
 		L.Push(lua.LNil)
@@ -378,7 +651,7 @@ func (m *HueModule) recallScene(L *lua.LState) int {
 	}
 
 	// Find scene by name first
-	scene, err := m.sceneIndex.FindByName(sceneName, groupID)
+	scene, err := m.sceneIndex.FindForGroup(sceneName, groupID)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Str("scene", sceneName).Msg("Failed to find scene")
 		L.Push(lua.LBool(false))
@@ -387,7 +660,7 @@ func (m *HueModule) recallScene(L *lua.LState) int {
 	}
 
 	// Get group and activate scene
-	group, err := m.bridge.GetGroup(id)
+	group, err := m.bridge().GetGroup(id)
 	if err != nil {
 		log.Error().Err(err).Str("group", groupID).Msg("Failed to get group")
 		L.Push(lua.LBool(false))
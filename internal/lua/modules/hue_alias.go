@@ -0,0 +1,79 @@
+package modules
+
+import (
+	"fmt"
+	"strconv"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Alias kinds accepted by hue.alias()
+const (
+	aliasKindLight = "light"
+	aliasKindGroup = "group"
+)
+
+// AddAlias registers name as a friendly name for a light or group ID.
+// kind is "light" or "group"; id must be numeric.
+func (m *HueModule) AddAlias(kind, name, id string) error {
+	aliases, ok := m.aliases[kind]
+	if !ok {
+		return fmt.Errorf("invalid alias kind %q (expected \"light\" or \"group\")", kind)
+	}
+	if name == "" {
+		return fmt.Errorf("alias name cannot be empty")
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid %s ID %q for alias %q", kind, id, name)
+	}
+	aliases[name] = n
+	return nil
+}
+
+// resolveID returns the numeric ID for an alias or numeric string.
+// Aliases take precedence over numeric IDs.
+func (m *HueModule) resolveID(kind, ref string) (int, error) {
+	if id, ok := m.aliases[kind][ref]; ok {
+		return id, nil
+	}
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s ID: %s (not a number or known alias)", kind, ref)
+	}
+	return id, nil
+}
+
+// ResolveID returns the numeric ID, as a string, for a light or group alias
+// or numeric string. It backs alias resolution in ctx.desired.
+func (m *HueModule) ResolveID(kind, ref string) (string, error) {
+	id, err := m.resolveID(kind, ref)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+// alias(name, kind, id)
+// Registers name as a friendly name so hue.light(name) / hue.group(name) resolve it.
+// kind is "light" or "group"; id may be a string or number.
+func (m *HueModule) alias(L *lua.LState) int {
+	name := L.CheckString(1)
+	kind := L.CheckString(2)
+
+	var id string
+	switch v := L.Get(3).(type) {
+	case lua.LString:
+		id = string(v)
+	case lua.LNumber:
+		id = strconv.Itoa(int(v))
+	default:
+		L.ArgError(3, "ID must be string or number")
+		return 0
+	}
+
+	if err := m.AddAlias(kind, name, id); err != nil {
+		L.RaiseError("%s", err.Error())
+	}
+	return 0
+}
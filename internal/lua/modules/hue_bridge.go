@@ -0,0 +1,39 @@
+package modules
+
+import (
+	"context"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// bridge_info() -> (info, err)
+// Returns {bridge_id, model, product_name, swversion, api_version, outdated}.
+// api_version is the V1 API version, or nil when the V1 API is unreachable;
+// outdated is true when the firmware predates a reliable event stream.
+func (m *HueModule) bridgeInfo(L *lua.LState) int {
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	info, err := m.v2.GetBridgeInfo(ctx)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	tbl := L.NewTable()
+	tbl.RawSetString("bridge_id", lua.LString(info.BridgeID))
+	tbl.RawSetString("model", lua.LString(info.Model))
+	tbl.RawSetString("product_name", lua.LString(info.ProductName))
+	tbl.RawSetString("swversion", lua.LString(info.SoftwareVersion))
+	tbl.RawSetString("outdated", lua.LBool(info.Outdated()))
+	if cfg, err := m.bridge().GetConfigContext(ctx); err == nil && cfg.APIVersion != "" {
+		tbl.RawSetString("api_version", lua.LString(cfg.APIVersion))
+	}
+
+	L.Push(tbl)
+	L.Push(lua.LNil)
+	return 2
+}
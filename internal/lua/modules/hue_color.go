@@ -0,0 +1,186 @@
+package modules
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// rgbColor is an sRGB color with 0-255 components.
+type rgbColor struct {
+	R, G, B float64
+}
+
+// builtinColors is the palette available via hue.color() without definition.
+var builtinColors = map[string]rgbColor{
+	"warm_white":    {255, 197, 143},
+	"soft_white":    {255, 214, 170},
+	"cool_white":    {212, 235, 255},
+	"daylight":      {255, 249, 253},
+	"candlelight":   {255, 147, 41},
+	"red":           {255, 0, 0},
+	"green":         {0, 255, 0},
+	"blue":          {0, 0, 255},
+	"yellow":        {255, 255, 0},
+	"orange":        {255, 165, 0},
+	"sunset_orange": {253, 94, 83},
+	"purple":        {128, 0, 128},
+	"pink":          {255, 105, 180},
+	"cyan":          {0, 255, 255},
+	"magenta":       {255, 0, 255},
+	"teal":          {0, 128, 128},
+}
+
+// =============================================================================
+// Color conversion helpers
+// =============================================================================
+
+// hueRGBToXY converts sRGB (0-255) to CIE xy
+// hue.rgb_to_xy(r, g, b) -> {x, y}
+// The result can be passed to set_color() or used as set_state({ xy = ... }).
+func hueRGBToXY(L *lua.LState) int {
+	r := clamp255(L.CheckNumber(1))
+	g := clamp255(L.CheckNumber(2))
+	b := clamp255(L.CheckNumber(3))
+
+	x, y := rgbToXY(r, g, b)
+
+	tbl := L.NewTable()
+	tbl.RawSetInt(1, lua.LNumber(x))
+	tbl.RawSetInt(2, lua.LNumber(y))
+	L.Push(tbl)
+	return 1
+}
+
+// hueKelvinToMirek converts a color temperature in Kelvin to mirek
+// hue.kelvin_to_mirek(k) -> number
+func hueKelvinToMirek(L *lua.LState) int {
+	k := float64(L.CheckNumber(1))
+	if k <= 0 {
+		L.ArgError(1, "kelvin must be positive")
+		return 0
+	}
+	L.Push(lua.LNumber(math.Round(1e6 / k)))
+	return 1
+}
+
+// hueMirekToKelvin converts a color temperature in mirek to Kelvin
+// hue.mirek_to_kelvin(m) -> number
+func hueMirekToKelvin(L *lua.LState) int {
+	m := float64(L.CheckNumber(1))
+	if m <= 0 {
+		L.ArgError(1, "mirek must be positive")
+		return 0
+	}
+	L.Push(lua.LNumber(math.Round(1e6 / m)))
+	return 1
+}
+
+// color(name) -> ({x, y}, err)
+// Looks up a user-defined or built-in named color. User-defined colors take precedence.
+func (m *HueModule) color(L *lua.LState) int {
+	name := L.CheckString(1)
+
+	c, ok := m.customColors[name]
+	if !ok {
+		c, ok = builtinColors[name]
+	}
+	if !ok {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(fmt.Sprintf("unknown color %q (available: %s)", name, strings.Join(m.colorNames(), ", "))))
+		return 2
+	}
+
+	x, y := rgbToXY(c.R, c.G, c.B)
+	tbl := L.NewTable()
+	tbl.RawSetInt(1, lua.LNumber(x))
+	tbl.RawSetInt(2, lua.LNumber(y))
+	L.Push(tbl)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// define_color(name, r, g, b)
+// Registers a named color for hue.color(). Overrides built-ins of the same name.
+func (m *HueModule) defineColor(L *lua.LState) int {
+	name := L.CheckString(1)
+	m.customColors[name] = rgbColor{
+		R: clamp255(L.CheckNumber(2)),
+		G: clamp255(L.CheckNumber(3)),
+		B: clamp255(L.CheckNumber(4)),
+	}
+	return 0
+}
+
+// colorNames returns all known color names, sorted.
+func (m *HueModule) colorNames() []string {
+	seen := make(map[string]bool, len(builtinColors)+len(m.customColors))
+	for name := range builtinColors {
+		seen[name] = true
+	}
+	for name := range m.customColors {
+		seen[name] = true
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rgbToXY converts 0-255 sRGB components to CIE xy using sRGB gamma
+// expansion and the wide-gamut D65 matrix. Gamut clamping is left to the bridge.
+func rgbToXY(r, g, b float64) (float64, float64) {
+	red := gammaExpand(r / 255)
+	green := gammaExpand(g / 255)
+	blue := gammaExpand(b / 255)
+
+	X := red*0.664511 + green*0.154324 + blue*0.162028
+	Y := red*0.283881 + green*0.668433 + blue*0.047685
+	Z := red*0.000088 + green*0.072310 + blue*0.986039
+
+	sum := X + Y + Z
+	if sum == 0 {
+		// Black has no chromaticity; use the D65 white point
+		return 0.3127, 0.3290
+	}
+
+	return roundTo(X/sum, 4), roundTo(Y/sum, 4)
+}
+
+// gammaExpand undoes sRGB gamma compression for a 0-1 component.
+func gammaExpand(c float64) float64 {
+	if c > 0.04045 {
+		return math.Pow((c+0.055)/1.055, 2.4)
+	}
+	return c / 12.92
+}
+
+func clamp255(n lua.LNumber) float64 {
+	return math.Max(0, math.Min(255, float64(n)))
+}
+
+func roundTo(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// checkXY reads CIE xy coordinates starting at pos, accepting either two
+// numbers (x, y) or a single {x, y} table as returned by hue.rgb_to_xy().
+func checkXY(L *lua.LState, pos int) (float32, float32) {
+	if tbl, ok := L.Get(pos).(*lua.LTable); ok {
+		x, xok := tbl.RawGetInt(1).(lua.LNumber)
+		y, yok := tbl.RawGetInt(2).(lua.LNumber)
+		if !xok || !yok {
+			L.ArgError(pos, "{x, y} table expected")
+			return 0, 0
+		}
+		return float32(x), float32(y)
+	}
+	return float32(L.CheckNumber(pos)), float32(L.CheckNumber(pos + 1))
+}
@@ -0,0 +1,173 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/amimof/huego"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+	v2 "github.com/dokzlo13/lightd/internal/hue/v2"
+)
+
+// Read-back settings for light:set_state_confirm()
+const (
+	defaultConfirmTimeout = 5 * time.Second
+	confirmPollInterval   = 250 * time.Millisecond
+
+	// Tolerances for comparing the read-back state: the bridge rounds
+	// brightness to V2 percent, clips xy to the light's gamut and snaps ct.
+	confirmBriTolerance = 3    // bri units (1-254)
+	confirmXYTolerance  = 0.01 // CIE xy
+	confirmCTTolerance  = 5    // mirek
+)
+
+// lightSetStateConfirm applies state like set_state, then reads the light
+// back over V2 until the applied fields match or the timeout expires.
+// light:set_state_confirm(state, {timeout = "5s"}?) -> (confirmed, actual, err)
+// Only on, bri, xy and ct are compared; the wait starts after transitiontime.
+// Blocks the Lua worker for at most timeout.
+func lightSetStateConfirm(L *lua.LState) int {
+	light, _ := checkLight(L)
+	tbl := L.CheckTable(2)
+	opts := L.OptTable(3, nil)
+
+	timeout := defaultConfirmTimeout
+	if opts != nil {
+		if v := opts.RawGetString("timeout"); v != lua.LNil {
+			d, err := time.ParseDuration(v.String())
+			if err != nil || d <= 0 {
+				L.ArgError(3, fmt.Sprintf("invalid timeout %q (expected a positive duration like \"5s\")", v.String()))
+				return 0
+			}
+			timeout = d
+		}
+	}
+
+	fail := func(err error) int {
+		L.Push(lua.LFalse)
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 3
+	}
+
+	if light.v2 == nil {
+		return fail(fmt.Errorf("V2 API not available"))
+	}
+
+	logUnknownStateKeys(tbl, "light", light.light.ID)
+	gradient := checkGradient(L, tbl)
+	dynamics := checkDynamics(L, tbl)
+	state, hasState := lightStateFromTable(tbl)
+
+	parent := L.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	id, err := light.v2.LightIDForV1(ctx, light.light.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	if update := v2LightUpdate(tbl, state, gradient, dynamics); update != nil {
+		if err := light.v2.UpdateLight(ctx, id, update); err != nil {
+			return fail(err)
+		}
+	} else if hasState {
+		if err := light.light.SetStateContext(ctx, state); err != nil {
+			return fail(err)
+		}
+	}
+
+	// Let the transition finish before the first read
+	wait := time.Duration(state.TransitionTime) * 100 * time.Millisecond
+	for {
+		select {
+		case <-ctx.Done():
+			return fail(fmt.Errorf("light %d: no read-back within %s", light.light.ID, timeout))
+		case <-time.After(wait):
+		}
+
+		actual, err := light.v2.GetLight(ctx, id)
+		if err == nil && stateApplied(tbl, state, actual) {
+			L.Push(lua.LTrue)
+			L.Push(v2LightState(L, actual))
+			L.Push(lua.LNil)
+			return 3
+		}
+
+		// Report the last read-back once there is no time for another poll
+		if deadline, _ := ctx.Deadline(); time.Until(deadline) < confirmPollInterval {
+			if err != nil {
+				return fail(err)
+			}
+			L.Push(lua.LFalse)
+			L.Push(v2LightState(L, actual))
+			L.Push(lua.LNil)
+			return 3
+		}
+		wait = confirmPollInterval
+	}
+}
+
+// stateApplied reports whether the read-back light matches the on, bri, xy
+// and ct fields set in tbl, within tolerance. Brightness and color are not
+// compared when the light was turned off.
+func stateApplied(tbl *lua.LTable, state huego.State, actual *v2.Light) bool {
+	if tbl.RawGetString("on") != lua.LNil {
+		if actual.On == nil || actual.On.On != state.On {
+			return false
+		}
+		if !state.On {
+			return true
+		}
+	}
+	if state.Bri != 0 {
+		if actual.Dimming == nil || math.Abs(float64(reconcile.PercentToBri(actual.Dimming.Brightness))-float64(state.Bri)) > confirmBriTolerance {
+			return false
+		}
+	}
+	if len(state.Xy) == 2 {
+		if actual.Color == nil ||
+			math.Abs(actual.Color.XY.X-float64(state.Xy[0])) > confirmXYTolerance ||
+			math.Abs(actual.Color.XY.Y-float64(state.Xy[1])) > confirmXYTolerance {
+			return false
+		}
+	}
+	if state.Ct != 0 {
+		if actual.ColorTemperature == nil || !actual.ColorTemperature.MirekValid ||
+			math.Abs(float64(actual.ColorTemperature.Mirek)-float64(state.Ct)) > confirmCTTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+// v2LightState converts a V2 light to {on, bri, bri_pct, xy = {x, y}, ct}, leaving
+// out fields the light doesn't report.
+func v2LightState(L *lua.LState, light *v2.Light) *lua.LTable {
+	tbl := L.NewTable()
+	if light.On != nil {
+		L.SetField(tbl, "on", lua.LBool(light.On.On))
+	}
+	if light.Dimming != nil {
+		L.SetField(tbl, "bri", lua.LNumber(reconcile.PercentToBri(light.Dimming.Brightness)))
+		L.SetField(tbl, "bri_pct", lua.LNumber(light.Dimming.Brightness))
+	}
+	if light.Color != nil {
+		xy := L.NewTable()
+		xy.Append(lua.LNumber(light.Color.XY.X))
+		xy.Append(lua.LNumber(light.Color.XY.Y))
+		L.SetField(tbl, "xy", xy)
+	}
+	if light.ColorTemperature != nil && light.ColorTemperature.MirekValid {
+		L.SetField(tbl, "ct", lua.LNumber(light.ColorTemperature.Mirek))
+	}
+	return tbl
+}
@@ -0,0 +1,71 @@
+package modules
+
+import (
+	"context"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// devices() -> (list, err)
+// Lists the bridge's devices with their service IDs, e.g.
+// {id=..., name="Hallway dimmer", product_name="Hue dimmer switch", room="Hallway",
+//
+//	buttons={...}, rotaries={...}, services={{rid=..., rtype="button"}, ...}}
+//
+// The list is cached and refreshed after device SSE events.
+func (m *HueModule) listDevices(L *lua.LState) int {
+	if m.devices == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("device list not available"))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	devices, err := m.devices.Devices(ctx)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	result := L.NewTable()
+	for _, d := range devices {
+		t := L.NewTable()
+		L.SetField(t, "id", lua.LString(d.ID))
+		L.SetField(t, "name", lua.LString(d.Name))
+		L.SetField(t, "product_name", lua.LString(d.ProductName))
+		L.SetField(t, "model_id", lua.LString(d.ModelID))
+		L.SetField(t, "room", lua.LString(d.Room))
+
+		buttons := L.NewTable()
+		for _, id := range d.Buttons {
+			buttons.Append(lua.LString(id))
+		}
+		L.SetField(t, "buttons", buttons)
+
+		rotaries := L.NewTable()
+		for _, id := range d.Rotaries {
+			rotaries.Append(lua.LString(id))
+		}
+		L.SetField(t, "rotaries", rotaries)
+
+		services := L.NewTable()
+		for _, svc := range d.Services {
+			st := L.NewTable()
+			L.SetField(st, "rid", lua.LString(svc.RID))
+			L.SetField(st, "rtype", lua.LString(svc.RType))
+			services.Append(st)
+		}
+		L.SetField(t, "services", services)
+
+		result.Append(t)
+	}
+
+	L.Push(result)
+	L.Push(lua.LNil)
+	return 2
+}
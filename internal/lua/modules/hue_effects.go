@@ -0,0 +1,92 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+// stopEffectsUpdate halts a running V2 effect (candle, fire, ...) and freezes
+// dynamic scene playback.
+var stopEffectsUpdate = map[string]any{
+	"effects":  map[string]any{"effect": "no_effect"},
+	"dynamics": map[string]any{"speed": 0},
+}
+
+// stopEffects(id, kind?) -> (ok, err)
+// Stops running effects on a light, or on a whole group (one grouped_light
+// update) when kind is "group", and stops any reconciler brightness ramp on
+// it by holding the current brightness as desired. id may be an alias,
+// numeric string or number; kind defaults to "light".
+func (m *HueModule) stopEffects(L *lua.LState) int {
+	var ref string
+	switch v := L.Get(1).(type) {
+	case lua.LString:
+		ref = string(v)
+	case lua.LNumber:
+		ref = strconv.Itoa(int(v))
+	default:
+		L.ArgError(1, "expected string or number")
+		return 0
+	}
+	kind := L.OptString(2, aliasKindLight)
+	if kind != aliasKindLight && kind != aliasKindGroup {
+		L.ArgError(2, `kind must be "light" or "group"`)
+		return 0
+	}
+
+	fail := func(err error) int {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	id, err := m.resolveID(kind, ref)
+	if err != nil {
+		return fail(err)
+	}
+	if m.v2 == nil {
+		return fail(fmt.Errorf("V2 API not available"))
+	}
+
+	parent := L.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, v2StateTimeout)
+	defer cancel()
+
+	resourceKind := reconcile.KindLight
+	if kind == aliasKindGroup {
+		resourceKind = reconcile.KindGroup
+		groupedLightID, err := m.v2.ResolveV1(ctx, "grouped_light", fmt.Sprintf("/groups/%d", id))
+		if err != nil {
+			return fail(err)
+		}
+		if err := m.v2.UpdateGroupedLight(ctx, groupedLightID, stopEffectsUpdate); err != nil {
+			return fail(fmt.Errorf("group %d: %w", id, err))
+		}
+	} else {
+		lightID, err := m.v2.LightIDForV1(ctx, id)
+		if err != nil {
+			return fail(err)
+		}
+		if err := m.v2.UpdateLight(ctx, lightID, stopEffectsUpdate); err != nil {
+			return fail(fmt.Errorf("light %d: %w", id, err))
+		}
+	}
+
+	if m.holdBri != nil {
+		if err := m.holdBri(ctx, resourceKind, strconv.Itoa(id)); err != nil {
+			return fail(err)
+		}
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
@@ -8,6 +8,7 @@ import (
 	lua "github.com/yuin/gopher-lua"
 
 	"github.com/dokzlo13/lightd/internal/hue"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
 const groupTypeName = "hue.group"
@@ -16,6 +17,7 @@ const groupTypeName = "hue.group"
 type GroupUserdata struct {
 	group      *huego.Group
 	sceneIndex *hue.SceneIndex
+	v2         *hue.ResolvingV2Client // for V2-only state (gradient)
 }
 
 // RegisterGroupType registers the hue.group metatable
@@ -26,33 +28,35 @@ func RegisterGroupType(L *lua.LState) {
 
 var groupMethods = map[string]lua.LGFunction{
 	// Getters (return values)
-	"id":        groupGetID,
-	"name":      groupGetName,
-	"is_on":     groupIsOn,
-	"all_on":    groupAllOn,
-	"any_on":    groupAnyOn,
-	"get_bri":   groupGetBri,
-	"get_state": groupGetState,
-	"lights":    groupGetLights,
+	"id":          groupGetID,
+	"name":        groupGetName,
+	"is_on":       groupIsOn,
+	"all_on":      groupAllOn,
+	"any_on":      groupAnyOn,
+	"get_bri":     groupGetBri,
+	"get_bri_pct": groupGetBriPct,
+	"get_state":   groupGetState,
+	"lights":      groupGetLights,
 
 	// Chainable setters (return self for chaining)
-	"on":        groupOn,
-	"off":       groupOff,
-	"toggle":    groupToggle,
-	"set_bri":   groupSetBri,
-	"set_ct":    groupSetCt,
-	"set_scene": groupSetScene,
-	"set_color": groupSetColorXY,
-	"alert":     groupAlert,
+	"on":          groupOn,
+	"off":         groupOff,
+	"toggle":      groupToggle,
+	"set_bri":     groupSetBri,
+	"set_bri_pct": groupSetBriPct,
+	"set_ct":      groupSetCt,
+	"set_scene":   groupSetScene,
+	"set_color":   groupSetColorXY,
+	"alert":       groupAlert,
 
 	// Generic state setter
 	"set_state": groupSetState,
 }
 
 // pushGroup creates a new Group userdata and pushes it onto the stack
-func pushGroup(L *lua.LState, group *huego.Group, sceneIndex *hue.SceneIndex) {
+func pushGroup(L *lua.LState, group *huego.Group, sceneIndex *hue.SceneIndex, v2Client *hue.ResolvingV2Client) {
 	ud := L.NewUserData()
-	ud.Value = &GroupUserdata{group: group, sceneIndex: sceneIndex}
+	ud.Value = &GroupUserdata{group: group, sceneIndex: sceneIndex, v2: v2Client}
 	L.SetMetatable(ud, L.GetTypeMetatable(groupTypeName))
 	L.Push(ud)
 }
@@ -135,8 +139,20 @@ func groupGetBri(L *lua.LState) int {
 	return 1
 }
 
+// groupGetBriPct gets the current brightness as a percentage (0-100)
+// group:get_bri_pct() -> number
+func groupGetBriPct(L *lua.LState) int {
+	group, _ := checkGroup(L)
+	pct := 0.0
+	if group.group.State != nil {
+		pct = reconcile.BriToPercent(group.group.State.Bri)
+	}
+	L.Push(lua.LNumber(pct))
+	return 1
+}
+
 // groupGetState returns the current color state for saving/restoring
-// group:get_state() -> { bri, xy, ct, colormode }
+// group:get_state() -> { bri, bri_pct, xy, ct, colormode }
 func groupGetState(L *lua.LState) int {
 	group, _ := checkGroup(L)
 	tbl := L.NewTable()
@@ -144,6 +160,7 @@ func groupGetState(L *lua.LState) int {
 	if group.group.State != nil {
 		s := group.group.State
 		L.SetField(tbl, "bri", lua.LNumber(s.Bri))
+		L.SetField(tbl, "bri_pct", lua.LNumber(reconcile.BriToPercent(s.Bri)))
 		L.SetField(tbl, "ct", lua.LNumber(s.Ct))
 		L.SetField(tbl, "colormode", lua.LString(s.ColorMode))
 
@@ -242,6 +259,20 @@ func groupSetBri(L *lua.LState) int {
 	return 1
 }
 
+// groupSetBriPct sets the group brightness as a percentage (0-100) (chainable)
+// group:set_bri_pct(value) -> self
+func groupSetBriPct(L *lua.LState) int {
+	group, ud := checkGroup(L)
+	bri := reconcile.PercentToBri(float64(L.CheckNumber(2)))
+
+	err := group.group.Bri(bri)
+	if err != nil {
+		log.Error().Err(err).Int("group", group.group.ID).Uint8("bri", bri).Msg("Failed to set group brightness")
+	}
+	L.Push(ud)
+	return 1
+}
+
 // groupSetCt sets the group color temperature in mirek (chainable)
 // group:set_ct(ct) -> self
 // ct: color temperature in mirek (153-500, lower = cooler/bluer, higher = warmer/yellower)
@@ -274,7 +305,7 @@ func groupSetScene(L *lua.LState) int {
 	groupID := strconv.Itoa(group.group.ID)
 
 	// Find scene by name
-	scene, err := group.sceneIndex.FindByName(sceneName, groupID)
+	scene, err := group.sceneIndex.FindForGroup(sceneName, groupID)
 	if err != nil {
 		log.Error().Err(err).Int("group", group.group.ID).Str("scene", sceneName).Msg("Failed to find scene")
 		L.Push(ud)
@@ -293,10 +324,10 @@ func groupSetScene(L *lua.LState) int {
 
 // groupSetColorXY sets the group color using CIE xy coordinates (chainable)
 // group:set_color(x, y) -> self
+// group:set_color({x, y}) -> self
 func groupSetColorXY(L *lua.LState) int {
 	group, ud := checkGroup(L)
-	x := float32(L.CheckNumber(2))
-	y := float32(L.CheckNumber(3))
+	x, y := checkXY(L, 2)
 
 	err := group.group.Xy([]float32{x, y})
 	if err != nil {
@@ -323,10 +354,16 @@ func groupAlert(L *lua.LState) int {
 
 // groupSetState sets multiple state properties at once (chainable)
 // group:set_state({on = true, bri = 200, hue = 40000, sat = 254, xy = {0.5, 0.4}, ct = 300, scene = "Relax"}) -> self
+// gradient = {{x, y}, ...} is applied to each member light via the V2 API (non-gradient lights ignore it).
+// dynamics.duration (ms) becomes the V1 transition time; dynamics.speed is not supported for groups.
 func groupSetState(L *lua.LState) int {
 	group, ud := checkGroup(L)
 	tbl := L.CheckTable(2)
 
+	logUnknownStateKeys(tbl, "group", group.group.ID, "scene")
+	gradient := checkGradient(L, tbl)
+	dynamics := checkDynamics(L, tbl)
+
 	state := huego.State{}
 	hasState := false
 
@@ -334,7 +371,7 @@ func groupSetState(L *lua.LState) int {
 	if v := tbl.RawGetString("scene"); v != lua.LNil {
 		if sceneName, ok := v.(lua.LString); ok {
 			groupID := strconv.Itoa(group.group.ID)
-			scene, err := group.sceneIndex.FindByName(string(sceneName), groupID)
+			scene, err := group.sceneIndex.FindForGroup(string(sceneName), groupID)
 			if err != nil {
 				log.Error().Err(err).Int("group", group.group.ID).Str("scene", string(sceneName)).Msg("Failed to find scene")
 			} else {
@@ -372,6 +409,14 @@ func groupSetState(L *lua.LState) int {
 		}
 	}
 
+	// bri_pct (percentage, takes precedence over bri)
+	if v := tbl.RawGetString("bri_pct"); v != lua.LNil {
+		if pct, ok := v.(lua.LNumber); ok {
+			state.Bri = reconcile.PercentToBri(float64(pct))
+			hasState = true
+		}
+	}
+
 	// hue
 	if v := tbl.RawGetString("hue"); v != lua.LNil {
 		if hue, ok := v.(lua.LNumber); ok {
@@ -462,6 +507,16 @@ func groupSetState(L *lua.LState) int {
 		}
 	}
 
+	// dynamics (duration maps to V1 transition time in 100ms steps)
+	if dynamics != nil {
+		if dynamics.durationMs != nil && tbl.RawGetString("transitiontime") == lua.LNil {
+			state.TransitionTime = uint16(*dynamics.durationMs / 100)
+		}
+		if dynamics.speed != nil {
+			log.Debug().Int("group", group.group.ID).Msg("Ignoring dynamics.speed for group")
+		}
+	}
+
 	if hasState {
 		err := group.group.SetState(state)
 		if err != nil {
@@ -469,6 +524,20 @@ func groupSetState(L *lua.LState) int {
 		}
 	}
 
+	// gradient (per member light, V2 only)
+	if gradient != nil {
+		update := map[string]any{"gradient": map[string]any{"points": gradient}}
+		for _, lightID := range group.group.Lights {
+			id, err := strconv.Atoi(lightID)
+			if err != nil {
+				continue
+			}
+			if err := updateV2Light(L, group.v2, id, update); err != nil {
+				log.Debug().Err(err).Int("group", group.group.ID).Int("light", id).Msg("Gradient not applied to light")
+			}
+		}
+	}
+
 	L.Push(ud)
 	return 1
 }
@@ -0,0 +1,63 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// rotateKeyTimeout bounds hue.rotate_key(), which talks to the bridge twice
+const rotateKeyTimeout = 30 * time.Second
+
+// rotate_key() -> (ok, err)
+// Creates a new application key (press the bridge link button first), verifies
+// it, writes it to hue.token_file and switches the running clients to it.
+// The old key keeps working until every step succeeded.
+func (m *HueModule) rotateKeyFn(L *lua.LState) int {
+	if m.rotateKey == nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString("key rotation not available"))
+		return 2
+	}
+
+	parent := L.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, rotateKeyTimeout)
+	defer cancel()
+
+	if err := m.rotateKey(ctx); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// clientkey() -> (key, err)
+// Returns the entertainment (DTLS) client key paired with the application key,
+// from hue.clientkey, the token file or the last hue.rotate_key(). It is a
+// secret: pass it to the process that needs it, don't log it.
+func (m *HueModule) clientKeyFn(L *lua.LState) int {
+	if m.clientKey == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("client key not available"))
+		return 2
+	}
+
+	key := m.clientKey()
+	if key == "" {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("no client key configured (set hue.clientkey or call hue.rotate_key())"))
+		return 2
+	}
+
+	L.Push(lua.LString(key))
+	L.Push(lua.LNil)
+	return 2
+}
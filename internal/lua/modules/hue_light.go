@@ -4,13 +4,25 @@ import (
 	"github.com/amimof/huego"
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
 )
 
 const lightTypeName = "hue.light"
 
+// Color temperature range (mirek) of Hue white ambiance bulbs. huego does not
+// expose the per-light capabilities block, so this range is reported for every
+// light that supports color temperature.
+const (
+	defaultMinCT = 153 // 6500K
+	defaultMaxCT = 500 // 2000K
+)
+
 // LightUserdata wraps a huego.Light for Lua access
 type LightUserdata struct {
 	light *huego.Light
+	v2    *hue.ResolvingV2Client // for V2-only state (gradient, dynamics)
 }
 
 // RegisterLightType registers the hue.light metatable
@@ -21,30 +33,43 @@ func RegisterLightType(L *lua.LState) {
 
 var lightMethods = map[string]lua.LGFunction{
 	// Getters (return values)
-	"id":      lightGetID,
-	"name":    lightGetName,
-	"is_on":   lightIsOn,
-	"get_bri": lightGetBri,
+	"id":          lightGetID,
+	"name":        lightGetName,
+	"is_on":       lightIsOn,
+	"get_bri":     lightGetBri,
+	"get_bri_pct": lightGetBriPct,
+
+	"reachable": lightReachable,
+
+	// Capabilities
+	"supports_color": lightSupportsColor,
+	"supports_ct":    lightSupportsCT,
+	"min_ct":         lightMinCT,
+	"max_ct":         lightMaxCT,
 
 	// Chainable setters (return self for chaining)
-	"on":        lightOn,
-	"off":       lightOff,
-	"toggle":    lightToggle,
-	"set_bri":   lightSetBri,
-	"set_color": lightSetColorXY,
-	"set_ct":    lightSetColorTemp,
-	"set_hue":   lightSetHue,
-	"set_sat":   lightSetSat,
-	"alert":     lightAlert,
+	"on":          lightOn,
+	"off":         lightOff,
+	"toggle":      lightToggle,
+	"set_bri":     lightSetBri,
+	"set_bri_pct": lightSetBriPct,
+	"set_color":   lightSetColorXY,
+	"set_ct":      lightSetColorTemp,
+	"set_hue":     lightSetHue,
+	"set_sat":     lightSetSat,
+	"alert":       lightAlert,
 
 	// Generic state setter
 	"set_state": lightSetState,
+
+	// State setter with read-back verification
+	"set_state_confirm": lightSetStateConfirm,
 }
 
 // pushLight creates a new Light userdata and pushes it onto the stack
-func pushLight(L *lua.LState, light *huego.Light) {
+func pushLight(L *lua.LState, light *huego.Light, v2Client *hue.ResolvingV2Client) {
 	ud := L.NewUserData()
-	ud.Value = &LightUserdata{light: light}
+	ud.Value = &LightUserdata{light: light, v2: v2Client}
 	L.SetMetatable(ud, L.GetTypeMetatable(lightTypeName))
 	L.Push(ud)
 }
@@ -95,6 +120,92 @@ func lightGetBri(L *lua.LState) int {
 	return 1
 }
 
+// lightGetBriPct gets the current brightness as a percentage (0-100)
+// light:get_bri_pct() -> number
+func lightGetBriPct(L *lua.LState) int {
+	light, _ := checkLight(L)
+	L.Push(lua.LNumber(reconcile.BriToPercent(light.light.State.Bri)))
+	return 1
+}
+
+// lightReachable returns whether the bridge can currently reach the light.
+// A light switched off at the wall reports false and ignores commands.
+// light:reachable() -> bool
+func lightReachable(L *lua.LState) int {
+	light, _ := checkLight(L)
+	L.Push(lua.LBool(light.light.State.Reachable))
+	return 1
+}
+
+// lightCapabilities reports whether a light supports xy/hue color and color
+// temperature, based on its V1 type. Unknown types fall back to the current
+// color mode, which only reveals the mode the light is in right now.
+func lightCapabilities(light *huego.Light) (color, ct bool) {
+	switch light.Type {
+	case "Extended color light":
+		return true, true
+	case "Color light":
+		return true, false
+	case "Color temperature light":
+		return false, true
+	case "Dimmable light", "On/Off light", "On/Off plug-in unit":
+		return false, false
+	}
+
+	if light.State == nil {
+		return false, false
+	}
+	switch light.State.ColorMode {
+	case "xy", "hs":
+		return true, false
+	case "ct":
+		return false, true
+	}
+	return false, false
+}
+
+// lightSupportsColor returns whether the light accepts xy/hue/sat colors
+// light:supports_color() -> bool
+func lightSupportsColor(L *lua.LState) int {
+	light, _ := checkLight(L)
+	color, _ := lightCapabilities(light.light)
+	L.Push(lua.LBool(color))
+	return 1
+}
+
+// lightSupportsCT returns whether the light accepts color temperature
+// light:supports_ct() -> bool
+func lightSupportsCT(L *lua.LState) int {
+	light, _ := checkLight(L)
+	_, ct := lightCapabilities(light.light)
+	L.Push(lua.LBool(ct))
+	return 1
+}
+
+// lightMinCT returns the coolest supported color temperature in mirek
+// light:min_ct() -> number|nil (nil if color temperature is unsupported)
+func lightMinCT(L *lua.LState) int {
+	light, _ := checkLight(L)
+	if _, ct := lightCapabilities(light.light); !ct {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LNumber(defaultMinCT))
+	return 1
+}
+
+// lightMaxCT returns the warmest supported color temperature in mirek
+// light:max_ct() -> number|nil (nil if color temperature is unsupported)
+func lightMaxCT(L *lua.LState) int {
+	light, _ := checkLight(L)
+	if _, ct := lightCapabilities(light.light); !ct {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(lua.LNumber(defaultMaxCT))
+	return 1
+}
+
 // =============================================================================
 // Chainable Setters (return self for chaining)
 // =============================================================================
@@ -162,12 +273,26 @@ func lightSetBri(L *lua.LState) int {
 	return 1
 }
 
+// lightSetBriPct sets the light brightness as a percentage (0-100) (chainable)
+// light:set_bri_pct(value) -> self
+func lightSetBriPct(L *lua.LState) int {
+	light, ud := checkLight(L)
+	bri := reconcile.PercentToBri(float64(L.CheckNumber(2)))
+
+	err := light.light.Bri(bri)
+	if err != nil {
+		log.Error().Err(err).Int("light", light.light.ID).Uint8("bri", bri).Msg("Failed to set brightness")
+	}
+	L.Push(ud)
+	return 1
+}
+
 // lightSetColorXY sets the light color using CIE xy coordinates (chainable)
 // light:set_color(x, y) -> self
+// light:set_color({x, y}) -> self
 func lightSetColorXY(L *lua.LState) int {
 	light, ud := checkLight(L)
-	x := float32(L.CheckNumber(2))
-	y := float32(L.CheckNumber(3))
+	x, y := checkXY(L, 2)
 
 	err := light.light.Xy([]float32{x, y})
 	if err != nil {
@@ -260,10 +385,37 @@ func lightAlert(L *lua.LState) int {
 
 // lightSetState sets multiple state properties at once (chainable)
 // light:set_state({on = true, bri = 200, hue = 40000, sat = 254, xy = {0.5, 0.4}, ct = 300}) -> self
+// With gradient = {{x, y}, ...} or dynamics = {duration = ms, speed = 0..1}
+// the whole state is sent as one V2 update (hue/sat, alert and effect are ignored).
 func lightSetState(L *lua.LState) int {
 	light, ud := checkLight(L)
 	tbl := L.CheckTable(2)
 
+	logUnknownStateKeys(tbl, "light", light.light.ID)
+	gradient := checkGradient(L, tbl)
+	dynamics := checkDynamics(L, tbl)
+
+	state, hasState := lightStateFromTable(tbl)
+
+	if update := v2LightUpdate(tbl, state, gradient, dynamics); update != nil {
+		if err := updateV2Light(L, light.v2, light.light.ID, update); err != nil {
+			log.Error().Err(err).Int("light", light.light.ID).Msg("Failed to set state (V2)")
+		}
+	} else if hasState {
+		err := light.light.SetState(state)
+		if err != nil {
+			log.Error().Err(err).Int("light", light.light.ID).Msg("Failed to set state")
+		}
+	}
+
+	L.Push(ud)
+	return 1
+}
+
+// lightStateFromTable reads the V1 state keys of a set_state table (on, bri,
+// bri_pct, hue, sat, ct, xy, transitiontime, alert, effect), clamping values
+// to the bridge's ranges. Reports whether any key was set.
+func lightStateFromTable(tbl *lua.LTable) (huego.State, bool) {
 	state := huego.State{}
 	hasState := false
 
@@ -290,6 +442,14 @@ func lightSetState(L *lua.LState) int {
 		}
 	}
 
+	// bri_pct (percentage, takes precedence over bri)
+	if v := tbl.RawGetString("bri_pct"); v != lua.LNil {
+		if pct, ok := v.(lua.LNumber); ok {
+			state.Bri = reconcile.PercentToBri(float64(pct))
+			hasState = true
+		}
+	}
+
 	// hue
 	if v := tbl.RawGetString("hue"); v != lua.LNil {
 		if hue, ok := v.(lua.LNumber); ok {
@@ -380,13 +540,5 @@ func lightSetState(L *lua.LState) int {
 		}
 	}
 
-	if hasState {
-		err := light.light.SetState(state)
-		if err != nil {
-			log.Error().Err(err).Int("light", light.light.ID).Msg("Failed to set state")
-		}
-	}
-
-	L.Push(ud)
-	return 1
+	return state, hasState
 }
@@ -0,0 +1,111 @@
+package modules
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// random_scene(group_id, opts?) -> (scene_name, err)
+// Picks a scene for the group at random and activates it. opts.weights maps
+// scene names to relative weights ({Relax = 3, Read = 1}); scenes with weight
+// 0 are never picked. Without weights, every scene of the group is equally
+// likely.
+func (m *HueModule) randomScene(L *lua.LState) int {
+	ref := L.CheckString(1)
+	opts := L.OptTable(2, nil)
+
+	weights := make(map[string]float64)
+	if opts != nil {
+		if tbl, ok := opts.RawGetString("weights").(*lua.LTable); ok {
+			tbl.ForEach(func(k, v lua.LValue) {
+				n, ok := v.(lua.LNumber)
+				if k.Type() != lua.LTString || !ok || n < 0 {
+					L.ArgError(2, "weights must map scene names to non-negative numbers")
+				}
+				weights[k.String()] = float64(n)
+			})
+		}
+	}
+
+	id, err := m.resolveID(aliasKindGroup, ref)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	groupID := strconv.Itoa(id)
+
+	// Default to equal weights over the group's scenes
+	if len(weights) == 0 {
+		for _, scene := range m.sceneIndex.ForGroup(groupID) {
+			weights[scene.Name] = 1
+		}
+	}
+
+	name, ok := weightedPick(weights)
+	if !ok {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("no scenes to pick from for group " + groupID))
+		return 2
+	}
+
+	scene, err := m.sceneIndex.FindForGroup(name, groupID)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	group, err := m.bridge().GetGroupContext(ctx, id)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	if err := group.SceneContext(ctx, scene.ID); err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	log.Debug().Str("group", groupID).Str("scene", name).Msg("Recalled random scene")
+	L.Push(lua.LString(name))
+	L.Push(lua.LNil)
+	return 2
+}
+
+// weightedPick draws a key with probability proportional to its weight.
+// Returns false if no key has a positive weight.
+func weightedPick(weights map[string]float64) (string, bool) {
+	names := make([]string, 0, len(weights))
+	var total float64
+	for name, w := range weights {
+		if w > 0 {
+			names = append(names, name)
+			total += w
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+
+	r := rand.Float64() * total
+	for _, name := range names {
+		r -= weights[name]
+		if r < 0 {
+			return name, true
+		}
+	}
+	return names[len(names)-1], true
+}
@@ -0,0 +1,34 @@
+package modules
+
+import (
+	"context"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// refresh() -> (ok, err)
+// Reloads the scene index from the bridge and drops the cached device list,
+// picking up scenes and devices created in the Hue app since startup. The
+// refresh is bounded by hue.timeout, so it never holds the Lua worker longer.
+func (m *HueModule) refreshFn(L *lua.LState) int {
+	if m.refresh == nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString("cache refresh not available"))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := m.refresh(ctx); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
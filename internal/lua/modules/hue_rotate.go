@@ -0,0 +1,127 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/storage/kv"
+)
+
+const (
+	// RotateScenesAction is the built-in action behind hue.rotate_scenes, for
+	// use with sched.periodic
+	RotateScenesAction = "hue.rotate_scenes"
+
+	// rotateScenesBucket is the persistent KV bucket holding the last rotated
+	// scene index per group
+	rotateScenesBucket = "hue.rotate_scenes"
+)
+
+// SceneRotator advances a group to its next scene on each call, for ambiance
+// schedules. Unlike button_cycle it leaves groups that are off alone.
+type SceneRotator struct {
+	hue *HueModule
+	kv  *kv.Manager
+}
+
+// NewSceneRotator creates a rotator that recalls scenes through the hue module
+// and remembers positions in KV. It also backs hue.rotate_scenes().
+func NewSceneRotator(hue *HueModule, kvManager *kv.Manager) *SceneRotator {
+	r := &SceneRotator{hue: hue, kv: kvManager}
+	hue.rotator = r
+	return r
+}
+
+// Register adds the built-in RotateScenesAction to the registry.
+func (r *SceneRotator) Register(registry *actions.Registry) error {
+	return registry.RegisterSimple(RotateScenesAction, r.execute)
+}
+
+// execute rotates args.group to the next of args.scenes.
+func (r *SceneRotator) execute(ctx *actions.Context, args map[string]any) error {
+	ref, _ := args["group"].(string)
+	scenes := stringList(args["scenes"])
+	if ref == "" || len(scenes) == 0 {
+		return fmt.Errorf("rotate_scenes: group and scenes are required")
+	}
+	if _, err := r.rotate(ctx.Ctx(), ref, scenes); err != nil {
+		return fmt.Errorf("rotate_scenes: %w", err)
+	}
+	return nil
+}
+
+// rotate recalls the next scene for the group, or does nothing if it is off.
+func (r *SceneRotator) rotate(ctx context.Context, ref string, scenes []string) (string, error) {
+	return r.hue.cycleScene(ctx, r.kv.Bucket(rotateScenesBucket, true), ref, scenes, false)
+}
+
+// rotate_scenes(group, scenes) -> (scene_name, err)
+// Recalls the scene after the last one rotated to for the group, wrapping
+// around. Returns nil (and no error) without touching the group if it is off.
+func (m *HueModule) rotateScenesFn(L *lua.LState) int {
+	ref := L.CheckString(1)
+	tbl := L.CheckTable(2)
+
+	scenes := make([]string, 0, tbl.Len())
+	for i := 1; i <= tbl.Len(); i++ {
+		name, ok := tbl.RawGetInt(i).(lua.LString)
+		if !ok {
+			L.ArgError(2, fmt.Sprintf("scenes[%d] must be a string", i))
+			return 0
+		}
+		scenes = append(scenes, string(name))
+	}
+	if len(scenes) == 0 {
+		L.ArgError(2, "scenes must be a non-empty list of scene names")
+		return 0
+	}
+
+	if m.rotator == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("scene rotation not available"))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	name, err := m.rotator.rotate(ctx, ref, scenes)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+	if name == "" {
+		L.Push(lua.LNil)
+		L.Push(lua.LNil)
+		return 2
+	}
+	L.Push(lua.LString(name))
+	L.Push(lua.LNil)
+	return 2
+}
+
+// stringList converts action args holding a list of strings, as passed from
+// Go ([]string) or from a Lua table ([]any).
+func stringList(v any) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil
+			}
+			out = append(out, s)
+		}
+		return out
+	}
+	return nil
+}
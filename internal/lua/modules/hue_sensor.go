@@ -0,0 +1,49 @@
+package modules
+
+import (
+	"context"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue"
+)
+
+// sensor(id) -> ({temperature, motion, light_level}, err)
+// Polls the current sensor values through the V2 API. id is a device ID or a
+// sensor service ID; fields the sensor doesn't provide are nil.
+func (m *HueModule) sensor(L *lua.LState) int {
+	id := L.CheckString(1)
+
+	if m.v2 == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("V2 client not available"))
+		return 2
+	}
+
+	ctx := L.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reading, err := hue.ReadSensorV2(ctx, m.v2.Client, id)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	result := L.NewTable()
+	if reading.Temperature != nil {
+		L.SetField(result, "temperature", lua.LNumber(*reading.Temperature))
+	}
+	if reading.Motion != nil {
+		L.SetField(result, "motion", lua.LBool(*reading.Motion))
+	}
+	if reading.LightLevel != nil {
+		L.SetField(result, "light_level", lua.LNumber(*reading.LightLevel))
+	}
+
+	L.Push(result)
+	L.Push(lua.LNil)
+	return 2
+}
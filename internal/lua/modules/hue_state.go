@@ -0,0 +1,240 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/amimof/huego"
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue"
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+// Gradient point limits accepted by the bridge for gradient-capable lights
+const (
+	minGradientPoints = 2
+	maxGradientPoints = 5
+)
+
+// v2StateTimeout bounds V2 requests issued from set_state
+const v2StateTimeout = 10 * time.Second
+
+// stateKeys are the keys understood by set_state(); anything else is ignored.
+var stateKeys = map[string]bool{
+	"on":             true,
+	"bri":            true,
+	"bri_pct":        true,
+	"hue":            true,
+	"sat":            true,
+	"ct":             true,
+	"xy":             true,
+	"transitiontime": true,
+	"alert":          true,
+	"effect":         true,
+	"gradient":       true,
+	"dynamics":       true,
+}
+
+// logUnknownStateKeys logs (at debug) set_state keys that will be ignored.
+// extra lists additional keys accepted by the caller (e.g. "scene" for groups).
+func logUnknownStateKeys(tbl *lua.LTable, kind string, id int, extra ...string) {
+	tbl.ForEach(func(k, _ lua.LValue) {
+		if key, ok := k.(lua.LString); ok {
+			if stateKeys[string(key)] || slices.Contains(extra, string(key)) {
+				return
+			}
+		}
+		log.Debug().Str("key", k.String()).Int(kind, id).Msg("Ignoring unknown set_state key")
+	})
+}
+
+// stateDynamics holds the parsed dynamics = {duration = ms, speed = n} table.
+type stateDynamics struct {
+	durationMs *int
+	speed      *float64
+}
+
+// checkGradient reads gradient = {{x, y}, ...} from tbl.
+// Returns nil if absent. Raises an argument error on malformed input.
+func checkGradient(L *lua.LState, tbl *lua.LTable) []map[string]any {
+	v := tbl.RawGetString("gradient")
+	if v == lua.LNil {
+		return nil
+	}
+
+	pointsTbl, ok := v.(*lua.LTable)
+	if !ok {
+		L.ArgError(2, "gradient must be a table of {x, y} points")
+		return nil
+	}
+
+	n := pointsTbl.Len()
+	if n < minGradientPoints || n > maxGradientPoints {
+		L.ArgError(2, fmt.Sprintf("gradient needs %d-%d points, got %d", minGradientPoints, maxGradientPoints, n))
+		return nil
+	}
+
+	points := make([]map[string]any, 0, n)
+	for i := 1; i <= n; i++ {
+		point, ok := pointsTbl.RawGetInt(i).(*lua.LTable)
+		if !ok {
+			L.ArgError(2, fmt.Sprintf("gradient point %d must be an {x, y} table", i))
+			return nil
+		}
+		x, xok := point.RawGetInt(1).(lua.LNumber)
+		y, yok := point.RawGetInt(2).(lua.LNumber)
+		if !xok || !yok {
+			L.ArgError(2, fmt.Sprintf("gradient point %d must be an {x, y} table", i))
+			return nil
+		}
+		points = append(points, map[string]any{
+			"color": map[string]any{
+				"xy": map[string]any{"x": float64(x), "y": float64(y)},
+			},
+		})
+	}
+
+	return points
+}
+
+// checkDynamics reads dynamics = {duration = ms, speed = n} from tbl.
+// Returns nil if absent.
+func checkDynamics(L *lua.LState, tbl *lua.LTable) *stateDynamics {
+	v := tbl.RawGetString("dynamics")
+	if v == lua.LNil {
+		return nil
+	}
+
+	dynTbl, ok := v.(*lua.LTable)
+	if !ok {
+		L.ArgError(2, "dynamics must be a table {duration = ms, speed = n}")
+		return nil
+	}
+
+	d := &stateDynamics{}
+	if n, ok := dynTbl.RawGetString("duration").(lua.LNumber); ok {
+		ms := int(n)
+		if ms < 0 {
+			ms = 0
+		}
+		d.durationMs = &ms
+	}
+	if n, ok := dynTbl.RawGetString("speed").(lua.LNumber); ok {
+		speed := float64(n)
+		if speed < 0 {
+			speed = 0
+		}
+		if speed > 1 {
+			speed = 1
+		}
+		d.speed = &speed
+	}
+	return d
+}
+
+// v2Update builds the V2 light update body for gradient and dynamics.
+// Returns nil if there is nothing to send.
+func (d *stateDynamics) v2Update(gradient []map[string]any) map[string]any {
+	update := map[string]any{}
+	if gradient != nil {
+		update["gradient"] = map[string]any{"points": gradient}
+	}
+	if d != nil && (d.durationMs != nil || d.speed != nil) {
+		dyn := map[string]any{}
+		if d.durationMs != nil {
+			dyn["duration"] = *d.durationMs
+		}
+		if d.speed != nil {
+			dyn["speed"] = *d.speed
+		}
+		update["dynamics"] = dyn
+	}
+	if len(update) == 0 {
+		return nil
+	}
+	return update
+}
+
+// v2LightUpdate merges the V1 keys of a light set_state table into the
+// gradient/dynamics update so the whole state is sent as one V2 request.
+// transitiontime becomes dynamics.duration unless a duration is given.
+// Returns nil if there is no gradient or dynamics (use the V1 path).
+func v2LightUpdate(tbl *lua.LTable, state huego.State, gradient []map[string]any, dynamics *stateDynamics) map[string]any {
+	update := dynamics.v2Update(gradient)
+	if update == nil {
+		return nil
+	}
+
+	var on *bool
+	if v, ok := tbl.RawGetString("on").(lua.LBool); ok {
+		b := bool(v)
+		on = &b
+	}
+	var bri *uint8
+	if state.Bri != 0 {
+		bri = &state.Bri
+	}
+	var ct *uint16
+	if state.Ct != 0 {
+		ct = &state.Ct
+	}
+	maps.Copy(update, reconcile.V2StateUpdate(on, bri, state.Xy, ct))
+
+	if _, ok := tbl.RawGetString("transitiontime").(lua.LNumber); ok && (dynamics == nil || dynamics.durationMs == nil) {
+		dyn, _ := update["dynamics"].(map[string]any)
+		if dyn == nil {
+			dyn = map[string]any{}
+			update["dynamics"] = dyn
+		}
+		dyn["duration"] = int(state.TransitionTime) * 100
+	}
+
+	for _, key := range []string{"hue", "sat", "alert", "effect"} {
+		if tbl.RawGetString(key) != lua.LNil {
+			log.Debug().Str("key", key).Msg("Ignoring set_state key: not supported by V2 API")
+		}
+	}
+
+	return update
+}
+
+// updateV2Light applies a V2 update to the light with the given V1 ID.
+func updateV2Light(L *lua.LState, client *hue.ResolvingV2Client, v1ID int, update map[string]any) error {
+	if client == nil {
+		return fmt.Errorf("V2 API not available")
+	}
+
+	parent := L.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, v2StateTimeout)
+	defer cancel()
+
+	id, err := client.LightIDForV1(ctx, v1ID)
+	if err != nil {
+		return err
+	}
+	return client.UpdateLight(ctx, id, update)
+}
+
+// hueBri254ToPct converts V1 brightness (1-254) to a percentage (0-100)
+// hue.bri254_to_pct(bri) -> number
+func hueBri254ToPct(L *lua.LState) int {
+	bri := math.Min(254, math.Max(0, float64(L.CheckNumber(1))))
+	L.Push(lua.LNumber(reconcile.BriToPercent(uint8(math.Round(bri)))))
+	return 1
+}
+
+// huePctToBri254 converts a percentage (0-100) to V1 brightness (1-254)
+// hue.pct_to_bri254(pct) -> number
+func huePctToBri254(L *lua.LState) int {
+	L.Push(lua.LNumber(reconcile.PercentToBri(float64(L.CheckNumber(1)))))
+	return 1
+}
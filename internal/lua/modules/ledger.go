@@ -0,0 +1,90 @@
+package modules
+
+import (
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// defaultLedgerLimit is the number of entries returned when no limit is given
+const defaultLedgerLimit = 50
+
+// LedgerModule provides read access to the action/schedule ledger, e.g. to
+// audit slow or failing automations.
+//
+// ERROR HANDLING CONVENTION:
+//   - recent(), range(): Return (entries, nil) or (nil, error_string)
+type LedgerModule struct {
+	ledger *storage.Ledger
+}
+
+// NewLedgerModule creates a new ledger module.
+func NewLedgerModule(ledger *storage.Ledger) *LedgerModule {
+	return &LedgerModule{ledger: ledger}
+}
+
+// Loader is the module loader for Lua.
+func (m *LedgerModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "recent", L.NewFunction(m.recent))
+	L.SetField(mod, "range", L.NewFunction(m.between))
+
+	L.Push(mod)
+	return 1
+}
+
+// recent(event_type, limit?) -> (entries, err)
+// Returns the newest entries of a type ("action_completed", "action_failed",
+// "action_suppressed", "schedule_fired"), newest first.
+func (m *LedgerModule) recent(L *lua.LState) int {
+	eventType := L.CheckString(1)
+	limit := L.OptInt(2, defaultLedgerLimit)
+
+	entries, err := m.ledger.GetByType(storage.EventType(eventType), limit)
+	return m.pushEntries(L, entries, err)
+}
+
+// range(start, end, limit?) -> (entries, err)
+// Returns entries between two Unix timestamps (inclusive), newest first.
+func (m *LedgerModule) between(L *lua.LState) int {
+	start := L.CheckInt64(1)
+	end := L.CheckInt64(2)
+	limit := L.OptInt(3, defaultLedgerLimit)
+
+	entries, err := m.ledger.GetByTimeRange(time.Unix(start, 0), time.Unix(end, 0), limit)
+	return m.pushEntries(L, entries, err)
+}
+
+// pushEntries pushes entries as an array of
+// { id, type, time, source, key, def_id, payload, duration_ms }.
+// duration_ms is nil for entries that don't record one.
+func (m *LedgerModule) pushEntries(L *lua.LState, entries []*storage.Entry, err error) int {
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	tbl := L.NewTable()
+	for _, e := range entries {
+		entry := L.NewTable()
+		entry.RawSetString("id", lua.LNumber(e.ID))
+		entry.RawSetString("type", lua.LString(e.EventType))
+		entry.RawSetString("time", lua.LNumber(e.Timestamp.Unix()))
+		entry.RawSetString("source", lua.LString(e.Source))
+		entry.RawSetString("key", lua.LString(e.IdempotencyKey))
+		entry.RawSetString("def_id", lua.LString(e.DefID))
+		entry.RawSetString("payload", MapToLuaTable(L, e.Payload))
+		if d, ok := e.Duration(); ok {
+			entry.RawSetString("duration_ms", lua.LNumber(d.Milliseconds()))
+		}
+		tbl.Append(entry)
+	}
+
+	L.Push(tbl)
+	L.Push(lua.LNil)
+	return 2
+}
@@ -0,0 +1,80 @@
+package modules
+
+import (
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+
+	modeevents "github.com/dokzlo13/lightd/internal/events/mode"
+	"github.com/dokzlo13/lightd/internal/mode"
+)
+
+// ModeModule provides the mode Lua module for the global automation mode.
+//
+// ERROR HANDLING CONVENTION:
+//   - get(): Always returns the current mode
+//   - set(): Returns (ok, error_string); ok is true even if the mode was already active
+//   - on_change(): Raises on invalid arguments (setup-time API)
+type ModeModule struct {
+	manager  *mode.Manager
+	handlers []modeevents.Handler
+}
+
+// NewModeModule creates a new mode module
+func NewModeModule(manager *mode.Manager) *ModeModule {
+	return &ModeModule{manager: manager}
+}
+
+// Loader is the module loader for Lua
+func (m *ModeModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "get", L.NewFunction(m.get))
+	L.SetField(mod, "set", L.NewFunction(m.set))
+	L.SetField(mod, "on_change", L.NewFunction(m.onChange))
+
+	L.Push(mod)
+	return 1
+}
+
+// get() -> string
+func (m *ModeModule) get(L *lua.LState) int {
+	L.Push(lua.LString(m.manager.Get()))
+	return 1
+}
+
+// set(name) -> (ok, err)
+// Persists the mode and emits a mode_change event if it changed.
+func (m *ModeModule) set(L *lua.LState) int {
+	name := L.CheckString(1)
+
+	if _, err := m.manager.Set(name); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// on_change(action_name, args) - Register an action to run when the mode changes
+// The action receives args.mode and args.previous merged with the static args.
+func (m *ModeModule) onChange(L *lua.LState) int {
+	actionName := L.CheckString(1)
+	argsTable := L.OptTable(2, L.NewTable())
+
+	m.handlers = append(m.handlers, modeevents.Handler{
+		ActionName: actionName,
+		ActionArgs: LuaTableToMap(argsTable),
+	})
+
+	log.Debug().Str("action", actionName).Msg("Registered mode change handler")
+	return 0
+}
+
+// GetChangeHandlers returns all registered mode change handlers.
+// Implements the modeevents.HandlerRegistry interface.
+func (m *ModeModule) GetChangeHandlers() []modeevents.Handler {
+	return m.handlers
+}
@@ -0,0 +1,74 @@
+package modules
+
+import (
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/hue/reconcile"
+)
+
+// ReconcileModule provides reconcile.* controls over the reconciliation loop.
+//
+// ERROR HANDLING CONVENTION:
+//   - pause(), resume(), is_paused(): Never fail; pause/resume return nothing
+//   - set_rate_limit(): Raises on invalid arguments, returns nothing
+type ReconcileModule struct {
+	orchestrator *reconcile.Orchestrator
+}
+
+// NewReconcileModule creates a new reconcile module
+func NewReconcileModule(orchestrator *reconcile.Orchestrator) *ReconcileModule {
+	return &ReconcileModule{orchestrator: orchestrator}
+}
+
+// Loader is the module loader for Lua
+func (m *ReconcileModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "pause", L.NewFunction(m.pause))
+	L.SetField(mod, "resume", L.NewFunction(m.resume))
+	L.SetField(mod, "is_paused", L.NewFunction(m.isPaused))
+	L.SetField(mod, "set_rate_limit", L.NewFunction(m.setRateLimit))
+
+	L.Push(mod)
+	return 1
+}
+
+// pause()
+// Stops enforcing desired state; changes keep accumulating until resume().
+func (m *ReconcileModule) pause(L *lua.LState) int {
+	if m.orchestrator != nil {
+		m.orchestrator.Pause()
+	}
+	return 0
+}
+
+// resume()
+// Re-enables reconciliation and applies everything queued while paused.
+func (m *ReconcileModule) resume(L *lua.LState) int {
+	if m.orchestrator != nil {
+		m.orchestrator.Resume()
+	}
+	return 0
+}
+
+// is_paused() -> bool
+func (m *ReconcileModule) isPaused(L *lua.LState) int {
+	L.Push(lua.LBool(m.orchestrator != nil && m.orchestrator.Paused()))
+	return 1
+}
+
+// set_rate_limit(key, rps)
+// Adds a rate limit for a kind ("group") or a resource ("group:3") on top of
+// the global one, like reconciler.rate_limits. rps <= 0 removes it.
+func (m *ReconcileModule) setRateLimit(L *lua.LState) int {
+	key := L.CheckString(1)
+	rps := float64(L.CheckNumber(2))
+	if key == "" {
+		L.ArgError(1, "key must not be empty")
+		return 0
+	}
+	if m.orchestrator != nil {
+		m.orchestrator.SetRateLimit(key, rps)
+	}
+	return 0
+}
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -15,6 +16,7 @@ import (
 // ERROR HANDLING CONVENTION:
 //   - define(), periodic(), disable(): Use L.RaiseError() for critical setup failures
 //   - run_closest(), run(): Returns (ok, error_string) for runtime operations
+//   - eval(): Returns (result, error_string)
 type SchedModule struct {
 	scheduler *scheduler.Scheduler
 	enabled   bool
@@ -47,6 +49,10 @@ func (m *SchedModule) Loader(L *lua.LState) int {
 	L.SetField(mod, "list", L.NewFunction(m.list))
 	L.SetField(mod, "get_closest", L.NewFunction(m.getClosest))
 	L.SetField(mod, "run", L.NewFunction(m.run))
+	L.SetField(mod, "fired_today", L.NewFunction(m.firedToday))
+
+	// Expression evaluation without registering a schedule
+	L.SetField(mod, "eval", L.NewFunction(m.eval))
 
 	L.Push(mod)
 	return 1
@@ -54,7 +60,12 @@ func (m *SchedModule) Loader(L *lua.LState) int {
 
 // define(id, time_expr, action_name, args, opts) - Register a daily schedule definition
 // opts.tag: optional tag for grouping schedules
-// opts.replay: whether to replay on boot (default: true). Set to false to skip boot recovery.
+// opts.replay: whether to replay on boot (default: true). Set to false to skip boot recovery,
+// or "all" to replay every missed occurrence since the last fire.
+// opts.polar_fallback: fixed time (e.g. "22:00") used when an astronomical event
+// does not occur at high latitudes, instead of skipping the day.
+// opts.sync = true invokes the action directly from the scheduler instead of via
+// the event bus (see Scheduler.SetSync).
 func (m *SchedModule) define(L *lua.LState) int {
 	id := L.CheckString(1)
 	timeExpr := L.CheckString(2)
@@ -80,22 +91,41 @@ func (m *SchedModule) define(L *lua.LState) int {
 				misfirePolicy = scheduler.MisfirePolicySkip
 			}
 		case lua.LString:
-			if string(v) == "never" || string(v) == "false" {
+			switch string(v) {
+			case "never", "false":
 				misfirePolicy = scheduler.MisfirePolicySkip
+			case "all":
+				misfirePolicy = scheduler.MisfirePolicyRunAll
 			}
 		}
 	}
 
-	if err := m.scheduler.Define(id, timeExpr, actionName, args, tag, misfirePolicy); err != nil {
+	polarFallback := ""
+	if f := optsTable.RawGetString("polar_fallback"); f != lua.LNil {
+		polarFallback = f.String()
+	}
+
+	if err := m.scheduler.Define(id, timeExpr, actionName, args, tag, misfirePolicy, polarFallback); err != nil {
 		L.RaiseError("failed to define schedule: %s", err.Error())
 		return 0
 	}
+	m.scheduler.SetSync(id, lua.LVAsBool(optsTable.RawGetString("sync")))
 
 	return 0
 }
 
 // periodic(id, interval, action_name, args, opts) - Register a periodic schedule
 // interval is a duration string like "30m", "1h", "5s"
+// opts.align = true fires on clock boundaries (e.g. "1h" on the hour) instead of
+// counting from registration time
+// opts.min_gap_from = tag with opts.gap = "10m" delays ticks that fall within gap
+// of an occurrence of a schedule with that tag
+// opts.sync = true invokes the action directly from the scheduler (as in define)
+// opts.max_runs = N unregisters the schedule after N runs
+// opts.until = time expression (e.g. "23:00", "@sunrise") unregisters it at the
+// next occurrence of that time
+// Both bounds are counted from registration; they are not persisted, so a
+// restart or script reload starts over.
 func (m *SchedModule) periodic(L *lua.LState) int {
 	id := L.CheckString(1)
 	intervalStr := L.CheckString(2)
@@ -117,14 +147,46 @@ func (m *SchedModule) periodic(L *lua.LState) int {
 	if t := optsTable.RawGetString("tag"); t != lua.LNil {
 		tag = t.String()
 	}
+	align := lua.LVAsBool(optsTable.RawGetString("align"))
+
+	var minGap scheduler.MinGap
+	if from := optsTable.RawGetString("min_gap_from"); from != lua.LNil {
+		minGap.Tag = from.String()
+		gapStr := lua.LVAsString(optsTable.RawGetString("gap"))
+		minGap.Gap, err = time.ParseDuration(gapStr)
+		if err != nil || minGap.Gap <= 0 {
+			L.RaiseError("invalid gap %q for min_gap_from (expected a positive duration like \"10m\")", gapStr)
+			return 0
+		}
+	}
+
+	var bound scheduler.PeriodicBound
+	if n := optsTable.RawGetString("max_runs"); n != lua.LNil {
+		num, ok := n.(lua.LNumber)
+		if !ok || num < 1 {
+			L.RaiseError("invalid max_runs %s (expected a positive number)", n.String())
+			return 0
+		}
+		bound.MaxRuns = int(num)
+	}
+	if u := optsTable.RawGetString("until"); u != lua.LNil {
+		bound.Until = u.String()
+	}
 
-	m.scheduler.DefinePeriodic(id, interval, actionName, args, tag)
+	if err := m.scheduler.DefinePeriodic(id, interval, actionName, args, tag, align, minGap, bound); err != nil {
+		L.RaiseError("failed to define periodic schedule: %s", err.Error())
+		return 0
+	}
+	m.scheduler.SetSync(id, lua.LVAsBool(optsTable.RawGetString("sync")))
 
 	log.Debug().
 		Str("id", id).
 		Dur("interval", interval).
 		Str("action", actionName).
 		Str("tag", tag).
+		Bool("align", align).
+		Int("max_runs", bound.MaxRuns).
+		Str("until", bound.Until).
 		Msg("Periodic schedule registered")
 
 	return 0
@@ -132,6 +194,8 @@ func (m *SchedModule) periodic(L *lua.LState) int {
 
 // run_closest(opts) -> (ok, err)
 // Runs the closest schedule matching criteria. Uses NO idempotency key (always runs).
+// opts accepts {tag, tags, strategy}; strategy is case-insensitive, so a webhook's
+// ctx.request.json can be passed through directly.
 func (m *SchedModule) runClosest(L *lua.LState) int {
 	optsTable := L.CheckTable(1)
 
@@ -149,12 +213,22 @@ func (m *SchedModule) runClosest(L *lua.LState) int {
 	}
 
 	// Parse strategy
-	strategy := scheduler.StrategyNext
+	strategyName := ""
 	if s := optsTable.RawGetString("strategy"); s != lua.LNil {
-		strategy = scheduler.Strategy(s.String())
+		strategyName = s.String()
+	}
+	strategy, err := scheduler.ParseStrategy(strategyName)
+	if err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
 	}
 
-	m.scheduler.RunClosest(tags, strategy)
+	if err := m.scheduler.RunClosest(tags, strategy); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
 
 	L.Push(lua.LBool(true))
 	L.Push(lua.LNil)
@@ -199,6 +273,15 @@ func (m *SchedModule) run(L *lua.LState) int {
 	return 2
 }
 
+// fired_today(id) -> bool
+// Returns whether the schedule already ran on schedule today (scheduler timezone).
+// Manual runs via sched.run() and sched.run_closest() are not counted.
+func (m *SchedModule) firedToday(L *lua.LState) int {
+	id := L.CheckString(1)
+	L.Push(lua.LBool(m.scheduler.FiredToday(id)))
+	return 1
+}
+
 // get_closest(opts) -> { id, action, tag, time } or nil
 // Returns the closest schedule matching criteria without running it.
 // opts.tag: filter by tag (optional)
@@ -232,6 +315,43 @@ func (m *SchedModule) getClosest(L *lua.LState) int {
 	return 1
 }
 
+// eval(time_expr) -> ({ next, next_ts, today, today_ts }, err)
+// Evaluates a time expression without registering a schedule.
+// today/today_ts are nil when the expression has no occurrence today (e.g. polar skip).
+func (m *SchedModule) eval(L *lua.LState) int {
+	exprStr := L.CheckString(1)
+
+	expr, err := scheduler.ParseTimeExpr(exprStr)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	evaluator := m.scheduler.Evaluator()
+	if expr.IsAstronomical() && !evaluator.SupportsAstronomical() {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(fmt.Sprintf("astronomical time expression %q requires geo to be enabled (events.scheduler.geo.enabled: true)", exprStr)))
+		return 2
+	}
+
+	now := time.Now().In(evaluator.Timezone())
+	tbl := L.NewTable()
+
+	if next, ok := evaluator.ComputeNextOccurrence(expr, now); ok {
+		L.SetField(tbl, "next", lua.LString(next.Format("2006-01-02 15:04:05")))
+		L.SetField(tbl, "next_ts", lua.LNumber(next.Unix()))
+	}
+	if today, ok := evaluator.Evaluate(expr, now); ok {
+		L.SetField(tbl, "today", lua.LString(today.Format("15:04:05")))
+		L.SetField(tbl, "today_ts", lua.LNumber(today.Unix()))
+	}
+
+	L.Push(tbl)
+	L.Push(lua.LNil)
+	return 2
+}
+
 // print(opts) - Print the current schedule
 // opts.format: "today" (default) or "tomorrow"
 func (m *SchedModule) print(L *lua.LState) int {
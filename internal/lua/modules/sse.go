@@ -2,7 +2,10 @@
 package modules
 
 import (
+	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	glua "github.com/yuin/gopher-lua"
@@ -61,6 +64,13 @@ func (m *SSEModule) Loader(L *glua.LState) int {
 	L.SetField(mod, "connectivity", L.NewFunction(m.connectivity))
 	L.SetField(mod, "rotary", L.NewFunction(m.rotary))
 	L.SetField(mod, "light_change", L.NewFunction(m.lightChange))
+	L.SetField(mod, "button_cycle", L.NewFunction(m.buttonCycle))
+
+	// Always true here: the module can only be loaded when SSE is enabled
+	L.SetField(mod, "is_enabled", L.NewFunction(func(L *glua.LState) int {
+		L.Push(glua.LTrue)
+		return 1
+	}))
 
 	// Unbind functions
 	L.SetField(mod, "unbind_button", L.NewFunction(m.unbindButton))
@@ -110,6 +120,56 @@ func (m *SSEModule) button(L *glua.LState) int {
 	return 0
 }
 
+// button_cycle(resource_id, button_action, group, opts) - Register a button
+// handler that recalls the next of opts.scenes in group on each press, wrapping
+// around. The position is kept per group in KV; when the group is off the
+// cycle starts over from the first scene. group may be an alias or ID.
+// Remove it with unbind_button like any other button handler.
+func (m *SSEModule) buttonCycle(L *glua.LState) int {
+	resourceID := L.CheckString(1)
+	buttonAction := L.CheckString(2)
+	group := L.CheckString(3)
+	optsTable := L.CheckTable(4)
+
+	scenesTable, ok := optsTable.RawGetString("scenes").(*glua.LTable)
+	if !ok || scenesTable.Len() == 0 {
+		L.ArgError(4, "scenes must be a non-empty list of scene names")
+		return 0
+	}
+	scenes := make([]string, 0, scenesTable.Len())
+	for i := 1; i <= scenesTable.Len(); i++ {
+		name, ok := scenesTable.RawGetInt(i).(glua.LString)
+		if !ok {
+			L.ArgError(4, fmt.Sprintf("scenes[%d] must be a string", i))
+			return 0
+		}
+		scenes = append(scenes, string(name))
+	}
+
+	m.mu.Lock()
+	m.buttonHandlers = append(m.buttonHandlers, sse.ButtonHandler{
+		ResourceID:   sse.ParseMatcher(resourceID),
+		ButtonAction: sse.ParseMatcher(buttonAction),
+		ActionName:   ButtonCycleAction,
+		ActionArgs: map[string]any{
+			"group":  group,
+			"scenes": scenes,
+		},
+	})
+	m.mu.Unlock()
+
+	m.notifyHandlersChanged()
+
+	log.Debug().
+		Str("resource_id", resourceID).
+		Str("button_action", buttonAction).
+		Str("group", group).
+		Strs("scenes", scenes).
+		Msg("Registered button cycle handler")
+
+	return 0
+}
+
 // unbind_button(resource_id, button_action?) - Remove button handlers
 // If button_action is omitted or "*", removes all handlers for the resource_id
 func (m *SSEModule) unbindButton(L *glua.LState) int {
@@ -146,6 +206,9 @@ func (m *SSEModule) unbindButton(L *glua.LState) int {
 }
 
 // connectivity(device_id, status, action_name, args) - Register a connectivity handler
+// Optional args.middleware sets the collector middleware
+// Optional args.dedupe_window = "30s" runs the action at most once per device,
+// status and time window (off by default)
 func (m *SSEModule) connectivity(L *glua.LState) int {
 	deviceID := L.CheckString(1)
 	status := L.CheckString(2)
@@ -161,6 +224,9 @@ func (m *SSEModule) connectivity(L *glua.LState) int {
 		delete(args, "middleware")
 	}
 
+	dedupeWindow := checkDedupeWindow(L, argsTable, 4)
+	delete(args, "dedupe_window")
+
 	m.mu.Lock()
 	m.connectivityHandlers = append(m.connectivityHandlers, sse.ConnectivityHandler{
 		DeviceID:         sse.ParseMatcher(deviceID),
@@ -168,6 +234,7 @@ func (m *SSEModule) connectivity(L *glua.LState) int {
 		ActionName:       actionName,
 		ActionArgs:       args,
 		CollectorFactory: factory,
+		DedupeWindow:     dedupeWindow,
 	})
 	m.mu.Unlock()
 
@@ -219,6 +286,10 @@ func (m *SSEModule) unbindConnectivity(L *glua.LState) int {
 // rotary(resource_id, action_name, args) - Register a rotary handler
 // The action will receive direction and steps in args
 // Optional args.middleware sets the collector middleware
+// Optional args.target = {target = "light"|"group", id = "5"} names the resource
+// the dial controls; it is passed to the action as args.target.
+// Optional args.dedupe_window = "1s" runs the action at most once per dial,
+// direction and time window (off by default)
 func (m *SSEModule) rotary(L *glua.LState) int {
 	resourceID := L.CheckString(1)
 	actionName := L.CheckString(2)
@@ -233,12 +304,37 @@ func (m *SSEModule) rotary(L *glua.LState) int {
 		delete(args, "middleware")
 	}
 
+	// Extract and validate target
+	var target *sse.RotaryTarget
+	if tv := argsTable.RawGetString("target"); tv != glua.LNil {
+		tbl, ok := tv.(*glua.LTable)
+		if !ok {
+			L.ArgError(3, "target must be a table {target = \"light\"|\"group\", id = \"...\"}")
+			return 0
+		}
+		var err error
+		target, err = sse.ParseRotaryTarget(
+			glua.LVAsString(tbl.RawGetString("target")),
+			glua.LVAsString(tbl.RawGetString("id")),
+		)
+		if err != nil {
+			L.ArgError(3, err.Error())
+			return 0
+		}
+		delete(args, "target")
+	}
+
+	dedupeWindow := checkDedupeWindow(L, argsTable, 3)
+	delete(args, "dedupe_window")
+
 	m.mu.Lock()
 	m.rotaryHandlers = append(m.rotaryHandlers, sse.RotaryHandler{
 		ResourceID:       sse.ParseMatcher(resourceID),
+		Target:           target,
 		ActionName:       actionName,
 		ActionArgs:       args,
 		CollectorFactory: factory,
+		DedupeWindow:     dedupeWindow,
 	})
 	m.mu.Unlock()
 
@@ -285,6 +381,10 @@ func (m *SSEModule) unbindRotary(L *glua.LState) int {
 // resource_id: Light resource ID, "*" for all, or "id1|id2" for multiple
 // Optional args.resource_type: "light", "grouped_light", "*" (default), or "light|grouped_light"
 // Optional args.middleware sets the collector middleware
+// Optional args.throttle_ms runs the action at most once per window across all
+// matching resources, with the latest event's values
+// Optional args.priority orders handlers matching the same event (higher first,
+// default 0); equal priorities run in registration order
 // The action will receive: resource_id, resource_type, brightness, power, color_temp_mirek, etc.
 func (m *SSEModule) lightChange(L *glua.LState) int {
 	resourceIDPattern := L.CheckString(1)
@@ -307,6 +407,20 @@ func (m *SSEModule) lightChange(L *glua.LState) int {
 		delete(args, "middleware")
 	}
 
+	throttle := checkThrottle(L, argsTable, 3)
+	delete(args, "throttle_ms")
+
+	priority := 0
+	if v := argsTable.RawGetString("priority"); v != glua.LNil {
+		n, ok := v.(glua.LNumber)
+		if !ok {
+			L.ArgError(3, fmt.Sprintf("invalid priority %q (expected a number)", v.String()))
+			return 0
+		}
+		priority = int(n)
+		delete(args, "priority")
+	}
+
 	m.mu.Lock()
 	m.lightChangeHandlers = append(m.lightChangeHandlers, sse.LightChangeHandler{
 		ResourceID:       sse.ParseMatcher(resourceIDPattern),
@@ -314,6 +428,8 @@ func (m *SSEModule) lightChange(L *glua.LState) int {
 		ActionName:       actionName,
 		ActionArgs:       args,
 		CollectorFactory: factory,
+		Throttle:         throttle,
+		Priority:         priority,
 	})
 	m.mu.Unlock()
 
@@ -459,5 +575,42 @@ func (m *SSEModule) FindLightChangeHandlers(resourceID, resourceType string) []*
 			matches = append(matches, &result)
 		}
 	}
+
+	// Dispatch order: higher priority first, then registration order
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Priority > matches[j].Priority
+	})
 	return matches
 }
+
+// checkDedupeWindow reads args.dedupe_window as a duration string.
+// Returns 0 (no dedupe) if absent. Raises an argument error if invalid.
+func checkDedupeWindow(L *glua.LState, argsTable *glua.LTable, argPos int) time.Duration {
+	v := argsTable.RawGetString("dedupe_window")
+	if v == glua.LNil {
+		return 0
+	}
+
+	window, err := time.ParseDuration(glua.LVAsString(v))
+	if err != nil || window <= 0 {
+		L.ArgError(argPos, fmt.Sprintf("invalid dedupe_window %q (expected a positive duration like \"30s\")", v.String()))
+		return 0
+	}
+	return window
+}
+
+// checkThrottle reads args.throttle_ms as a positive number of milliseconds.
+// Returns 0 (no throttling) if absent. Raises an argument error if invalid.
+func checkThrottle(L *glua.LState, argsTable *glua.LTable, argPos int) time.Duration {
+	v := argsTable.RawGetString("throttle_ms")
+	if v == glua.LNil {
+		return 0
+	}
+
+	ms, ok := v.(glua.LNumber)
+	if !ok || ms <= 0 {
+		L.ArgError(argPos, fmt.Sprintf("invalid throttle_ms %q (expected a positive number of milliseconds)", v.String()))
+		return 0
+	}
+	return time.Duration(float64(ms) * float64(time.Millisecond))
+}
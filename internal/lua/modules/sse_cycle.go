@@ -0,0 +1,104 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/storage/kv"
+)
+
+const (
+	// ButtonCycleAction is the built-in action behind events.sse.button_cycle
+	ButtonCycleAction = "sse.button_cycle"
+
+	// buttonCycleBucket is the persistent KV bucket holding the last scene
+	// index per group
+	buttonCycleBucket = "sse.button_cycle"
+)
+
+// ButtonCycler activates the next scene of a button_cycle handler on each press.
+type ButtonCycler struct {
+	hue *HueModule
+	kv  *kv.Manager
+}
+
+// NewButtonCycler creates a cycler that recalls scenes through the hue module
+// and remembers positions in KV.
+func NewButtonCycler(hue *HueModule, kvManager *kv.Manager) *ButtonCycler {
+	return &ButtonCycler{hue: hue, kv: kvManager}
+}
+
+// Register adds the built-in ButtonCycleAction to the registry.
+func (c *ButtonCycler) Register(registry *actions.Registry) error {
+	return registry.RegisterSimple(ButtonCycleAction, c.execute)
+}
+
+// execute recalls the scene after the last one recalled for the group,
+// wrapping around. If the group is off (e.g. it was turned off by another
+// switch or the app), the cycle restarts from the first scene.
+func (c *ButtonCycler) execute(ctx *actions.Context, args map[string]any) error {
+	ref, _ := args["group"].(string)
+	scenes := stringList(args["scenes"])
+	if ref == "" || len(scenes) == 0 {
+		return fmt.Errorf("button_cycle: group and scenes are required")
+	}
+
+	bucket := c.kv.Bucket(buttonCycleBucket, true)
+	if _, err := c.hue.cycleScene(ctx.Ctx(), bucket, ref, scenes, true); err != nil {
+		return fmt.Errorf("button_cycle: %w", err)
+	}
+	return nil
+}
+
+// cycleScene recalls the scene after the last one recalled for the group,
+// wrapping around, and remembers its position in bucket. When the group is
+// off, the cycle restarts from the first scene if restartWhenOff is set and
+// is left alone otherwise. Returns the recalled scene name, or "" if skipped.
+func (m *HueModule) cycleScene(ctx context.Context, bucket kv.Bucket, ref string, scenes []string, restartWhenOff bool) (string, error) {
+	id, err := m.resolveID(aliasKindGroup, ref)
+	if err != nil {
+		return "", err
+	}
+	groupID := strconv.Itoa(id)
+
+	group, err := m.bridge().GetGroupContext(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("group %s: %w", groupID, err)
+	}
+
+	on := group.GroupState != nil && group.GroupState.AnyOn
+	if !on && !restartWhenOff {
+		log.Debug().Str("group", groupID).Msg("Group is off, not cycling scene")
+		return "", nil
+	}
+
+	next := 0
+	if on {
+		last, err := bucket.Get(groupID)
+		if err != nil {
+			return "", err
+		}
+		switch n := last.(type) {
+		case float64: // decoded from JSON
+			next = (int(n) + 1) % len(scenes)
+		case int:
+			next = (n + 1) % len(scenes)
+		}
+	}
+
+	name := scenes[next]
+	scene, err := m.sceneIndex.FindForGroup(name, groupID)
+	if err != nil {
+		return "", err
+	}
+	if err := group.SceneContext(ctx, scene.ID); err != nil {
+		return "", fmt.Errorf("recall %q: %w", name, err)
+	}
+
+	log.Debug().Str("group", groupID).Str("scene", name).Int("index", next).Msg("Cycled scene")
+	return name, bucket.Store(groupID, next, nil)
+}
@@ -0,0 +1,162 @@
+package modules
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// StoreModule provides low-level access to the versioned resource state store.
+// Unlike kv, every write bumps the (kind, id) version counter, which is the
+// same counter the reconciler uses for dirty tracking.
+//
+// ERROR HANDLING CONVENTION:
+//   - get(), version(), ids(): Return nil/0/empty on missing entries, log on failure
+//   - set(), delete(): Return (ok, error_string)
+type StoreModule struct {
+	store *storage.Store
+}
+
+// NewStoreModule creates a new store module.
+func NewStoreModule(store *storage.Store) *StoreModule {
+	return &StoreModule{store: store}
+}
+
+// Loader is the module loader for Lua.
+func (m *StoreModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "get", L.NewFunction(m.get))
+	L.SetField(mod, "set", L.NewFunction(m.set))
+	L.SetField(mod, "version", L.NewFunction(m.version))
+	L.SetField(mod, "delete", L.NewFunction(m.delete))
+	L.SetField(mod, "ids", L.NewFunction(m.ids))
+
+	L.Push(mod)
+	return 1
+}
+
+// get(kind, id) -> (value, version)
+// Returns nil, 0 if the entry does not exist.
+func (m *StoreModule) get(L *lua.LState) int {
+	L.CheckTable(1) // self
+	kind := L.CheckString(2)
+	id := L.CheckString(3)
+
+	payload, version, err := m.store.Get(kind, id)
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to read store entry")
+		L.Push(lua.LNil)
+		L.Push(lua.LNumber(0))
+		return 2
+	}
+
+	if payload == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LNumber(0))
+		return 2
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		log.Warn().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to decode store entry")
+		L.Push(lua.LNil)
+		L.Push(lua.LNumber(version))
+		return 2
+	}
+
+	L.Push(GoToLuaValue(L, value))
+	L.Push(lua.LNumber(version))
+	return 2
+}
+
+// set(kind, id, value) -> (ok, err)
+// Stores value as JSON and increments the entry's version.
+func (m *StoreModule) set(L *lua.LState) int {
+	L.CheckTable(1) // self
+	kind := L.CheckString(2)
+	id := L.CheckString(3)
+	value := LuaToGo(L.Get(4))
+
+	payload, err := json.Marshal(value)
+	if err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	if err := m.store.Set(kind, id, payload); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// version(kind, id) -> number
+// Returns 0 if the entry does not exist.
+func (m *StoreModule) version(L *lua.LState) int {
+	L.CheckTable(1) // self
+	kind := L.CheckString(2)
+	id := L.CheckString(3)
+
+	_, version, err := m.store.Get(kind, id)
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Str("id", id).Msg("Failed to read store entry")
+	}
+
+	L.Push(lua.LNumber(version))
+	return 1
+}
+
+// delete(kind, id) -> (ok, err)
+func (m *StoreModule) delete(L *lua.LState) int {
+	L.CheckTable(1) // self
+	kind := L.CheckString(2)
+	id := L.CheckString(3)
+
+	if err := m.store.Delete(kind, id); err != nil {
+		L.Push(lua.LFalse)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	L.Push(lua.LTrue)
+	L.Push(lua.LNil)
+	return 2
+}
+
+// ids(kind) -> table
+// Returns all IDs stored under kind, sorted.
+func (m *StoreModule) ids(L *lua.LState) int {
+	L.CheckTable(1) // self
+	kind := L.CheckString(2)
+
+	_, versions, err := m.store.GetAll(kind)
+	if err != nil {
+		log.Warn().Err(err).Str("kind", kind).Msg("Failed to list store entries")
+		L.Push(L.NewTable())
+		return 1
+	}
+
+	ids := make([]string, 0, len(versions))
+	for id := range versions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	tbl := L.NewTable()
+	for i, id := range ids {
+		tbl.RawSetInt(i+1, lua.LString(id))
+	}
+
+	L.Push(tbl)
+	return 1
+}
@@ -0,0 +1,246 @@
+package modules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/actions"
+	"github.com/dokzlo13/lightd/internal/scheduler"
+)
+
+// SystemModule provides system.* introspection functions to Lua.
+type SystemModule struct {
+	registry  *actions.Registry
+	scheduler *scheduler.Scheduler // nil when the scheduler is disabled
+	sse       *SSEModule
+	webhook   *WebhookModule
+	dashboard func() map[string]any // nil if unsupported
+
+	// Lifecycle hooks, in registration order (only touched from the Lua worker)
+	onStart []*lua.LFunction
+	onStop  []*lua.LFunction
+}
+
+// NewSystemModule creates a new system module
+func NewSystemModule(
+	registry *actions.Registry,
+	sched *scheduler.Scheduler,
+	sseModule *SSEModule,
+	webhookModule *WebhookModule,
+	dashboard func() map[string]any,
+) *SystemModule {
+	return &SystemModule{
+		registry:  registry,
+		scheduler: sched,
+		sse:       sseModule,
+		webhook:   webhookModule,
+		dashboard: dashboard,
+	}
+}
+
+// Loader is the module loader for Lua
+func (m *SystemModule) Loader(L *lua.LState) int {
+	mod := L.NewTable()
+
+	L.SetField(mod, "summary", L.NewFunction(m.summary))
+	L.SetField(mod, "on_start", L.NewFunction(m.registerOnStart))
+	L.SetField(mod, "on_stop", L.NewFunction(m.registerOnStop))
+	L.SetField(mod, "validate", L.NewFunction(m.validate))
+	L.SetField(mod, "dashboard_state", L.NewFunction(m.dashboardState))
+
+	L.Push(mod)
+	return 1
+}
+
+// summary() -> { actions, schedules, sse, webhooks }
+// Returns what the script has registered so far.
+func (m *SystemModule) summary(L *lua.LState) int {
+	L.Push(MapToLuaTable(L, m.Summary()))
+	return 1
+}
+
+// dashboard_state() -> { mode, reconciler, next_by_tag, groups, event_stream, actual_at }
+// Returns a snapshot for home dashboards built from cached state only.
+func (m *SystemModule) dashboardState(L *lua.LState) int {
+	if m.dashboard == nil {
+		L.RaiseError("system.dashboard_state is not available")
+		return 0
+	}
+	L.Push(MapToLuaTable(L, m.dashboard()))
+	return 1
+}
+
+// on_start(fn)
+// Registers fn to run once all services are up and the bridge is connected.
+func (m *SystemModule) registerOnStart(L *lua.LState) int {
+	m.onStart = append(m.onStart, L.CheckFunction(1))
+	return 0
+}
+
+// on_stop(fn)
+// Registers fn to run during graceful shutdown, after queued work has drained.
+func (m *SystemModule) registerOnStop(L *lua.LState) int {
+	m.onStop = append(m.onStop, L.CheckFunction(1))
+	return 0
+}
+
+// validate()
+// Checks that every SSE, webhook and schedule handler registered so far
+// references a defined action. Each dangling reference is logged and the
+// call raises, so a typo fails at load time instead of on the first event.
+// Call it at the end of the script, after all actions are defined.
+func (m *SystemModule) validate(L *lua.LState) int {
+	dangling := m.Validate()
+	if len(dangling) == 0 {
+		return 0
+	}
+	for _, d := range dangling {
+		log.Error().Msg("Handler references an undefined action: " + d)
+	}
+	L.RaiseError("system.validate: %d handler(s) reference undefined actions: %s",
+		len(dangling), strings.Join(dangling, "; "))
+	return 0
+}
+
+// Validate returns a description of every registered handler whose action is
+// not in the registry, sorted. Empty when all references resolve.
+func (m *SystemModule) Validate() []string {
+	var dangling []string
+	check := func(handler, action string) {
+		if _, ok := m.registry.Get(action); !ok {
+			dangling = append(dangling, fmt.Sprintf("%s -> %q", handler, action))
+		}
+	}
+
+	for _, h := range m.sse.GetButtonHandlers() {
+		check(fmt.Sprintf("sse.button %s %s", h.ResourceID, h.ButtonAction), h.ActionName)
+	}
+	for _, h := range m.sse.GetRotaryHandlers() {
+		check(fmt.Sprintf("sse.rotary %s", h.ResourceID), h.ActionName)
+	}
+	for _, h := range m.sse.GetConnectivityHandlers() {
+		check(fmt.Sprintf("sse.connectivity %s %s", h.DeviceID, h.Status), h.ActionName)
+	}
+	for _, h := range m.sse.GetLightChangeHandlers() {
+		check(fmt.Sprintf("sse.light_change %s %s", h.ResourceID, h.ResourceType), h.ActionName)
+	}
+	for _, h := range m.webhook.GetHandlers() {
+		if h.Respond != nil {
+			continue
+		}
+		check(fmt.Sprintf("webhook %s %s", h.Method, h.Path), h.ActionName)
+	}
+	if m.scheduler != nil {
+		for id, action := range m.scheduler.ActionNames() {
+			check("schedule "+id, action)
+		}
+	}
+
+	sort.Strings(dangling)
+	return dangling
+}
+
+// RunStartHooks calls the on_start hooks. Must be called from the Lua worker.
+func (m *SystemModule) RunStartHooks(L *lua.LState) {
+	runHooks(L, "on_start", m.onStart)
+}
+
+// RunStopHooks calls the on_stop hooks. Must be called from the goroutine
+// that owns L, with no other Lua work running.
+func (m *SystemModule) RunStopHooks(L *lua.LState) {
+	runHooks(L, "on_stop", m.onStop)
+}
+
+// runHooks calls each hook in order; a failing hook is logged and does not
+// stop the others.
+func runHooks(L *lua.LState, name string, hooks []*lua.LFunction) {
+	for i, fn := range hooks {
+		if err := L.CallByParam(lua.P{Fn: fn, NRet: 0, Protect: true}); err != nil {
+			log.Error().Err(err).Str("hook", name).Int("index", i+1).Msg("Lifecycle hook failed")
+		}
+	}
+	if len(hooks) > 0 {
+		log.Info().Str("hook", name).Int("count", len(hooks)).Msg("Ran lifecycle hooks")
+	}
+}
+
+// registrations is a snapshot of what the script has registered.
+type registrations struct {
+	actions   []string
+	schedules []string
+	sse       map[string]any
+	webhooks  []string
+}
+
+func (m *SystemModule) collect() registrations {
+	actionNames := m.registry.List()
+
+	var scheduleIDs []string
+	if m.scheduler != nil {
+		scheduleIDs = m.scheduler.IDs()
+	}
+
+	var routes []string
+	for _, h := range m.webhook.GetHandlers() {
+		routes = append(routes, fmt.Sprintf("%s %s", h.Method, h.Path))
+	}
+	sort.Strings(routes)
+
+	return registrations{
+		actions:   actionNames,
+		schedules: scheduleIDs,
+		sse: map[string]any{
+			"button":       len(m.sse.GetButtonHandlers()),
+			"rotary":       len(m.sse.GetRotaryHandlers()),
+			"connectivity": len(m.sse.GetConnectivityHandlers()),
+			"light_change": len(m.sse.GetLightChangeHandlers()),
+		},
+		webhooks: routes,
+	}
+}
+
+// Summary returns registered actions, schedules, SSE handler counts and webhook routes.
+func (m *SystemModule) Summary() map[string]any {
+	r := m.collect()
+	return map[string]any{
+		"actions":   toAnySlice(r.actions),
+		"schedules": toAnySlice(r.schedules),
+		"sse":       r.sse,
+		"webhooks":  toAnySlice(r.webhooks),
+	}
+}
+
+// LogSummary logs the registration summary at info level.
+func (m *SystemModule) LogSummary() {
+	r := m.collect()
+
+	log.Info().
+		Int("actions", len(r.actions)).
+		Int("schedules", len(r.schedules)).
+		Interface("sse_handlers", r.sse).
+		Int("webhooks", len(r.webhooks)).
+		Msg("Registered automations")
+
+	log.Info().Msg("Actions: " + joinOrNone(r.actions))
+	log.Info().Msg("Schedules: " + joinOrNone(r.schedules))
+	log.Info().Msg("Webhooks: " + joinOrNone(r.webhooks))
+}
+
+func toAnySlice(items []string) []any {
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result
+}
+
+func joinOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
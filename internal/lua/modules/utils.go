@@ -1,17 +1,38 @@
 package modules
 
 import (
+	"hash/fnv"
+	"math/rand/v2"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
+
+	"github.com/dokzlo13/lightd/internal/storage/kv"
 )
 
+// crossedBucket is the persistent KV bucket holding threshold states for crossed()
+const crossedBucket = "utils.crossed"
+
 // UtilsModule provides utility functions to Lua
-type UtilsModule struct{}
+//
+// ERROR HANDLING CONVENTION:
+//   - crossed(): Returns (edge, err); err is set if the state can't be read or saved
+//   - Other functions never fail
+type UtilsModule struct {
+	tz *time.Location // calendar days for pick_daily
+	kv *kv.Manager    // remembers threshold states for crossed
+}
 
-// NewUtilsModule creates a new utils module
-func NewUtilsModule() *UtilsModule {
-	return &UtilsModule{}
+// NewUtilsModule creates a new utils module. timezone is the scheduler
+// timezone, used to decide which calendar day it is.
+func NewUtilsModule(timezone string, kvManager *kv.Manager) *UtilsModule {
+	tz, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Warn().Err(err).Str("timezone", timezone).Msg("Failed to load timezone for utils, using UTC")
+		tz = time.UTC
+	}
+	return &UtilsModule{tz: tz, kv: kvManager}
 }
 
 // Loader is the module loader for Lua
@@ -19,6 +40,11 @@ func (m *UtilsModule) Loader(L *lua.LState) int {
 	mod := L.NewTable()
 
 	L.SetField(mod, "sleep", L.NewFunction(m.sleep))
+	L.SetField(mod, "pick", L.NewFunction(m.pick))
+	L.SetField(mod, "pick_daily", L.NewFunction(m.pickDaily))
+	L.SetField(mod, "c_to_f", L.NewFunction(utilsCToF))
+	L.SetField(mod, "f_to_c", L.NewFunction(utilsFToC))
+	L.SetField(mod, "crossed", L.NewFunction(m.crossed))
 
 	L.Push(mod)
 	return 1
@@ -32,3 +58,113 @@ func (m *UtilsModule) sleep(L *lua.LState) int {
 	return 0
 }
 
+// pick(list) -> element
+// Returns a random element of list, or nil if it is empty.
+func (m *UtilsModule) pick(L *lua.LState) int {
+	list := L.CheckTable(1)
+	n := list.Len()
+	if n == 0 {
+		L.Push(lua.LNil)
+		return 1
+	}
+	L.Push(list.RawGetInt(rand.IntN(n) + 1))
+	return 1
+}
+
+// pick_daily(list, salt?) -> element
+// Returns an element of list that stays the same for the whole calendar day
+// (scheduler timezone), including across restarts. Different salts give
+// independent picks on the same day. Returns nil if list is empty.
+func (m *UtilsModule) pickDaily(L *lua.LState) int {
+	list := L.CheckTable(1)
+	salt := L.OptString(2, "")
+	n := list.Len()
+	if n == 0 {
+		L.Push(lua.LNil)
+		return 1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(time.Now().In(m.tz).Format("2006-01-02")))
+	h.Write([]byte(salt))
+	rng := rand.New(rand.NewPCG(h.Sum64(), uint64(n)))
+
+	L.Push(list.RawGetInt(rng.IntN(n) + 1))
+	return 1
+}
+
+// c_to_f(celsius) -> fahrenheit
+func utilsCToF(L *lua.LState) int {
+	c := float64(L.CheckNumber(1))
+	L.Push(lua.LNumber(c*9/5 + 32))
+	return 1
+}
+
+// f_to_c(fahrenheit) -> celsius
+func utilsFToC(L *lua.LState) int {
+	f := float64(L.CheckNumber(1))
+	L.Push(lua.LNumber((f - 32) * 5 / 9))
+	return 1
+}
+
+// crossed(key, value, threshold, opts?) -> ("rising"|"falling"|nil, err)
+// Reports whether value crossed threshold since the last call with the same
+// key. opts.hysteresis is the width of a band centred on threshold: rising
+// needs value >= threshold + hysteresis/2, falling value <= threshold -
+// hysteresis/2, so readings hovering near the threshold don't chatter. The
+// first call for a key only records which side value is on. States persist in
+// KV, so they survive restarts.
+func (m *UtilsModule) crossed(L *lua.LState) int {
+	key := L.CheckString(1)
+	value := float64(L.CheckNumber(2))
+	threshold := float64(L.CheckNumber(3))
+	half := 0.0
+	if opts := L.OptTable(4, nil); opts != nil {
+		if h, ok := opts.RawGetString("hysteresis").(lua.LNumber); ok && h > 0 {
+			half = float64(h) / 2
+		}
+	}
+
+	bucket := m.kv.Bucket(crossedBucket, true)
+	prev, err := bucket.Get(key)
+	if err != nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString(err.Error()))
+		return 2
+	}
+
+	state, edge := "", ""
+	switch prev {
+	case "above":
+		state = "above"
+		if value <= threshold-half {
+			state, edge = "below", "falling"
+		}
+	case "below":
+		state = "below"
+		if value >= threshold+half {
+			state, edge = "above", "rising"
+		}
+	default:
+		state = "below"
+		if value >= threshold {
+			state = "above"
+		}
+	}
+
+	if state != prev {
+		if err := bucket.Store(key, state, nil); err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+	}
+
+	if edge == "" {
+		L.Push(lua.LNil)
+	} else {
+		L.Push(lua.LString(edge))
+	}
+	L.Push(lua.LNil)
+	return 2
+}
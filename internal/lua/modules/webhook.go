@@ -31,6 +31,7 @@ func (m *WebhookModule) Loader(L *glua.LState) int {
 	mod := L.NewTable()
 
 	L.SetField(mod, "define", L.NewFunction(m.define))
+	L.SetField(mod, "respond", L.NewFunction(m.respond))
 
 	L.Push(mod)
 	return 1
@@ -69,6 +70,28 @@ func (m *WebhookModule) define(L *glua.LState) int {
 	return 0
 }
 
+// respond(method, path, fn) - Register a handler whose return value is the HTTP response
+// fn(request) runs while the request waits and returns body[, status]: a table is sent as
+// JSON, a string as text, nil as an empty response.
+func (m *WebhookModule) respond(L *glua.LState) int {
+	method := L.CheckString(1)
+	path := L.CheckString(2)
+	fn := L.CheckFunction(3)
+
+	m.handlers = append(m.handlers, webhook.Handler{
+		Method:  method,
+		Path:    path,
+		Respond: fn,
+	})
+
+	log.Info().
+		Str("method", method).
+		Str("path", path).
+		Msg("Registered webhook responder")
+
+	return 0
+}
+
 // GetHandlers returns all registered webhook handlers
 func (m *WebhookModule) GetHandlers() []webhook.Handler {
 	return m.handlers
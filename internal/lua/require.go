@@ -0,0 +1,92 @@
+package lua
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// installScriptLoader makes require() resolve Lua files under baseDir only.
+// require("rooms/bedroom") and require("rooms.bedroom") both load
+// baseDir/rooms/bedroom.lua (or baseDir/rooms/bedroom/init.lua). Names that
+// resolve outside baseDir are rejected. Preloaded Go modules are unaffected.
+func installScriptLoader(L *lua.LState, baseDir string) error {
+	base, err := filepath.Abs(baseDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve scripts directory: %w", err)
+	}
+
+	pkg, ok := L.GetGlobal("package").(*lua.LTable)
+	if !ok {
+		return fmt.Errorf("lua package library not loaded")
+	}
+	loaders, ok := L.GetField(pkg, "loaders").(*lua.LTable)
+	if !ok {
+		return fmt.Errorf("package.loaders must be a table")
+	}
+
+	// Keep package.path informative for scripts that inspect it
+	L.SetField(pkg, "path", lua.LString(
+		filepath.Join(base, "?.lua")+";"+filepath.Join(base, "?", "init.lua"),
+	))
+
+	// Slot 2 is the default file loader; replace it with the sandboxed one
+	loaders.RawSetInt(2, L.NewFunction(func(L *lua.LState) int {
+		name := L.CheckString(1)
+
+		candidates, err := scriptCandidates(base, name)
+		if err != nil {
+			L.RaiseError("require %q: %s", name, err.Error())
+			return 0
+		}
+
+		var tried []string
+		for _, path := range candidates {
+			if _, err := os.Stat(path); err != nil {
+				tried = append(tried, fmt.Sprintf("no file '%s'", path))
+				continue
+			}
+			fn, err := L.LoadFile(path)
+			if err != nil {
+				L.RaiseError("%s", err.Error())
+				return 0
+			}
+			L.Push(fn)
+			return 1
+		}
+
+		L.Push(lua.LString(strings.Join(tried, "\n\t")))
+		return 1
+	}))
+
+	return nil
+}
+
+// scriptCandidates returns the files a module name may resolve to under base.
+// Returns an error if the name escapes base.
+func scriptCandidates(base, name string) ([]string, error) {
+	if name == "" || filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return nil, fmt.Errorf("module name must be relative to the scripts directory")
+	}
+
+	rel := strings.TrimSuffix(name, ".lua")
+	if !strings.Contains(rel, "/") {
+		rel = strings.ReplaceAll(rel, ".", "/")
+	}
+	rel = filepath.FromSlash(rel)
+
+	candidates := []string{
+		filepath.Join(base, rel+".lua"),
+		filepath.Join(base, rel, "init.lua"),
+	}
+	for _, path := range candidates {
+		within, err := filepath.Rel(base, path)
+		if err != nil || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("module path escapes the scripts directory")
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,48 @@
+package lua
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	glua "github.com/yuin/gopher-lua"
+)
+
+func TestScriptLoader_RejectsPathTraversal(t *testing.T) {
+	root := t.TempDir()
+	scripts := filepath.Join(root, "scripts")
+	write := func(path, src string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(scripts, "rooms", "bedroom.lua"), `return "bedroom"`)
+	write(filepath.Join(root, "secret.lua"), `return "secret"`)
+
+	L := glua.NewState()
+	defer L.Close()
+	if err := installScriptLoader(L, scripts); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := L.DoString(`assert(require("rooms/bedroom") == "bedroom")`); err != nil {
+		t.Fatalf("require inside scripts dir: %v", err)
+	}
+
+	for _, name := range []string{
+		"../secret",
+		"rooms/../../secret",
+		filepath.Join(root, "secret"),
+		"/etc/passwd",
+	} {
+		err := L.DoString(`require("` + filepath.ToSlash(name) + `")`)
+		if err == nil || !strings.Contains(err.Error(), "scripts directory") {
+			t.Errorf("require(%q) error = %v, want it rejected as outside the scripts directory", name, err)
+		}
+	}
+}
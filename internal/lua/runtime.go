@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 
 	"github.com/rs/zerolog/log"
 	lua "github.com/yuin/gopher-lua"
@@ -34,6 +35,9 @@ type Runtime struct {
 	kvModule      *modules.KVModule
 	sseModule     *modules.SSEModule
 	webhookModule *modules.WebhookModule
+	systemModule  *modules.SystemModule
+	modeModule    *modules.ModeModule
+	eventsModule  *modules.EventsModule
 
 	// Work queue for thread-safe Lua execution
 	workQueue chan LuaWork
@@ -42,6 +46,10 @@ type Runtime struct {
 	// Using a channel in select is race-free (unlike mutex + bool)
 	closing   chan struct{}
 	closeOnce sync.Once
+
+	// Worker lifecycle: stopped is closed when Run returns
+	running atomic.Bool
+	stopped chan struct{}
 }
 
 // NewRuntime creates a new Lua runtime
@@ -53,6 +61,7 @@ func NewRuntime(deps RuntimeDeps) *Runtime {
 		deps:      deps,
 		workQueue: make(chan LuaWork, 100),
 		closing:   make(chan struct{}),
+		stopped:   make(chan struct{}),
 	}
 
 	r.registerModules()
@@ -72,6 +81,39 @@ func (r *Runtime) Close() {
 	r.L.Close()
 }
 
+// Shutdown stops accepting work, waits for the worker to drain its queue
+// (bounded by ctx), runs the on_stop hooks, then closes the Lua state. Unlike
+// Close, it never closes the state while queued work is still running.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	r.closeOnce.Do(func() {
+		close(r.closing)
+	})
+
+	if r.running.Load() {
+		select {
+		case <-r.stopped:
+		case <-ctx.Done():
+			log.Warn().Msg("Lua worker did not drain before shutdown timeout")
+			return ctx.Err()
+		}
+	}
+
+	// The worker has exited, so the state is ours: run on_stop hooks before closing it
+	r.L.SetContext(ctx)
+	r.systemModule.RunStopHooks(r.L)
+
+	r.L.Close()
+	return nil
+}
+
+// RunStartHooks queues the script's system.on_start hooks on the Lua worker.
+// Call once all services are started.
+func (r *Runtime) RunStartHooks(ctx context.Context) error {
+	return r.DoSync(ctx, func(ctx context.Context) {
+		r.systemModule.RunStartHooks(r.L)
+	})
+}
+
 // Do queues work to be executed on the Lua VM (thread-safe, non-blocking)
 // Returns false if the runtime is closing, queue is full, or context is cancelled.
 // Uses channel-based signaling for race-free shutdown detection.
@@ -143,33 +185,77 @@ func (r *Runtime) registerModules() {
 
 	// Geo module (uses shared calculator to avoid duplicate geocoding)
 	geoCfg := r.deps.Config.Events.Scheduler.Geo
-	geoModule := modules.NewGeoModule(geoCfg.Name, geoCfg.Timezone, r.deps.GeoCalc)
+	astronomical := r.deps.Scheduler != nil && r.deps.Scheduler.Evaluator().SupportsAstronomical()
+	geoModule := modules.NewGeoModule(geoCfg.Name, geoCfg.Timezone, r.deps.GeoCalc, geoCfg.IsEnabled(), astronomical)
 	r.L.PreloadModule("geo", geoModule.Loader)
 
+	// Hue module (created first: ctx.desired resolves its aliases)
+	r.hueModule = modules.NewHueModule(r.deps.Bridge, r.deps.V2Client, r.deps.SceneIndex, r.deps.Devices, r.deps.RotateKey, r.deps.RefreshCache, r.deps.ClientKey, r.deps.HoldBri)
+	r.loadHueAliases()
+	r.L.PreloadModule("hue", r.hueModule.Loader)
+
 	// Action module
-	r.actionModule = modules.NewActionModule(r.deps.Registry, r.deps.Bridge, r.deps.Stores, r.deps.Orchestrator)
+	r.actionModule = modules.NewActionModule(r.deps.Registry, r.deps.GroupActual, r.deps.Stores, r.deps.Orchestrator, r.hueModule.ResolveID)
 	r.L.PreloadModule("action", r.actionModule.Loader)
 
 	// Sched module
 	r.schedModule = modules.NewSchedModule(r.deps.Scheduler, r.deps.Config.Events.Scheduler.IsEnabled())
 	r.L.PreloadModule("sched", r.schedModule.Loader)
 
-	// Hue module
-	r.hueModule = modules.NewHueModule(r.deps.Bridge, r.deps.SceneIndex)
-	r.L.PreloadModule("hue", r.hueModule.Loader)
+	// Built-in action behind events.sse.button_cycle
+	if err := modules.NewButtonCycler(r.hueModule, r.deps.KVManager).Register(r.deps.Registry); err != nil {
+		log.Error().Err(err).Msg("Failed to register button cycle action")
+	}
+
+	// Built-in action behind hue.rotate_scenes
+	if err := modules.NewSceneRotator(r.hueModule, r.deps.KVManager).Register(r.deps.Registry); err != nil {
+		log.Error().Err(err).Msg("Failed to register scene rotation action")
+	}
 
 	// KV module (persistent key-value storage)
 	r.kvModule = modules.NewKVModule(r.deps.KVManager)
 	r.L.PreloadModule("kv", r.kvModule.Loader)
 
+	// Store module (low-level versioned resource state)
+	storeModule := modules.NewStoreModule(r.deps.Stores.Base())
+	r.L.PreloadModule("store", storeModule.Loader)
+
+	// Ledger module (action and schedule history)
+	ledgerModule := modules.NewLedgerModule(r.deps.Ledger)
+	r.L.PreloadModule("ledger", ledgerModule.Loader)
+
+	// Automation module (suppression window)
+	automationModule := modules.NewAutomationModule(r.deps.Invoker.Suppression())
+	r.L.PreloadModule("automation", automationModule.Loader)
+
+	// Reconcile module (pause/resume the reconciliation loop)
+	reconcileModule := modules.NewReconcileModule(r.deps.Orchestrator)
+	r.L.PreloadModule("reconcile", reconcileModule.Loader)
+
+	// Mode module (global home/away/night mode)
+	r.modeModule = modules.NewModeModule(r.deps.Modes)
+	r.L.PreloadModule("mode", r.modeModule.Loader)
+
 	// Collect module (event collectors for middleware)
 	collectModule := collect.NewModule()
 	r.L.PreloadModule("collect", collectModule.Loader)
 
-	// Utils module (sleep, etc.)
-	utilsModule := modules.NewUtilsModule()
+	// HTTP module (outbound requests, e.g. notifications)
+	httpModule := modules.NewHTTPModule()
+	r.L.PreloadModule("http", httpModule.Loader)
+
+	// Env module (environment variables, e.g. secrets)
+	envModule := modules.NewEnvModule()
+	r.L.PreloadModule("env", envModule.Loader)
+
+	// Utils module (sleep, random picks, etc.)
+	utilsModule := modules.NewUtilsModule(geoCfg.GetTimezone(), r.deps.KVManager)
 	r.L.PreloadModule("utils", utilsModule.Loader)
 
+	// Events module (internal lightd events: action failures)
+	r.eventsModule = modules.NewEventsModule(r.deps.Config.Events.SSE.IsEnabled())
+	r.L.PreloadModule("events", r.eventsModule.Loader)
+
 	// Event source modules with dotted namespace
 	// SSE module (Hue event stream events: button, rotary, connectivity)
 	r.sseModule = modules.NewSSEModule(r.deps.Config.Events.SSE.IsEnabled())
@@ -178,12 +264,19 @@ func (r *Runtime) registerModules() {
 	// Webhook module (HTTP webhook events)
 	r.webhookModule = modules.NewWebhookModule(r.deps.Config.Events.Webhook.Enabled)
 	r.L.PreloadModule("events.webhook", r.webhookModule.Loader)
+
+	// System module (introspection of registered automations)
+	r.systemModule = modules.NewSystemModule(r.deps.Registry, r.deps.Scheduler, r.sseModule, r.webhookModule, r.deps.Dashboard)
+	r.L.PreloadModule("system", r.systemModule.Loader)
 }
 
 // Run starts the Lua worker goroutine - this is the ONLY goroutine that touches Lua
 // It includes panic recovery to prevent crashes from killing the worker.
 // Exits when context is cancelled or runtime is closed.
 func (r *Runtime) Run(ctx context.Context) {
+	r.running.Store(true)
+	defer close(r.stopped)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -224,26 +317,68 @@ func (r *Runtime) executeWork(ctx context.Context, work LuaWork) {
 	work(ctx)
 }
 
-// LoadScript loads and executes a Lua script (must be called before Run)
+// LoadScript loads and executes a Lua script (must be called before Run).
+// require() inside the script resolves modules under the scripts directory.
 func (r *Runtime) LoadScript(path string) error {
-	// Resolve path relative to config file
-	if !filepath.IsAbs(path) {
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			configDir := filepath.Dir(r.deps.Config.Script)
-			path = filepath.Join(configDir, path)
-		}
+	// Resolve path relative to the configured script
+	path = resolvePath(path, filepath.Dir(r.deps.Config.Script))
+
+	// Modules resolve relative to scripts_dir, or the entrypoint's directory
+	scriptsDir := filepath.Dir(path)
+	if dir := r.deps.Config.ScriptsDir; dir != "" {
+		scriptsDir = resolvePath(dir, filepath.Dir(r.deps.Config.Path))
+	}
+	if err := installScriptLoader(r.L, scriptsDir); err != nil {
+		return err
 	}
 
-	log.Info().Str("path", path).Msg("Loading Lua script")
+	log.Info().Str("path", path).Str("scripts_dir", scriptsDir).Msg("Loading Lua script")
 
 	if err := r.L.DoFile(path); err != nil {
 		return fmt.Errorf("failed to execute Lua script: %w", err)
 	}
 
 	log.Info().Msg("Lua script loaded successfully")
+	r.systemModule.LogSummary()
+	return nil
+}
+
+// LoadScriptString executes Lua source given directly (script_inline or
+// stdin) instead of a file (must be called before Run). require() resolves
+// modules under scripts_dir, or the config file's directory.
+func (r *Runtime) LoadScriptString(source string) error {
+	scriptsDir := filepath.Dir(r.deps.Config.Path)
+	if dir := r.deps.Config.ScriptsDir; dir != "" {
+		scriptsDir = resolvePath(dir, scriptsDir)
+	}
+	if err := installScriptLoader(r.L, scriptsDir); err != nil {
+		return err
+	}
+
+	log.Info().Int("bytes", len(source)).Str("scripts_dir", scriptsDir).Msg("Loading inline Lua script")
+
+	if err := r.L.DoString(source); err != nil {
+		return fmt.Errorf("failed to execute Lua script: %w", err)
+	}
+
+	log.Info().Msg("Lua script loaded successfully")
+	r.systemModule.LogSummary()
 	return nil
 }
 
+// resolvePath resolves a relative path that does not exist in the working
+// directory against baseDir. The entrypoint script resolves against the
+// configured script's directory, scripts_dir against the config file's.
+func resolvePath(path, baseDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return filepath.Join(baseDir, path)
+	}
+	return path
+}
+
 // GetSSEModule returns the SSE module for handler registration
 func (r *Runtime) GetSSEModule() *modules.SSEModule {
 	return r.sseModule
@@ -254,7 +389,33 @@ func (r *Runtime) GetWebhookModule() *modules.WebhookModule {
 	return r.webhookModule
 }
 
+// GetModeModule returns the mode module for handler registration
+func (r *Runtime) GetModeModule() *modules.ModeModule {
+	return r.modeModule
+}
+
+// GetEventsModule returns the events module for handler registration
+func (r *Runtime) GetEventsModule() *modules.EventsModule {
+	return r.eventsModule
+}
+
 // Invoker returns the action invoker
 func (r *Runtime) Invoker() *actions.Invoker {
 	return r.deps.Invoker
 }
+
+// loadHueAliases registers the hue.aliases from config with the hue module.
+// Invalid entries are logged and skipped; scripts can still add aliases via hue.alias().
+func (r *Runtime) loadHueAliases() {
+	if r.deps.Config == nil {
+		return
+	}
+	aliases := r.deps.Config.Hue.Aliases
+	for kind, names := range map[string]map[string]string{"light": aliases.Lights, "group": aliases.Groups} {
+		for name, id := range names {
+			if err := r.hueModule.AddAlias(kind, name, id); err != nil {
+				log.Warn().Err(err).Msg("Ignoring invalid hue alias in config")
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Package mode tracks the global automation mode (e.g. "home", "away", "night").
+package mode
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// DefaultMode is the mode used until one is set
+const DefaultMode = "home"
+
+// Store location of the current mode
+const (
+	storeKind = "mode"
+	storeID   = "current"
+)
+
+// Manager holds the current mode, persists it in the state store and
+// publishes EventTypeModeChange on the bus when it changes.
+type Manager struct {
+	store *storage.Store
+	bus   *events.Bus
+
+	mu      sync.RWMutex
+	current string
+}
+
+// NewManager creates a mode manager, restoring the persisted mode if present.
+func NewManager(store *storage.Store, bus *events.Bus) *Manager {
+	m := &Manager{
+		store:   store,
+		bus:     bus,
+		current: DefaultMode,
+	}
+
+	payload, _, err := store.Get(storeKind, storeID)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to load persisted mode, using default")
+		return m
+	}
+	if payload != nil {
+		var name string
+		if err := json.Unmarshal(payload, &name); err != nil || name == "" {
+			log.Warn().Err(err).Msg("Invalid persisted mode, using default")
+			return m
+		}
+		m.current = name
+	}
+
+	log.Info().Str("mode", m.current).Msg("Mode restored")
+	return m
+}
+
+// Get returns the current mode.
+func (m *Manager) Get() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Set changes the current mode, persists it and publishes a mode change event.
+// Setting the mode that is already active is a no-op and returns false.
+func (m *Manager) Set(name string) (bool, error) {
+	if name == "" {
+		return false, fmt.Errorf("mode name cannot be empty")
+	}
+
+	m.mu.Lock()
+	previous := m.current
+	if name == previous {
+		m.mu.Unlock()
+		return false, nil
+	}
+
+	payload, err := json.Marshal(name)
+	if err != nil {
+		m.mu.Unlock()
+		return false, err
+	}
+	if err := m.store.Set(storeKind, storeID, payload); err != nil {
+		m.mu.Unlock()
+		return false, fmt.Errorf("failed to persist mode: %w", err)
+	}
+	m.current = name
+	m.mu.Unlock()
+
+	log.Info().Str("mode", name).Str("previous", previous).Msg("Mode changed")
+
+	m.bus.Publish(events.Event{
+		Type: events.EventTypeModeChange,
+		Data: map[string]interface{}{
+			"mode":     name,
+			"previous": previous,
+		},
+	})
+
+	return true, nil
+}
@@ -1,9 +1,20 @@
 package scheduler
 
+import "time"
+
 // MisfirePolicy defines how to handle missed schedule occurrences on boot
 type MisfirePolicy string
 
 const (
 	MisfirePolicySkip      MisfirePolicy = "skip"       // Skip missed occurrences on boot
 	MisfirePolicyRunLatest MisfirePolicy = "run_latest" // Run the most recent missed occurrence on boot
+	MisfirePolicyRunAll    MisfirePolicy = "run_all"    // Run every missed occurrence since the last fire on boot
+)
+
+const (
+	// MaxReplayWindow bounds how far back MisfirePolicyRunAll looks for missed occurrences
+	MaxReplayWindow = 72 * time.Hour
+
+	// MaxReplayOccurrences bounds how many occurrences MisfirePolicyRunAll replays per schedule
+	MaxReplayOccurrences = 20
 )
@@ -4,6 +4,7 @@ package scheduler
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
@@ -135,6 +136,14 @@ func (s *DailySchedule) TimeExprString() string {
 	return s.timeExpr.String()
 }
 
+// MinGap keeps a periodic schedule's occurrences at least Gap away from the
+// occurrences of schedules tagged Tag. A tick that falls too close is delayed
+// until Gap after the conflicting occurrence.
+type MinGap struct {
+	Tag string
+	Gap time.Duration
+}
+
 // PeriodicSchedule implements Schedule for interval-based schedules.
 // Fires at regular intervals (e.g., "every 30m").
 type PeriodicSchedule struct {
@@ -142,6 +151,12 @@ type PeriodicSchedule struct {
 	tag           string
 	interval      time.Duration
 	startTime     time.Time // When the schedule started (for interval calculation)
+	aligned       bool      // ticks restart at every local midnight in loc
+	loc           *time.Location
+	minGap        MinGap    // spacing from other schedules' occurrences (zero Gap = none)
+	maxRuns       int       // stop after this many runs (0 = unbounded)
+	until         time.Time // no occurrences after this time (zero = unbounded)
+	runs          atomic.Int64
 	actionName    string
 	actionArgs    map[string]any
 	misfirePolicy MisfirePolicy
@@ -166,22 +181,84 @@ func NewPeriodicSchedule(
 	}
 }
 
+// NewAlignedPeriodicSchedule creates a periodic schedule whose occurrences
+// fall on wall-clock boundaries in loc (e.g. :00, :15, :30, :45 for 15m).
+// Each day's ticks are counted from that day's local midnight, so they stay
+// on the clock across DST changes; for intervals that don't divide 24h the
+// last tick of a day is followed by the next midnight.
+func NewAlignedPeriodicSchedule(
+	id string,
+	interval time.Duration,
+	actionName string,
+	actionArgs map[string]any,
+	tag string,
+	loc *time.Location,
+) *PeriodicSchedule {
+	s := NewPeriodicSchedule(id, interval, actionName, actionArgs, tag)
+	now := time.Now().In(loc)
+	s.startTime = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	s.aligned = true
+	s.loc = loc
+	return s
+}
+
+// midnight returns the local midnight starting t's day in the schedule's location.
+func (s *PeriodicSchedule) midnight(t time.Time) time.Time {
+	t = t.In(s.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+}
+
+// nextMidnight returns the local midnight ending the day that starts at day.
+func (s *PeriodicSchedule) nextMidnight(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, s.loc)
+}
+
+// alignedNext returns the first tick after t.
+func (s *PeriodicSchedule) alignedNext(t time.Time) time.Time {
+	day := s.midnight(t)
+	next := day.Add(time.Duration(int64(t.Sub(day)/s.interval)+1) * s.interval)
+	if end := s.nextMidnight(day); !next.Before(end) {
+		return end
+	}
+	return next
+}
+
+// alignedPrev returns the last tick before t.
+func (s *PeriodicSchedule) alignedPrev(t time.Time) time.Time {
+	day := s.midnight(t)
+	if !t.After(day) {
+		end := day
+		day = s.midnight(day.Add(-time.Nanosecond))
+		t = end
+	}
+	return day.Add(time.Duration(int64((t.Sub(day)-1)/s.interval)) * s.interval)
+}
+
 func (s *PeriodicSchedule) ID() string                   { return s.id }
 func (s *PeriodicSchedule) Tag() string                  { return s.tag }
 func (s *PeriodicSchedule) ActionName() string           { return s.actionName }
 func (s *PeriodicSchedule) ActionArgs() map[string]any   { return s.actionArgs }
 func (s *PeriodicSchedule) MisfirePolicy() MisfirePolicy { return s.misfirePolicy }
 
-// Next returns the next occurrence after the given time.
+// Next returns the next occurrence after the given time, or nil once the
+// schedule has used up its max runs or the occurrence would fall after until.
 func (s *PeriodicSchedule) Next(after time.Time) *Occurrence {
-	if after.Before(s.startTime) {
-		return NewOccurrence(s.id, s.startTime)
+	if s.Exhausted() {
+		return nil
 	}
 
-	elapsed := after.Sub(s.startTime)
-	ticks := int64(elapsed / s.interval)
-	nextTime := s.startTime.Add(time.Duration(ticks+1) * s.interval)
+	nextTime := s.startTime
+	if s.aligned && !after.Before(s.startTime) {
+		nextTime = s.alignedNext(after)
+	} else if !after.Before(s.startTime) {
+		elapsed := after.Sub(s.startTime)
+		ticks := int64(elapsed / s.interval)
+		nextTime = s.startTime.Add(time.Duration(ticks+1) * s.interval)
+	}
 
+	if !s.until.IsZero() && nextTime.After(s.until) {
+		return nil
+	}
 	return NewOccurrence(s.id, nextTime)
 }
 
@@ -191,6 +268,10 @@ func (s *PeriodicSchedule) Prev(before time.Time) *Occurrence {
 		return nil
 	}
 
+	if s.aligned {
+		return NewOccurrence(s.id, s.alignedPrev(before))
+	}
+
 	elapsed := before.Sub(s.startTime)
 	ticks := int64(elapsed / s.interval)
 	prevTime := s.startTime.Add(time.Duration(ticks) * s.interval)
@@ -211,3 +292,53 @@ func (s *PeriodicSchedule) Prev(before time.Time) *Occurrence {
 func (s *PeriodicSchedule) Interval() time.Duration {
 	return s.interval
 }
+
+// Aligned reports whether occurrences are aligned to clock boundaries.
+func (s *PeriodicSchedule) Aligned() bool {
+	return s.aligned
+}
+
+// SetMinGap sets the spacing kept from other schedules' occurrences.
+func (s *PeriodicSchedule) SetMinGap(gap MinGap) {
+	s.minGap = gap
+}
+
+// MinGap returns the spacing kept from other schedules' occurrences.
+func (s *PeriodicSchedule) MinGap() MinGap {
+	return s.minGap
+}
+
+// SetMaxRuns stops the schedule after n runs from the scheduler loop (0 = unbounded).
+func (s *PeriodicSchedule) SetMaxRuns(n int) {
+	s.maxRuns = n
+}
+
+// MaxRuns returns the run limit (0 = unbounded).
+func (s *PeriodicSchedule) MaxRuns() int {
+	return s.maxRuns
+}
+
+// SetUntil stops the schedule at t: no occurrence falls after it (zero = unbounded).
+func (s *PeriodicSchedule) SetUntil(t time.Time) {
+	s.until = t
+}
+
+// Until returns the end time (zero = unbounded).
+func (s *PeriodicSchedule) Until() time.Time {
+	return s.until
+}
+
+// RecordRun counts a run towards MaxRuns. The count is kept in memory only.
+func (s *PeriodicSchedule) RecordRun() {
+	s.runs.Add(1)
+}
+
+// Runs returns the number of runs recorded since the schedule was created.
+func (s *PeriodicSchedule) Runs() int {
+	return int(s.runs.Load())
+}
+
+// Exhausted reports whether the schedule has used up its max runs.
+func (s *PeriodicSchedule) Exhausted() bool {
+	return s.maxRuns > 0 && s.runs.Load() >= int64(s.maxRuns)
+}
@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// alignedAt returns an aligned periodic schedule as if created on day in loc.
+func alignedAt(interval time.Duration, loc *time.Location, day time.Time) *PeriodicSchedule {
+	s := NewAlignedPeriodicSchedule("p", interval, "a", nil, "", loc)
+	s.startTime = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	return s
+}
+
+func TestAlignedPeriodic_StaysOnClockAcrossDST(t *testing.T) {
+	loc := berlin(t)
+	// Created the day before 2025-03-30, when clocks jump from 02:00 to 03:00
+	created := time.Date(2025, 3, 29, 0, 0, 0, 0, loc)
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		after    time.Time
+		want     time.Time
+	}{
+		{"hourly skips the missing hour", time.Hour,
+			time.Date(2025, 3, 30, 1, 30, 0, 0, loc), time.Date(2025, 3, 30, 3, 0, 0, 0, loc)},
+		{"40m restarts at midnight after a 23h day", 40 * time.Minute,
+			time.Date(2025, 3, 30, 23, 50, 0, 0, loc), time.Date(2025, 3, 31, 0, 0, 0, 0, loc)},
+		{"40m on the day after the change", 40 * time.Minute,
+			time.Date(2025, 3, 31, 0, 5, 0, 0, loc), time.Date(2025, 3, 31, 0, 40, 0, 0, loc)},
+		{"hourly through the repeated hour", time.Hour,
+			time.Date(2025, 10, 26, 2, 30, 0, 0, loc), time.Date(2025, 10, 26, 2, 0, 0, 0, loc).Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := alignedAt(tt.interval, loc, created)
+			got := s.Next(tt.after)
+			if got == nil || !got.Time.Equal(tt.want) {
+				t.Fatalf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+			if prev := s.Prev(tt.want); prev == nil || !prev.Time.Before(tt.want) || prev.Time.After(tt.after) {
+				t.Errorf("Prev(%v) = %v, want the tick at or before %v", tt.want, prev, tt.after)
+			}
+		})
+	}
+}
+
+func TestAlignedPeriodic_IntervalNotDividingDay(t *testing.T) {
+	loc := time.UTC
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, loc)
+	s := alignedAt(7*time.Minute, loc, day)
+
+	// 205 * 7m = 23:55 is the last tick; the next day starts again at 00:00
+	steps := []struct{ after, want time.Time }{
+		{day.Add(23*time.Hour + 50*time.Minute), day.Add(23*time.Hour + 55*time.Minute)},
+		{day.Add(23*time.Hour + 55*time.Minute), day.AddDate(0, 0, 1)},
+		{day.AddDate(0, 0, 1), day.AddDate(0, 0, 1).Add(7 * time.Minute)},
+		{day.AddDate(0, 0, 9).Add(time.Minute), day.AddDate(0, 0, 9).Add(7 * time.Minute)},
+	}
+	for _, st := range steps {
+		if got := s.Next(st.after); got == nil || !got.Time.Equal(st.want) {
+			t.Errorf("Next(%v) = %v, want %v", st.after, got, st.want)
+		}
+	}
+
+	if got := s.Prev(day.AddDate(0, 0, 1)); got == nil || !got.Time.Equal(day.Add(23*time.Hour+55*time.Minute)) {
+		t.Errorf("Prev(next midnight) = %v, want 23:55", got)
+	}
+	if got := s.Prev(day); got != nil {
+		t.Errorf("Prev(start) = %v, want nil", got)
+	}
+}
@@ -3,6 +3,7 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -22,17 +23,41 @@ const (
 	StrategyPrev Strategy = "PREV"
 )
 
+// ParseStrategy parses a strategy name case-insensitively.
+// An empty string defaults to StrategyNext.
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(strings.ToUpper(strings.TrimSpace(s))) {
+	case "", StrategyNext:
+		return StrategyNext, nil
+	case StrategyPrev:
+		return StrategyPrev, nil
+	default:
+		return "", fmt.Errorf("unknown strategy %q (expected NEXT or PREV)", s)
+	}
+}
+
+// SyncInvoker invokes a scheduled action and waits for it to finish. It
+// receives the same values as a schedule event on the bus.
+type SyncInvoker func(ctx context.Context, actionName string, args map[string]any, occurrenceID, source, scheduleID string) error
+
 // Scheduler manages schedule definitions and occurrence execution.
-// Schedules are stored in memory and events are emitted to the EventBus.
+// Schedules are stored in memory and events are emitted to the EventBus,
+// except for schedules marked sync, which are invoked directly (see SetSync).
 type Scheduler struct {
 	mu        sync.RWMutex
 	schedules map[string]Schedule
+	syncIDs   map[string]bool // schedules invoked through syncInvoker
+
+	syncInvoker SyncInvoker
 
 	bus       *events.Bus
 	ledger    *storage.Ledger
 	evaluator TimeEvaluator
 	tz        *time.Location
 
+	// How long a schedule_fired record blocks re-emitting the same occurrence
+	firedDedupWindow time.Duration
+
 	reschedule chan struct{}
 }
 
@@ -51,6 +76,7 @@ func New(
 
 	return &Scheduler{
 		schedules:  make(map[string]Schedule),
+		syncIDs:    make(map[string]bool),
 		bus:        bus,
 		ledger:     l,
 		evaluator:  NewAstroTimeEvaluator(geoCalc, location, timezone),
@@ -73,6 +99,7 @@ func NewWithFixedTimeOnly(
 
 	return &Scheduler{
 		schedules:  make(map[string]Schedule),
+		syncIDs:    make(map[string]bool),
 		bus:        bus,
 		ledger:     l,
 		evaluator:  NewFixedTimeEvaluator(timezone),
@@ -81,6 +108,41 @@ func NewWithFixedTimeOnly(
 	}
 }
 
+// SetFiredDedupWindow sets how long an emitted occurrence is not emitted again
+// while its action is still pending. Zero disables the check. Boot recovery
+// ignores the window: a fired but never completed occurrence is replayed.
+func (s *Scheduler) SetFiredDedupWindow(d time.Duration) {
+	s.firedDedupWindow = d
+}
+
+// SetSyncInvoker sets the invoker used for schedules marked sync.
+// Without one, sync schedules are emitted to the bus like any other.
+func (s *Scheduler) SetSyncInvoker(invoke SyncInvoker) {
+	s.syncInvoker = invoke
+}
+
+// SetSync marks a schedule to be invoked directly from the scheduler loop
+// instead of through the bus, so its action starts at the computed time and
+// in order with other sync schedules. The loop waits for the action to finish
+// before moving on. Deduplication is the same either way. Manual runs
+// (RunClosest, RunByID) and boot recovery always go through the bus.
+func (s *Scheduler) SetSync(id string, sync bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sync {
+		s.syncIDs[id] = true
+	} else {
+		delete(s.syncIDs, id)
+	}
+}
+
+// isSync reports whether a schedule is invoked directly
+func (s *Scheduler) isSync(id string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.syncIDs[id]
+}
+
 // Register adds a schedule
 func (s *Scheduler) Register(sched Schedule) {
 	s.mu.Lock()
@@ -100,24 +162,113 @@ func (s *Scheduler) Register(sched Schedule) {
 func (s *Scheduler) Unregister(id string) {
 	s.mu.Lock()
 	delete(s.schedules, id)
+	delete(s.syncIDs, id)
 	s.mu.Unlock()
 	s.notifyReschedule()
 }
 
+// retire unregisters a bounded periodic schedule that will not fire again,
+// unless it has been replaced under the same ID in the meantime
+func (s *Scheduler) retire(sched *PeriodicSchedule) {
+	s.mu.Lock()
+	current, ok := s.schedules[sched.ID()]
+	if !ok || current != Schedule(sched) {
+		s.mu.Unlock()
+		return
+	}
+	delete(s.schedules, sched.ID())
+	delete(s.syncIDs, sched.ID())
+	s.mu.Unlock()
+
+	log.Info().
+		Str("id", sched.ID()).
+		Int("runs", sched.Runs()).
+		Msg("Periodic schedule reached its bound, unregistered")
+
+	s.notifyReschedule()
+}
+
+// IDs returns the IDs of all registered schedules, sorted
+func (s *Scheduler) IDs() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]string, 0, len(s.schedules))
+	for id := range s.schedules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ActionNames returns the action name of every registered schedule, keyed by
+// schedule ID.
+func (s *Scheduler) ActionNames() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make(map[string]string, len(s.schedules))
+	for id, sched := range s.schedules {
+		names[id] = sched.ActionName()
+	}
+	return names
+}
+
 // Define creates and registers a daily schedule (convenience method for Lua)
-func (s *Scheduler) Define(id, timeExpr, actionName string, args map[string]any, tag string, misfirePolicy MisfirePolicy) error {
+// polarFallback, if not empty, is a fixed time used when an astronomical event
+// does not occur (see TimeExpr.SetPolarFallback).
+func (s *Scheduler) Define(id, timeExpr, actionName string, args map[string]any, tag string, misfirePolicy MisfirePolicy, polarFallback string) error {
 	sched, err := NewDailySchedule(id, timeExpr, actionName, args, tag, misfirePolicy, s.evaluator)
 	if err != nil {
 		return err
 	}
+	if polarFallback != "" {
+		if err := sched.timeExpr.SetPolarFallback(polarFallback); err != nil {
+			return err
+		}
+	}
 	s.Register(sched)
 	return nil
 }
 
-// DefinePeriodic creates and registers a periodic schedule (convenience method for Lua)
-func (s *Scheduler) DefinePeriodic(id string, interval time.Duration, actionName string, args map[string]any, tag string) {
-	sched := NewPeriodicSchedule(id, interval, actionName, args, tag)
+// PeriodicBound limits how long a periodic schedule keeps firing.
+// Zero values mean unbounded.
+type PeriodicBound struct {
+	MaxRuns int    // stop after this many runs
+	Until   string // time expression (e.g. "23:00", "@sunrise"); stop at its next occurrence
+}
+
+// DefinePeriodic creates and registers a periodic schedule (convenience method for Lua).
+// If align is set, occurrences fall on clock boundaries in the scheduler's timezone.
+// A non-zero minGap.Gap delays ticks that fall too close to schedules tagged minGap.Tag.
+// Once bound is reached the schedule is unregistered.
+func (s *Scheduler) DefinePeriodic(id string, interval time.Duration, actionName string, args map[string]any, tag string, align bool, minGap MinGap, bound PeriodicBound) error {
+	var sched *PeriodicSchedule
+	if align {
+		sched = NewAlignedPeriodicSchedule(id, interval, actionName, args, tag, s.tz)
+	} else {
+		sched = NewPeriodicSchedule(id, interval, actionName, args, tag)
+	}
+	sched.SetMinGap(minGap)
+	sched.SetMaxRuns(bound.MaxRuns)
+
+	if bound.Until != "" {
+		expr, err := ParseTimeExpr(bound.Until)
+		if err != nil {
+			return fmt.Errorf("invalid until expression: %w", err)
+		}
+		if expr.IsAstronomical() && !s.evaluator.SupportsAstronomical() {
+			return fmt.Errorf("astronomical time expression %q requires geo to be enabled (events.scheduler.geo.enabled: true)", bound.Until)
+		}
+		until, ok := s.evaluator.ComputeNextOccurrence(expr, time.Now())
+		if !ok {
+			return fmt.Errorf("until expression %q has no upcoming occurrence", bound.Until)
+		}
+		sched.SetUntil(until)
+	}
+
 	s.Register(sched)
+	return nil
 }
 
 // notifyReschedule signals the scheduler to recalculate
@@ -162,7 +313,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 
 		case <-timer.C:
 			if occ != nil && sched != nil {
-				s.emit(sched, occ, "scheduler")
+				s.fireDue(ctx, sched, occ)
 			}
 		}
 	}
@@ -172,6 +323,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 // grouped by tag. For schedules with the same tag, only the one with the
 // most recent previous occurrence is executed (since later schedules supersede earlier ones).
 // Schedules without a tag are grouped individually.
+// Schedules with MisfirePolicyRunAll are not grouped; each replays all its missed occurrences.
 func (s *Scheduler) RunBootRecovery() {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -187,7 +339,11 @@ func (s *Scheduler) RunBootRecovery() {
 	winners := make(map[string]candidate)
 
 	for _, sched := range s.schedules {
-		if sched.MisfirePolicy() == MisfirePolicySkip {
+		switch sched.MisfirePolicy() {
+		case MisfirePolicySkip:
+			continue
+		case MisfirePolicyRunAll:
+			s.replayAll(sched, now)
 			continue
 		}
 
@@ -222,6 +378,36 @@ func (s *Scheduler) RunBootRecovery() {
 	}
 }
 
+// replayAll emits every occurrence of sched missed since its last recorded
+// completion, oldest first. The window is bounded by MaxReplayWindow and
+// MaxReplayOccurrences. Occurrences keep their regular IDs so already
+// completed ones are deduplicated by the ledger.
+func (s *Scheduler) replayAll(sched Schedule, now time.Time) {
+	since := now.Add(-MaxReplayWindow)
+	if last, ok := s.ledger.LastCompletedForDef(sched.ID()); ok && last.After(since) {
+		since = last
+	}
+
+	count := 0
+	for occ := sched.Next(since); occ != nil && !occ.Time.After(now); occ = sched.Next(occ.Time) {
+		if count >= MaxReplayOccurrences {
+			log.Warn().
+				Str("schedule", sched.ID()).
+				Int("max", MaxReplayOccurrences).
+				Msg("Boot recovery: replay limit reached, dropping older occurrences")
+			break
+		}
+
+		log.Info().
+			Str("schedule", sched.ID()).
+			Time("occurrence_time", occ.Time).
+			Msg("Boot recovery: replaying missed occurrence")
+
+		s.emit(sched, occ, "boot_recovery")
+		count++
+	}
+}
+
 // nextOccurrence finds the earliest next occurrence across all schedules
 func (s *Scheduler) nextOccurrence(after time.Time) (*Occurrence, Schedule) {
 	s.mu.RLock()
@@ -231,7 +417,11 @@ func (s *Scheduler) nextOccurrence(after time.Time) (*Occurrence, Schedule) {
 	var source Schedule
 
 	for _, sched := range s.schedules {
-		if occ := sched.Next(after); occ != nil {
+		occ := sched.Next(after)
+		if periodic, ok := sched.(*PeriodicSchedule); ok && periodic.MinGap().Gap > 0 {
+			occ = s.nextSpaced(periodic, after)
+		}
+		if occ != nil {
 			if earliest == nil || occ.Time.Before(earliest.Time) {
 				earliest = occ
 				source = sched
@@ -242,15 +432,165 @@ func (s *Scheduler) nextOccurrence(after time.Time) (*Occurrence, Schedule) {
 	return earliest, source
 }
 
-// emit publishes a schedule event to the bus with deduplication check
-func (s *Scheduler) emit(sched Schedule, occ *Occurrence, source string) {
+// NextWake returns when the scheduler will next wake to fire a schedule, and
+// that schedule. Returns the zero time and nil if no schedule has an upcoming
+// occurrence.
+func (s *Scheduler) NextWake() (time.Time, Schedule) {
+	occ, sched := s.nextOccurrence(time.Now())
+	if occ == nil {
+		return time.Time{}, nil
+	}
+	return occ.Time, sched
+}
+
+// maxGapShifts bounds how many conflicting occurrences a single tick can be
+// pushed past, and how many ticks nextSpaced examines.
+const maxGapShifts = 16
+
+// nextSpaced returns the next occurrence of a periodic schedule with a
+// minimum gap, after delaying ticks that fall too close to occurrences of
+// the gap tag. The occurrence keeps its tick's ID so a delayed tick is still
+// deduplicated as the same occurrence. Caller must hold s.mu.
+func (s *Scheduler) nextSpaced(sched *PeriodicSchedule, after time.Time) *Occurrence {
+	if sched.Exhausted() {
+		return nil
+	}
+
+	// Ticks at or before after may have been delayed past it (several gaps
+	// when pushes chain); the earliest of them is the next occurrence
+	var delayed *Occurrence
+	prev := sched.Prev(after.Add(time.Nanosecond))
+	for i := 0; prev != nil && i < maxGapShifts; i++ {
+		t := s.spacedTime(sched, prev.Time)
+		if !t.After(after) {
+			break
+		}
+		delayed = &Occurrence{ID: prev.ID, ScheduleID: prev.ScheduleID, Time: t}
+		prev = sched.Prev(prev.Time)
+	}
+	if delayed != nil {
+		return delayed
+	}
+
+	occ := sched.Next(after)
+	for i := 0; occ != nil && i < maxGapShifts; i++ {
+		if t := s.spacedTime(sched, occ.Time); t.After(after) {
+			return &Occurrence{ID: occ.ID, ScheduleID: occ.ScheduleID, Time: t}
+		}
+		occ = sched.Next(occ.Time)
+	}
+	return occ
+}
+
+// spacedTime delays t until it is at least the schedule's gap away from
+// every occurrence of schedules with the gap tag. Caller must hold s.mu.
+func (s *Scheduler) spacedTime(sched *PeriodicSchedule, t time.Time) time.Time {
+	minGap := sched.MinGap()
+
+	for i := 0; i < maxGapShifts; i++ {
+		shifted := false
+		for _, other := range s.schedules {
+			if other.ID() == sched.ID() || other.Tag() != minGap.Tag {
+				continue
+			}
+			if c := other.Next(t.Add(-minGap.Gap)); c != nil && c.Time.Before(t.Add(minGap.Gap)) {
+				log.Debug().
+					Str("schedule", sched.ID()).
+					Str("conflict", other.ID()).
+					Time("tick", t).
+					Time("delayed_to", c.Time.Add(minGap.Gap)).
+					Msg("Delaying periodic occurrence to keep min gap")
+				t = c.Time.Add(minGap.Gap)
+				shifted = true
+			}
+		}
+		if !shifted {
+			break
+		}
+	}
+	return t
+}
+
+// fireDue fires a due occurrence and, for periodic schedules, counts the run
+// towards max_runs (only if it actually fired, not when deduplicated) and
+// retires the schedule once it has no further occurrence.
+func (s *Scheduler) fireDue(ctx context.Context, sched Schedule, occ *Occurrence) {
+	fired := s.fire(ctx, sched, occ)
+
+	periodic, ok := sched.(*PeriodicSchedule)
+	if !ok {
+		return
+	}
+	if fired {
+		periodic.RecordRun()
+	}
+	if periodic.Next(occ.Time) == nil {
+		s.retire(periodic)
+	}
+}
+
+// fire runs a due occurrence from the scheduler loop: sync schedules are
+// invoked directly, the rest are emitted to the bus. Returns false if the
+// occurrence was skipped as a duplicate.
+func (s *Scheduler) fire(ctx context.Context, sched Schedule, occ *Occurrence) bool {
+	if s.syncInvoker == nil || !s.isSync(sched.ID()) {
+		return s.emit(sched, occ, "scheduler")
+	}
+	if !s.claim(sched, occ, "scheduler") {
+		return false
+	}
+
+	log.Info().
+		Str("schedule_id", sched.ID()).
+		Str("occurrence_id", occ.ID).
+		Str("action", sched.ActionName()).
+		Time("time", occ.Time).
+		Msg("Invoking schedule synchronously")
+
+	if err := s.syncInvoker(ctx, sched.ActionName(), sched.ActionArgs(), occ.ID, "scheduler", sched.ID()); err != nil {
+		log.Error().Err(err).
+			Str("action", sched.ActionName()).
+			Str("schedule_id", sched.ID()).
+			Str("occurrence_id", occ.ID).
+			Msg("Failed to invoke scheduled action")
+	}
+	return true
+}
+
+// emit publishes a schedule event to the bus with deduplication check.
+// Returns false if the occurrence was skipped.
+func (s *Scheduler) emit(sched Schedule, occ *Occurrence, source string) bool {
+	if !s.claim(sched, occ, source) {
+		return false
+	}
+	s.emitDirect(sched, occ, source)
+	return true
+}
+
+// claim checks that an occurrence hasn't completed or been fired recently and
+// records it as fired. Returns false if it should be skipped. Boot recovery
+// only skips completed occurrences: one fired before a crash never ran to
+// completion, and recovering it is the point of the replay.
+func (s *Scheduler) claim(sched Schedule, occ *Occurrence, source string) bool {
 	// Deduplication check
 	if s.ledger.HasCompleted(occ.ID) {
 		log.Debug().Str("occurrence", occ.ID).Msg("Already completed, skipping")
-		return
+		return false
+	}
+	if source != "boot_recovery" && s.firedDedupWindow > 0 && s.ledger.HasFiredSince(occ.ID, time.Now().Add(-s.firedDedupWindow)) {
+		log.Debug().Str("occurrence", occ.ID).Msg("Already fired, skipping")
+		return false
 	}
 
-	s.emitDirect(sched, occ, source)
+	// Record the emission so a re-evaluation before the action completes
+	// doesn't emit the same occurrence twice
+	if err := s.ledger.AppendWithSource(storage.EventScheduleFired, occ.ID, source, sched.ID(), map[string]any{
+		"action": sched.ActionName(),
+		"run_at": occ.Time.Unix(),
+	}); err != nil {
+		log.Warn().Err(err).Str("occurrence", occ.ID).Msg("Failed to record schedule_fired")
+	}
+	return true
 }
 
 // emitDirect publishes a schedule event without deduplication (for boot recovery)
@@ -278,7 +618,8 @@ func (s *Scheduler) emitDirect(sched Schedule, occ *Occurrence, source string) {
 
 // RunClosest finds and executes the closest schedule matching criteria.
 // Emits an event (no idempotency key for manual triggers).
-func (s *Scheduler) RunClosest(tags []string, strategy Strategy) {
+// Returns an error if no schedule matches.
+func (s *Scheduler) RunClosest(tags []string, strategy Strategy) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -327,7 +668,7 @@ func (s *Scheduler) RunClosest(tags []string, strategy Strategy) {
 			Strs("tags", tags).
 			Str("strategy", string(strategy)).
 			Msg("No matching schedule found")
-		return
+		return fmt.Errorf("no schedule matches tags %v", tags)
 	}
 
 	log.Info().
@@ -344,6 +685,8 @@ func (s *Scheduler) RunClosest(tags []string, strategy Strategy) {
 		Time:       now,
 	}
 	s.emitDirect(closestSched, manualOcc, "run_closest")
+
+	return nil
 }
 
 // GetClosest finds the closest schedule matching criteria without running it.
@@ -415,23 +758,20 @@ func containsTag(tags []string, tag string) bool {
 
 // ScheduleEntry represents a single occurrence for display
 type ScheduleEntry struct {
-	ID         string
-	TypeExpr   string
-	Time       time.Time
-	ActionName string
-	Tag        string
-	IsPast     bool
+	ID         string    `json:"id"`
+	TypeExpr   string    `json:"type_expr"`
+	Time       time.Time `json:"time"`
+	ActionName string    `json:"action"`
+	Tag        string    `json:"tag,omitempty"`
+	IsPast     bool      `json:"is_past"`
 }
 
-// FormatScheduleForDay returns a human-readable schedule for a specific day.
-func (s *Scheduler) FormatScheduleForDay(day time.Time) string {
+// OccurrencesForDay returns every occurrence on the given day (in the
+// scheduler's timezone), sorted by time.
+func (s *Scheduler) OccurrencesForDay(day time.Time) []ScheduleEntry {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.schedules) == 0 {
-		return "No scheduled definitions"
-	}
-
 	now := time.Now().In(s.tz)
 	dayInTz := day.In(s.tz)
 
@@ -440,14 +780,11 @@ func (s *Scheduler) FormatScheduleForDay(day time.Time) string {
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
 	// Collect all occurrences for the day
-	var entries []ScheduleEntry
+	entries := []ScheduleEntry{}
 
 	for _, sched := range s.schedules {
 		typeExpr := s.getTypeExpr(sched)
 		tag := sched.Tag()
-		if tag == "" {
-			tag = "-"
-		}
 
 		// For daily schedules, get today's occurrence
 		if daily, ok := sched.(*DailySchedule); ok {
@@ -485,6 +822,48 @@ func (s *Scheduler) FormatScheduleForDay(day time.Time) string {
 
 	// Sort by time
 	sortScheduleEntries(entries)
+	return entries
+}
+
+// NextByTag returns the next occurrence after now for each tag.
+// Untagged schedules are reported under "".
+func (s *Scheduler) NextByTag(now time.Time) map[string]ScheduleEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	next := make(map[string]ScheduleEntry)
+	for _, sched := range s.schedules {
+		occ := sched.Next(now)
+		if occ == nil {
+			continue
+		}
+		tag := sched.Tag()
+		if cur, ok := next[tag]; ok && !occ.Time.Before(cur.Time) {
+			continue
+		}
+		next[tag] = ScheduleEntry{
+			ID:         sched.ID(),
+			TypeExpr:   s.getTypeExpr(sched),
+			Time:       occ.Time,
+			ActionName: sched.ActionName(),
+			Tag:        tag,
+		}
+	}
+	return next
+}
+
+// FormatScheduleForDay returns a human-readable schedule for a specific day.
+func (s *Scheduler) FormatScheduleForDay(day time.Time) string {
+	s.mu.RLock()
+	empty := len(s.schedules) == 0
+	s.mu.RUnlock()
+
+	if empty {
+		return "No scheduled definitions"
+	}
+
+	entries := s.OccurrencesForDay(day)
+	dayInTz := day.In(s.tz)
 
 	// Format output
 	var sb strings.Builder
@@ -501,8 +880,13 @@ func (s *Scheduler) FormatScheduleForDay(day time.Time) string {
 
 		timeStr := entry.Time.In(s.tz).Format("15:04:05")
 
+		tag := entry.Tag
+		if tag == "" {
+			tag = "-"
+		}
+
 		sb.WriteString(fmt.Sprintf("%-3s %-20s %-20s %-12s %-20s %s\n",
-			status, entry.ID, entry.TypeExpr, timeStr, entry.ActionName, entry.Tag))
+			status, entry.ID, entry.TypeExpr, timeStr, entry.ActionName, tag))
 	}
 
 	if len(entries) == 0 {
@@ -517,7 +901,17 @@ func (s *Scheduler) getTypeExpr(sched Schedule) string {
 	case *DailySchedule:
 		return v.TimeExprString()
 	case *PeriodicSchedule:
-		return fmt.Sprintf("every %s", v.Interval())
+		expr := fmt.Sprintf("every %s", v.Interval())
+		if v.Aligned() {
+			expr += " aligned"
+		}
+		if v.MaxRuns() > 0 {
+			expr += fmt.Sprintf(" (%d/%d runs)", v.Runs(), v.MaxRuns())
+		}
+		if !v.Until().IsZero() {
+			expr += fmt.Sprintf(" until %s", v.Until().In(s.tz).Format("Jan 2 15:04"))
+		}
+		return expr
 	default:
 		return "unknown"
 	}
@@ -615,6 +1009,15 @@ func sortScheduleInfoByTime(items []ScheduleInfo) {
 	}
 }
 
+// FiredToday reports whether the schedule fired today in the scheduler
+// timezone. Only scheduled and boot-recovery runs count; manual runs through
+// RunByID or RunClosest do not.
+func (s *Scheduler) FiredToday(id string) bool {
+	now := time.Now().In(s.tz)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.tz)
+	return s.ledger.FiredForDefSince(id, midnight, "scheduler", "boot_recovery")
+}
+
 // RunByID executes a schedule by ID directly.
 // Returns an error if the schedule is not found.
 func (s *Scheduler) RunByID(id string) error {
@@ -0,0 +1,221 @@
+package scheduler
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/events"
+	"github.com/dokzlo13/lightd/internal/storage"
+)
+
+// spacingScheduler returns a scheduler holding an hourly periodic schedule
+// that keeps 10m away from daily schedules tagged "scene" at the given times.
+func spacingScheduler(t *testing.T, start time.Time, sceneTimes ...string) (*Scheduler, *PeriodicSchedule) {
+	t.Helper()
+	s := &Scheduler{schedules: make(map[string]Schedule), tz: time.UTC}
+
+	p := NewPeriodicSchedule("reminder", time.Hour, "remind", nil, "")
+	p.startTime = start
+	p.SetMinGap(MinGap{Tag: "scene", Gap: 10 * time.Minute})
+	s.schedules[p.ID()] = p
+
+	eval := &FixedTimeEvaluator{tz: time.UTC}
+	for _, at := range sceneTimes {
+		d, err := NewDailySchedule("scene_"+at, at, "scene", nil, "scene", MisfirePolicySkip, eval)
+		if err != nil {
+			t.Fatalf("NewDailySchedule(%q): %v", at, err)
+		}
+		s.schedules[d.ID()] = d
+	}
+	return s, p
+}
+
+func TestSpacedTime(t *testing.T) {
+	day := utc(2025, 6, 1, 0, 0)
+
+	tests := []struct {
+		name   string
+		scenes []string
+		tick   time.Time
+		want   time.Time
+	}{
+		{"no conflict", []string{"12:00"}, utc(2025, 6, 1, 11, 0), utc(2025, 6, 1, 11, 0)},
+		{"collides with a scene", []string{"12:00"}, utc(2025, 6, 1, 12, 0), utc(2025, 6, 1, 12, 10)},
+		{"scene just after the tick", []string{"12:05"}, utc(2025, 6, 1, 12, 0), utc(2025, 6, 1, 12, 15)},
+		{"exactly gap away", []string{"12:10"}, utc(2025, 6, 1, 12, 0), utc(2025, 6, 1, 12, 0)},
+		{"chained pushes", []string{"12:00", "12:15", "12:30"}, utc(2025, 6, 1, 12, 0), utc(2025, 6, 1, 12, 40)},
+		{"scene before midnight", []string{"23:55"}, utc(2025, 6, 2, 0, 0), utc(2025, 6, 2, 0, 5)},
+		{"scene after midnight", []string{"00:05"}, utc(2025, 6, 2, 0, 0), utc(2025, 6, 2, 0, 15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, p := spacingScheduler(t, day, tt.scenes...)
+			if got := s.spacedTime(p, tt.tick); !got.Equal(tt.want) {
+				t.Errorf("spacedTime(%v) = %v, want %v", tt.tick, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextSpaced(t *testing.T) {
+	day := utc(2025, 6, 1, 0, 0)
+	s, p := spacingScheduler(t, day, "12:00", "12:15", "23:58")
+
+	tests := []struct {
+		name     string
+		after    time.Time
+		want     time.Time
+		wantTick time.Time
+	}{
+		{"undelayed tick", utc(2025, 6, 1, 10, 30), utc(2025, 6, 1, 11, 0), utc(2025, 6, 1, 11, 0)},
+		{"chained delay keeps the tick's ID", utc(2025, 6, 1, 11, 30), utc(2025, 6, 1, 12, 25), utc(2025, 6, 1, 12, 0)},
+		{"delayed tick is still ahead", utc(2025, 6, 1, 12, 20), utc(2025, 6, 1, 12, 25), utc(2025, 6, 1, 12, 0)},
+		{"after the delayed tick", utc(2025, 6, 1, 12, 25), utc(2025, 6, 1, 13, 0), utc(2025, 6, 1, 13, 0)},
+		{"pushed past midnight", utc(2025, 6, 1, 23, 30), utc(2025, 6, 2, 0, 8), utc(2025, 6, 2, 0, 0)},
+		{"pushed tick wins over the next one", utc(2025, 6, 2, 0, 5), utc(2025, 6, 2, 0, 8), utc(2025, 6, 2, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.nextSpaced(p, tt.after)
+			if got == nil {
+				t.Fatalf("nextSpaced(%v) = nil", tt.after)
+			}
+			if !got.Time.Equal(tt.want) {
+				t.Errorf("nextSpaced(%v) time = %v, want %v", tt.after, got.Time, tt.want)
+			}
+			if wantID := NewOccurrence(p.ID(), tt.wantTick).ID; got.ID != wantID {
+				t.Errorf("nextSpaced(%v) ID = %q, want %q", tt.after, got.ID, wantID)
+			}
+		})
+	}
+}
+
+func TestPeriodicMaxRuns(t *testing.T) {
+	s, ledger, _ := ledgerScheduler(t)
+	define := func() *PeriodicSchedule {
+		t.Helper()
+		if err := s.DefinePeriodic("blink", 2*time.Second, "blink", nil, "", false, MinGap{}, PeriodicBound{MaxRuns: 3}); err != nil {
+			t.Fatalf("DefinePeriodic() error = %v", err)
+		}
+		return s.schedules["blink"].(*PeriodicSchedule)
+	}
+
+	p := define()
+	at := p.startTime
+	ticks := []struct {
+		dedup    bool // occurrence already completed, so it is not fired
+		wantRuns int
+	}{
+		{false, 1},
+		{true, 1},
+		{false, 2},
+		{false, 3},
+	}
+	for i, tick := range ticks {
+		occ := p.Next(at)
+		if occ == nil {
+			t.Fatalf("tick %d: Next() = nil after %d runs, want an occurrence", i, p.Runs())
+		}
+		if tick.dedup {
+			if err := ledger.AppendWithSource(storage.EventActionCompleted, occ.ID, "scheduler", p.ID(), nil); err != nil {
+				t.Fatalf("AppendWithSource: %v", err)
+			}
+		}
+		s.fireDue(context.Background(), p, occ)
+		if got := p.Runs(); got != tick.wantRuns {
+			t.Fatalf("tick %d: Runs() = %d, want %d", i, got, tick.wantRuns)
+		}
+		at = occ.Time
+	}
+
+	// The third run used up max_runs: no further occurrence, and fireDue retired it
+	if occ := p.Next(at); occ != nil {
+		t.Fatalf("Next() after 3 runs = %v, want nil", occ.Time)
+	}
+	if _, ok := s.schedules["blink"]; ok {
+		t.Fatal("exhausted schedule still registered")
+	}
+
+	// A restart or reload defines the schedule afresh: the count starts over
+	fresh := define()
+	if fresh.Runs() != 0 {
+		t.Errorf("Runs() after redefine = %d, want 0", fresh.Runs())
+	}
+	if fresh.Next(fresh.startTime) == nil {
+		t.Error("Next() after redefine = nil, want an occurrence")
+	}
+
+	// Retiring the old instance must not remove its replacement
+	s.retire(p)
+	if s.schedules["blink"] != Schedule(fresh) {
+		t.Error("retire() removed the redefined schedule")
+	}
+}
+
+// ledgerScheduler returns a UTC scheduler backed by a temporary ledger, and
+// a channel receiving the occurrence ID of every emitted schedule event.
+func ledgerScheduler(t *testing.T) (*Scheduler, *storage.Ledger, <-chan string) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "test.db"), time.Second)
+	if err != nil {
+		t.Fatalf("storage.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	bus := events.NewBus()
+	t.Cleanup(func() { bus.Close(context.Background()) })
+	emitted := make(chan string, 16)
+	bus.Subscribe(events.EventTypeSchedule, func(e events.Event) {
+		id, _ := e.Data["occurrence_id"].(string)
+		emitted <- id
+	})
+
+	ledger := storage.NewLedger(db.DB)
+	return NewWithFixedTimeOnly(bus, ledger, "UTC"), ledger, emitted
+}
+
+// emittedIDs collects occurrence IDs until none arrive for a short while.
+func emittedIDs(ch <-chan string) map[string]bool {
+	ids := make(map[string]bool)
+	for {
+		select {
+		case id := <-ch:
+			ids[id] = true
+		case <-time.After(200 * time.Millisecond):
+			return ids
+		}
+	}
+}
+
+func TestRunBootRecovery_ReplaysFiredButNotCompleted(t *testing.T) {
+	s, ledger, emitted := ledgerScheduler(t)
+	s.SetFiredDedupWindow(10 * time.Minute)
+
+	at := time.Now().UTC().Add(-time.Minute).Format("15:04")
+	sched, err := NewDailySchedule("catchup", at, "catchup", nil, "", MisfirePolicyRunAll, s.evaluator)
+	if err != nil {
+		t.Fatalf("NewDailySchedule: %v", err)
+	}
+	s.Register(sched)
+
+	// Emitted just before a crash, never completed
+	occ := sched.Prev(time.Now())
+	if err := ledger.AppendWithSource(storage.EventScheduleFired, occ.ID, "scheduler", sched.ID(), nil); err != nil {
+		t.Fatalf("AppendWithSource: %v", err)
+	}
+
+	// The running scheduler still honours the fired window
+	s.emit(sched, occ, "scheduler")
+	if ids := emittedIDs(emitted); ids[occ.ID] {
+		t.Fatalf("occurrence %s re-emitted inside the fired dedup window", occ.ID)
+	}
+
+	s.RunBootRecovery()
+	if ids := emittedIDs(emitted); !ids[occ.ID] {
+		t.Errorf("boot recovery emitted %v, want the fired but not completed %s", ids, occ.ID)
+	}
+}
@@ -28,14 +28,21 @@ type TimeExpr struct {
 	BaseTime  BaseTimeType
 	FixedHour int // For fixed times (0-23)
 	FixedMin  int // For fixed times (0-59)
+	FixedSec  int // For fixed times (0-59), optional
 	Offset    time.Duration
+
+	// PolarFallback is a fixed time used when an astronomical event does not
+	// occur on a date (polar day/night). nil = skip the day.
+	PolarFallback *TimeExpr
 }
 
 var (
-	// Match patterns like "@dawn", "@sunset", "@noon + 30m", "@sunrise - 1h30m"
-	astroPattern = regexp.MustCompile(`^@(\w+)\s*([+-]\s*\d+[hms]+(?:\d+[ms]+)?)?$`)
-	// Match patterns like "22:15", "06:30"
-	fixedPattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	// Match patterns like "@dawn", "@sunset", "@noon + 30m", "@sunrise - 1h30m", "@sunset + 1h - 15m"
+	astroPattern = regexp.MustCompile(`^@(\w+)\s*((?:[+-]\s*\d+[hms]+(?:\d+[ms]+)?\s*)*)$`)
+	// Match a single signed offset term like "+ 30m", "-1h30m"
+	offsetTermPattern = regexp.MustCompile(`[+-]\s*\d+[hms]+(?:\d+[ms]+)?`)
+	// Match patterns like "22:15", "06:30", "06:30:15"
+	fixedPattern = regexp.MustCompile(`^(\d{1,2}):(\d{2})(?::(\d{2}))?$`)
 	// Match duration like "30m", "1h", "1h30m"
 	durationPattern = regexp.MustCompile(`([+-])\s*(.+)`)
 )
@@ -48,6 +55,10 @@ func ParseTimeExpr(expr string) (*TimeExpr, error) {
 	if matches := fixedPattern.FindStringSubmatch(expr); matches != nil {
 		hour, _ := strconv.Atoi(matches[1])
 		min, _ := strconv.Atoi(matches[2])
+		sec := 0
+		if matches[3] != "" {
+			sec, _ = strconv.Atoi(matches[3])
+		}
 
 		if hour < 0 || hour > 23 {
 			return nil, fmt.Errorf("invalid hour: %d", hour)
@@ -55,12 +66,16 @@ func ParseTimeExpr(expr string) (*TimeExpr, error) {
 		if min < 0 || min > 59 {
 			return nil, fmt.Errorf("invalid minute: %d", min)
 		}
+		if sec < 0 || sec > 59 {
+			return nil, fmt.Errorf("invalid second: %d", sec)
+		}
 
 		return &TimeExpr{
 			Raw:       expr,
 			BaseTime:  BaseTimeFixed,
 			FixedHour: hour,
 			FixedMin:  min,
+			FixedSec:  sec,
 		}, nil
 	}
 
@@ -85,14 +100,15 @@ func ParseTimeExpr(expr string) (*TimeExpr, error) {
 			return nil, fmt.Errorf("unknown astronomical time: %s", baseTimeStr)
 		}
 
+		// Sum all offset terms, so "@sunset + 1h - 15m" yields +45m
 		var offset time.Duration
-		if offsetStr != "" {
-			offsetStr = strings.ReplaceAll(offsetStr, " ", "")
-			d, err := parseDuration(offsetStr)
+		for _, term := range offsetTermPattern.FindAllString(offsetStr, -1) {
+			term = strings.ReplaceAll(term, " ", "")
+			d, err := parseDuration(term)
 			if err != nil {
 				return nil, fmt.Errorf("invalid offset: %w", err)
 			}
-			offset = d
+			offset += d
 		}
 
 		return &TimeExpr{
@@ -131,23 +147,50 @@ func parseDuration(s string) (time.Duration, error) {
 	return d, nil
 }
 
-// Evaluate calculates the actual time for this expression on a given date
+// SetPolarFallback sets the fixed civil time (e.g. "22:00") used on dates
+// where the astronomical event does not occur.
+func (te *TimeExpr) SetPolarFallback(expr string) error {
+	if !te.IsAstronomical() {
+		return fmt.Errorf("polar fallback only applies to astronomical times, not %q", te.Raw)
+	}
+	fallback, err := ParseTimeExpr(expr)
+	if err != nil {
+		return fmt.Errorf("invalid polar fallback: %w", err)
+	}
+	if !fallback.IsFixed() {
+		return fmt.Errorf("polar fallback must be a fixed time like \"22:00\", got %q", expr)
+	}
+	te.PolarFallback = fallback
+	return nil
+}
+
+// Evaluate calculates the actual time for this expression on a given date.
+// If the astronomical event does not occur and a polar fallback is set, the
+// fallback time is returned instead (offsets are not applied to it).
 func (te *TimeExpr) Evaluate(date time.Time, astro *geo.AstroTimes, tz *time.Location) (time.Time, bool) {
+	t, ok := te.evaluate(date, astro, tz)
+	if !ok && te.PolarFallback != nil && astro != nil {
+		return te.PolarFallback.evaluate(date, nil, tz)
+	}
+	return t, ok
+}
+
+func (te *TimeExpr) evaluate(date time.Time, astro *geo.AstroTimes, tz *time.Location) (time.Time, bool) {
 	var baseTime time.Time
 
 	switch te.BaseTime {
 	case BaseTimeFixed:
-		baseTime = time.Date(date.Year(), date.Month(), date.Day(),
-			te.FixedHour, te.FixedMin, 0, 0, tz)
+		baseTime = wallClockTime(date.Year(), date.Month(), date.Day(),
+			te.FixedHour, te.FixedMin, te.FixedSec, tz)
 
 	case BaseTimeDawn:
-		if astro == nil || astro.Dawn.IsZero() {
+		if astro == nil || astro.Dawn.IsZero() || astro.CivilPolarDay || astro.CivilPolarNight {
 			return time.Time{}, false // Polar skip
 		}
 		baseTime = astro.Dawn
 
 	case BaseTimeSunrise:
-		if astro == nil || astro.Sunrise.IsZero() {
+		if astro == nil || astro.Sunrise.IsZero() || astro.PolarDay || astro.PolarNight {
 			return time.Time{}, false
 		}
 		baseTime = astro.Sunrise
@@ -159,13 +202,13 @@ func (te *TimeExpr) Evaluate(date time.Time, astro *geo.AstroTimes, tz *time.Loc
 		baseTime = astro.Noon
 
 	case BaseTimeSunset:
-		if astro == nil || astro.Sunset.IsZero() {
+		if astro == nil || astro.Sunset.IsZero() || astro.PolarDay || astro.PolarNight {
 			return time.Time{}, false
 		}
 		baseTime = astro.Sunset
 
 	case BaseTimeDusk:
-		if astro == nil || astro.Dusk.IsZero() {
+		if astro == nil || astro.Dusk.IsZero() || astro.CivilPolarDay || astro.CivilPolarNight {
 			return time.Time{}, false
 		}
 		baseTime = astro.Dusk
@@ -174,6 +217,52 @@ func (te *TimeExpr) Evaluate(date time.Time, astro *geo.AstroTimes, tz *time.Loc
 	return baseTime.Add(te.Offset), true
 }
 
+// wallClockTime returns the instant a wall-clock time occurs on a date in loc,
+// resolving DST transitions explicitly instead of relying on time.Date:
+//   - a time skipped by spring-forward rolls forward to the end of the gap
+//     (e.g. 02:30 becomes 03:00 when clocks jump from 02:00 to 03:00)
+//   - a time repeated by fall-back resolves to its first occurrence
+func wallClockTime(year int, month time.Month, day, hour, min, sec int, loc *time.Location) time.Time {
+	want := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	t := time.Date(year, month, day, hour, min, sec, 0, loc)
+
+	if got := wallClock(t); !got.Equal(want) {
+		// Nonexistent time: the transition instant is the first valid one after it
+		start, end := t.ZoneBounds()
+		if got.Before(want) {
+			return end
+		}
+		return start
+	}
+
+	// Ambiguous time: if the previous zone had a larger offset, the same wall
+	// clock also occurred earlier, before the transition
+	start, _ := t.ZoneBounds()
+	if !start.IsZero() {
+		_, prevOffset := start.Add(-time.Second).Zone()
+		_, curOffset := t.Zone()
+		if prevOffset > curOffset {
+			earlier := t.Add(-time.Duration(prevOffset-curOffset) * time.Second)
+			if earlier.Before(start) && wallClock(earlier).Equal(want) {
+				return earlier
+			}
+		}
+	}
+
+	return t
+}
+
+// wallClock returns t's local date and time of day as a UTC time, for comparison.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// addDays returns noon on the day n days after t's date, in t's location.
+// Anchoring at noon keeps the calendar date stable across DST transitions.
+func addDays(t time.Time, n int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+n, 12, 0, 0, 0, t.Location())
+}
+
 // IsFixed returns true if this is a fixed time expression
 func (te *TimeExpr) IsFixed() bool {
 	return te.BaseTime == BaseTimeFixed
@@ -250,7 +339,7 @@ func (e *AstroTimeEvaluator) ComputeNextOccurrence(expr *TimeExpr, after time.Ti
 	date := after.In(e.tz)
 
 	for i := 0; i < 366; i++ {
-		checkDate := date.AddDate(0, 0, i)
+		checkDate := addDays(date, i)
 		t, ok := e.Evaluate(expr, checkDate)
 		if !ok {
 			continue
@@ -267,7 +356,7 @@ func (e *AstroTimeEvaluator) ComputePrevOccurrence(expr *TimeExpr, before time.T
 	date := before.In(e.tz)
 
 	for i := 0; i < 366; i++ {
-		checkDate := date.AddDate(0, 0, -i)
+		checkDate := addDays(date, -i)
 		t, ok := e.Evaluate(expr, checkDate)
 		if !ok {
 			continue
@@ -314,7 +403,7 @@ func (e *FixedTimeEvaluator) ComputeNextOccurrence(expr *TimeExpr, after time.Ti
 
 	// For fixed times, just check today and tomorrow
 	for i := 0; i < 2; i++ {
-		checkDate := date.AddDate(0, 0, i)
+		checkDate := addDays(date, i)
 		t, ok := e.Evaluate(expr, checkDate)
 		if !ok {
 			continue
@@ -336,7 +425,7 @@ func (e *FixedTimeEvaluator) ComputePrevOccurrence(expr *TimeExpr, before time.T
 
 	// For fixed times, just check today and yesterday
 	for i := 0; i < 2; i++ {
-		checkDate := date.AddDate(0, 0, -i)
+		checkDate := addDays(date, -i)
 		t, ok := e.Evaluate(expr, checkDate)
 		if !ok {
 			continue
@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dokzlo13/lightd/internal/geo"
+)
+
+// berlin returns the Europe/Berlin location, skipping the test if tzdata is unavailable.
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Europe/Berlin not available: %v", err)
+	}
+	return loc
+}
+
+func mustParse(t *testing.T, expr string) *TimeExpr {
+	t.Helper()
+	te, err := ParseTimeExpr(expr)
+	if err != nil {
+		t.Fatalf("ParseTimeExpr(%q) error = %v", expr, err)
+	}
+	return te
+}
+
+func utc(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestNextOccurrence_SpringForwardGapRollsForward(t *testing.T) {
+	loc := berlin(t)
+	e := &FixedTimeEvaluator{tz: loc}
+	expr := mustParse(t, "02:30")
+
+	// 2025-03-30: clocks jump from 02:00 CET to 03:00 CEST, so 02:30 does not exist
+	after := time.Date(2025, 3, 30, 0, 0, 0, 0, loc)
+	got, ok := e.ComputeNextOccurrence(expr, after)
+	if !ok {
+		t.Fatal("expected an occurrence")
+	}
+	if want := utc(2025, 3, 30, 1, 0); !got.Equal(want) {
+		t.Errorf("next = %v, want %v (03:00 CEST)", got.In(loc), want.In(loc))
+	}
+
+	// The following day is back to the normal wall-clock time
+	got, ok = e.ComputeNextOccurrence(expr, got)
+	if !ok {
+		t.Fatal("expected an occurrence")
+	}
+	if want := time.Date(2025, 3, 31, 2, 30, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("next = %v, want %v", got.In(loc), want)
+	}
+}
+
+func TestNextOccurrence_FallBackFiresOnce(t *testing.T) {
+	loc := berlin(t)
+	e := &FixedTimeEvaluator{tz: loc}
+	expr := mustParse(t, "02:30")
+
+	// 2025-10-26: clocks go back from 03:00 CEST to 02:00 CET, so 02:30 occurs twice
+	after := time.Date(2025, 10, 26, 0, 0, 0, 0, loc)
+	first, ok := e.ComputeNextOccurrence(expr, after)
+	if !ok {
+		t.Fatal("expected an occurrence")
+	}
+	if want := utc(2025, 10, 26, 0, 30); !first.Equal(want) {
+		t.Errorf("next = %v, want first occurrence %v (02:30 CEST)", first, want)
+	}
+
+	// After firing, the next occurrence is tomorrow, not the repeated 02:30 CET
+	next, ok := e.ComputeNextOccurrence(expr, first)
+	if !ok {
+		t.Fatal("expected an occurrence")
+	}
+	if want := utc(2025, 10, 27, 1, 30); !next.Equal(want) {
+		t.Errorf("next = %v, want %v (02:30 CET next day)", next, want)
+	}
+}
+
+func TestPrevOccurrence_FallBackUsesFirstOccurrence(t *testing.T) {
+	loc := berlin(t)
+	e := &FixedTimeEvaluator{tz: loc}
+	expr := mustParse(t, "02:30")
+
+	before := time.Date(2025, 10, 26, 12, 0, 0, 0, loc)
+	got, ok := e.ComputePrevOccurrence(expr, before)
+	if !ok {
+		t.Fatal("expected an occurrence")
+	}
+	if want := utc(2025, 10, 26, 0, 30); !got.Equal(want) {
+		t.Errorf("prev = %v, want %v", got, want)
+	}
+}
+
+func TestNextOccurrence_UnaffectedTimesAcrossTransitions(t *testing.T) {
+	loc := berlin(t)
+	e := &FixedTimeEvaluator{tz: loc}
+	expr := mustParse(t, "08:00")
+
+	for _, day := range []time.Time{
+		time.Date(2025, 3, 30, 0, 0, 0, 0, loc),
+		time.Date(2025, 10, 26, 0, 0, 0, 0, loc),
+	} {
+		got, ok := e.ComputeNextOccurrence(expr, day)
+		if !ok {
+			t.Fatalf("expected an occurrence on %s", day.Format("2006-01-02"))
+		}
+		local := got.In(loc)
+		if local.Hour() != 8 || local.Minute() != 0 || local.Day() != day.Day() {
+			t.Errorf("next = %v, want 08:00 on %s", local, day.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestEvaluate_PolarFallback(t *testing.T) {
+	expr := mustParse(t, "@sunset + 30m")
+	date := time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+	polar := &geo.AstroTimes{Sunset: utc(2025, 6, 21, 23, 59), PolarDay: true} // sun never sets
+
+	if _, ok := expr.Evaluate(date, polar, time.UTC); ok {
+		t.Fatal("expected skip without a fallback")
+	}
+
+	if err := expr.SetPolarFallback("22:00"); err != nil {
+		t.Fatalf("SetPolarFallback error = %v", err)
+	}
+	got, ok := expr.Evaluate(date, polar, time.UTC)
+	if !ok {
+		t.Fatal("expected fallback occurrence")
+	}
+	if want := utc(2025, 6, 21, 22, 0); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Normal days keep using the astronomical time
+	astro := &geo.AstroTimes{Sunset: utc(2025, 6, 21, 19, 0)}
+	got, _ = expr.Evaluate(date, astro, time.UTC)
+	if want := utc(2025, 6, 21, 19, 30); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEvaluate_PolarFallbackWithCalculator(t *testing.T) {
+	// Tromsø: midnight sun around the June solstice, polar night in December
+	calc := geo.NewCalculatorWithLocation("Tromsø", 69.65, 18.96, "UTC")
+	astroOn := func(date time.Time) *geo.AstroTimes {
+		t.Helper()
+		astro, err := calc.GetTimes("", date, "UTC")
+		if err != nil {
+			t.Fatalf("GetTimes error = %v", err)
+		}
+		return astro
+	}
+
+	june := time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+	for _, raw := range []string{"@sunset", "@dusk"} {
+		expr := mustParse(t, raw)
+		if err := expr.SetPolarFallback("22:00"); err != nil {
+			t.Fatalf("SetPolarFallback error = %v", err)
+		}
+		got, ok := expr.Evaluate(june, astroOn(june), time.UTC)
+		if !ok {
+			t.Fatalf("%s: expected fallback occurrence", raw)
+		}
+		if want := utc(2025, 6, 21, 22, 0); !got.Equal(want) {
+			t.Errorf("%s: got %v, want %v", raw, got, want)
+		}
+	}
+
+	// In polar night the sun never rises, but civil dawn still happens
+	december := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+	astro := astroOn(december)
+	sunrise := mustParse(t, "@sunrise")
+	if _, ok := sunrise.Evaluate(december, astro, time.UTC); ok {
+		t.Error("@sunrise: expected skip during polar night")
+	}
+	dawn := mustParse(t, "@dawn")
+	if err := dawn.SetPolarFallback("08:00"); err != nil {
+		t.Fatalf("SetPolarFallback error = %v", err)
+	}
+	got, ok := dawn.Evaluate(december, astro, time.UTC)
+	if !ok || !got.Equal(astro.Dawn) {
+		t.Errorf("@dawn: got %v (ok=%v), want civil dawn %v", got, ok, astro.Dawn)
+	}
+}
+
+func TestSetPolarFallback_Rejects(t *testing.T) {
+	if err := mustParse(t, "07:00").SetPolarFallback("22:00"); err == nil {
+		t.Error("expected error for fixed-time schedule")
+	}
+	if err := mustParse(t, "@sunset").SetPolarFallback("@dusk"); err == nil {
+		t.Error("expected error for astronomical fallback")
+	}
+}
+
+func TestParseTimeExpr_Fixed(t *testing.T) {
+	tests := []struct {
+		expr    string
+		hour    int
+		min     int
+		sec     int
+		wantErr bool
+	}{
+		{expr: "06:30", hour: 6, min: 30},
+		{expr: "6:05", hour: 6, min: 5},
+		{expr: "06:30:15", hour: 6, min: 30, sec: 15},
+		{expr: "23:59:59", hour: 23, min: 59, sec: 59},
+		{expr: "00:00:00"},
+		{expr: "06:30:60", wantErr: true},
+		{expr: "06:60", wantErr: true},
+		{expr: "24:00", wantErr: true},
+		{expr: "06:30:5", wantErr: true},
+		{expr: "06:30:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseTimeExpr(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTimeExpr(%q) = %+v, want error", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeExpr(%q) error = %v", tt.expr, err)
+			}
+			if got.BaseTime != BaseTimeFixed || got.FixedHour != tt.hour || got.FixedMin != tt.min || got.FixedSec != tt.sec {
+				t.Errorf("ParseTimeExpr(%q) = %02d:%02d:%02d, want %02d:%02d:%02d",
+					tt.expr, got.FixedHour, got.FixedMin, got.FixedSec, tt.hour, tt.min, tt.sec)
+			}
+			if got.Raw != tt.expr {
+				t.Errorf("Raw = %q, want %q", got.Raw, tt.expr)
+			}
+		})
+	}
+}
+
+func TestFormatScheduleForDay_Seconds(t *testing.T) {
+	s := &Scheduler{schedules: make(map[string]Schedule), tz: time.UTC}
+	eval := &FixedTimeEvaluator{tz: time.UTC}
+	for id, at := range map[string]string{"precise": "06:30:15", "plain": "07:45"} {
+		d, err := NewDailySchedule(id, at, "act", nil, "", MisfirePolicySkip, eval)
+		if err != nil {
+			t.Fatalf("NewDailySchedule(%q): %v", at, err)
+		}
+		s.schedules[id] = d
+	}
+
+	out := s.FormatScheduleForDay(utc(2025, 6, 1, 0, 0))
+	for _, want := range []string{"06:30:15", "07:45:00"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("FormatScheduleForDay() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseTimeExpr_Offsets(t *testing.T) {
+	tests := []struct {
+		expr    string
+		base    BaseTimeType
+		offset  time.Duration
+		wantErr bool
+	}{
+		// Single offsets
+		{expr: "@dawn", base: BaseTimeDawn},
+		{expr: "@noon + 30m", base: BaseTimeNoon, offset: 30 * time.Minute},
+		{expr: "@sunrise - 1h30m", base: BaseTimeSunrise, offset: -90 * time.Minute},
+		{expr: "@dusk+45s", base: BaseTimeDusk, offset: 45 * time.Second},
+		{expr: "@noon + 2h30m", base: BaseTimeNoon, offset: 150 * time.Minute},
+
+		// Compound offsets are summed
+		{expr: "@sunset + 1h - 15m", base: BaseTimeSunset, offset: 45 * time.Minute},
+		{expr: "@sunset - 30m + 5m", base: BaseTimeSunset, offset: -25 * time.Minute},
+		{expr: "@sunrise+1h-1h", base: BaseTimeSunrise},
+
+		// Rejected forms
+		{expr: "@sunset +", wantErr: true},
+		{expr: "@sunset + -1h", wantErr: true},
+		{expr: "@sunset + 1h -", wantErr: true},
+		{expr: "@sunset 1h", wantErr: true},
+		{expr: "@midnight + 1h", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ParseTimeExpr(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTimeExpr(%q) = %+v, want error", tt.expr, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeExpr(%q) error = %v", tt.expr, err)
+			}
+			if got.BaseTime != tt.base || got.Offset != tt.offset {
+				t.Errorf("ParseTimeExpr(%q) = base %v offset %v, want base %v offset %v",
+					tt.expr, got.BaseTime, got.Offset, tt.base, tt.offset)
+			}
+			if got.Raw != tt.expr {
+				t.Errorf("Raw = %q, want %q byte-for-byte", got.Raw, tt.expr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package storage
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -13,9 +14,15 @@ type DB struct {
 	*sql.DB
 }
 
-// Open opens the database and initializes the schema
-func Open(dbPath string) (*DB, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL")
+// Open opens the database and initializes the schema.
+// Every pooled connection runs with journal_mode=WAL, synchronous=NORMAL and the
+// given busy_timeout, so concurrent writers (ledger retention, geocache, KV)
+// wait for the lock instead of failing with "database is locked".
+func Open(dbPath string, busyTimeout time.Duration) (*DB, error) {
+	dsn := fmt.Sprintf("%s?_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=%d",
+		dbPath, busyTimeout.Milliseconds())
+
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -77,7 +84,7 @@ func initSchema(db *sql.DB) error {
 			id TEXT NOT NULL,
 			payload TEXT NOT NULL,
 			version INTEGER DEFAULT 1,
-			updated_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL, -- Unix nanoseconds
 			PRIMARY KEY (kind, id)
 		);
 		CREATE INDEX IF NOT EXISTS idx_resource_state_kind ON resource_state(kind);
@@ -61,3 +61,51 @@ func (c *GeoCache) Put(query string, loc *CachedLocation) error {
 	log.Info().Str("query", query).Float64("lat", loc.Latitude).Float64("lon", loc.Longitude).Msg("Geocache stored")
 	return nil
 }
+
+// GeoCacheEntry is a cached location together with its lookup key.
+type GeoCacheEntry struct {
+	Query string
+	CachedLocation
+	CreatedAt time.Time
+}
+
+// List returns all cached locations ordered by query.
+func (c *GeoCache) List() ([]GeoCacheEntry, error) {
+	rows, err := c.db.Query(`
+		SELECT query, display_name, latitude, longitude, created_at
+		FROM geocache
+		ORDER BY query
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []GeoCacheEntry
+	for rows.Next() {
+		var e GeoCacheEntry
+		var createdAt int64
+		if err := rows.Scan(&e.Query, &e.Name, &e.Latitude, &e.Longitude, &createdAt); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = time.Unix(createdAt, 0)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Clear removes the cached location for query, or every entry if query is empty.
+// Returns the number of entries removed.
+func (c *GeoCache) Clear(query string) (int64, error) {
+	var result sql.Result
+	var err error
+	if query == "" {
+		result, err = c.db.Exec(`DELETE FROM geocache`)
+	} else {
+		result, err = c.db.Exec(`DELETE FROM geocache WHERE query = ?`, query)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -11,9 +12,10 @@ import (
 type EventType string
 
 const (
-	EventActionCompleted EventType = "action_completed"
-	EventActionFailed    EventType = "action_failed"
-	EventScheduleFired   EventType = "schedule_fired"
+	EventActionCompleted  EventType = "action_completed"
+	EventActionFailed     EventType = "action_failed"
+	EventActionSuppressed EventType = "action_suppressed"
+	EventScheduleFired    EventType = "schedule_fired"
 )
 
 // Entry represents a single event in the ledger
@@ -27,6 +29,19 @@ type Entry struct {
 	DefID          string // For schedule-related events only
 }
 
+// Duration returns how long the action took, from the duration_ms payload
+// field of action_completed entries. ok is false for entries without it,
+// including completions recorded before the field was added.
+func (e *Entry) Duration() (d time.Duration, ok bool) {
+	switch ms := e.Payload["duration_ms"].(type) {
+	case float64: // decoded from JSON
+		return time.Duration(ms) * time.Millisecond, true
+	case int64:
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	return 0, false
+}
+
 // Ledger provides append-only event logging with deduplication
 type Ledger struct {
 	db *sql.DB
@@ -87,6 +102,64 @@ func (l *Ledger) HasCompleted(idempotencyKey string) bool {
 	return err == nil && exists == 1
 }
 
+// HasFiredSince checks if an occurrence with the given idempotency_key was
+// emitted by the scheduler at or after since
+func (l *Ledger) HasFiredSince(idempotencyKey string, since time.Time) bool {
+	if idempotencyKey == "" {
+		return false
+	}
+
+	var exists int
+	err := l.db.QueryRow(`
+		SELECT 1 FROM event_ledger
+		WHERE idempotency_key = ? AND event_type = ? AND timestamp >= ?
+		LIMIT 1
+	`, idempotencyKey, string(EventScheduleFired), since.Unix()).Scan(&exists)
+
+	return err == nil && exists == 1
+}
+
+// LastCompletedForDef returns the time of the most recent successful completion
+// recorded for a definition ID. Returns false if there is none.
+func (l *Ledger) LastCompletedForDef(defID string) (time.Time, bool) {
+	var ts sql.NullInt64
+	err := l.db.QueryRow(`
+		SELECT MAX(timestamp) FROM event_ledger
+		WHERE def_id = ? AND event_type = ?
+	`, defID, string(EventActionCompleted)).Scan(&ts)
+	if err != nil || !ts.Valid {
+		return time.Time{}, false
+	}
+	return time.Unix(ts.Int64, 0), true
+}
+
+// FiredForDefSince reports whether a definition fired at or after since:
+// a schedule_fired event, or a successful completion from one of sources.
+func (l *Ledger) FiredForDefSince(defID string, since time.Time, sources ...string) bool {
+	query := `
+		SELECT 1 FROM event_ledger
+		WHERE def_id = ? AND timestamp >= ?
+		AND (event_type = ? OR (event_type = ? AND source IN (` + placeholders(len(sources)) + `)))
+		LIMIT 1
+	`
+	args := []any{defID, since.Unix(), string(EventScheduleFired), string(EventActionCompleted)}
+	for _, src := range sources {
+		args = append(args, src)
+	}
+
+	var exists int
+	err := l.db.QueryRow(query, args...).Scan(&exists)
+	return err == nil && exists == 1
+}
+
+// placeholders returns n comma-separated SQL placeholders ("NULL" for n == 0).
+func placeholders(n int) string {
+	if n == 0 {
+		return "NULL"
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
 // GetByType returns entries filtered by event type
 func (l *Ledger) GetByType(eventType EventType, limit int) ([]*Entry, error) {
 	rows, err := l.db.Query(`
@@ -2,22 +2,63 @@ package storage
 
 import (
 	"database/sql"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+// querier is the subset of *sql.DB and *sql.Tx used by Store.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 // Store provides generic versioned state storage with JSON payloads.
 // State is keyed by (kind, id) and stored as JSON blobs with version tracking.
 type Store struct {
-	db *sql.DB
-	mu sync.RWMutex
+	db   querier
+	conn *sql.DB // nil for stores bound to a transaction
+	mu   *sync.RWMutex
 }
 
 // NewStore creates a new generic state store.
 func NewStore(db *sql.DB) *Store {
-	return &Store{db: db}
+	return &Store{db: db, conn: db, mu: &sync.RWMutex{}}
+}
+
+// WithTx runs fn against a Store bound to a single transaction.
+// If fn returns an error, every write made through the transactional store is
+// rolled back; otherwise all of them are committed together.
+// Other Store operations block until the transaction finishes.
+func (s *Store) WithTx(fn func(tx *Store) error) error {
+	if s.conn == nil {
+		return fmt.Errorf("nested transactions are not supported")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// The outer lock is held, so the tx store gets its own uncontended mutex
+	if err := fn(&Store{db: tx, mu: &sync.RWMutex{}}); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Error().Err(rbErr).Msg("Failed to roll back state transaction")
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
 
 // Get retrieves payload and version for a resource.
@@ -42,13 +83,32 @@ func (s *Store) Get(kind, id string) (payload []byte, version int64, err error)
 	return []byte(payloadStr), version, nil
 }
 
+// lastWrite is the last updated_at stamp handed out by writeStamp.
+var lastWrite atomic.Int64
+
+// writeStamp returns the updated_at value for a write: Unix nanoseconds,
+// strictly increasing within the process so writes in the same clock tick
+// still order by when they were made.
+func writeStamp() int64 {
+	for {
+		last := lastWrite.Load()
+		now := time.Now().UnixNano()
+		if now <= last {
+			now = last + 1
+		}
+		if lastWrite.CompareAndSwap(last, now) {
+			return now
+		}
+	}
+}
+
 // Set stores payload, incrementing version automatically.
 // Creates new entry if not exists, updates if exists.
 func (s *Store) Set(kind, id string, payload []byte) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	now := time.Now().UTC().Unix()
+	now := writeStamp()
 
 	_, err := s.db.Exec(`
 		INSERT INTO resource_state (kind, id, payload, version, updated_at)
@@ -159,3 +219,31 @@ func (s *Store) GetAll(kind string) (map[string][]byte, map[string]int64, error)
 
 	return payloads, versions, rows.Err()
 }
+
+// GetUpdatedAt returns when each entry of a kind was last written. Entries
+// written in the same second keep their write order; rows stamped in whole
+// seconds by older versions sort before all newer writes.
+func (s *Store) GetUpdatedAt(kind string) (map[string]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.Query(`
+		SELECT id, updated_at FROM resource_state WHERE kind = ?
+	`, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var updatedAt int64
+		if err := rows.Scan(&id, &updatedAt); err != nil {
+			return nil, err
+		}
+		result[id] = time.Unix(0, updatedAt)
+	}
+
+	return result, rows.Err()
+}
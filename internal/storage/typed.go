@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // TypedStore wraps Store with JSON marshaling for a specific type.
@@ -25,6 +26,17 @@ func (s *TypedStore[T]) Kind() string {
 	return s.kind
 }
 
+// Bind returns a typed store of the same kind backed by store,
+// typically a transactional store obtained from Store.WithTx.
+func (s *TypedStore[T]) Bind(store *Store) *TypedStore[T] {
+	return NewTypedStore[T](store, s.kind)
+}
+
+// Base returns the underlying generic store.
+func (s *TypedStore[T]) Base() *Store {
+	return s.store
+}
+
 // Get retrieves and unmarshals the state for an ID.
 // Returns zero value and version 0 if not found.
 func (s *TypedStore[T]) Get(id string) (value T, version int64, err error) {
@@ -88,6 +100,11 @@ func (s *TypedStore[T]) GetAll() (map[string]T, map[string]int64, error) {
 	return values, versions, nil
 }
 
+// GetUpdatedAt returns when each entry of this kind was last written.
+func (s *TypedStore[T]) GetUpdatedAt() (map[string]time.Time, error) {
+	return s.store.GetUpdatedAt(s.kind)
+}
+
 // Update applies a modification function to the current state.
 // If the ID doesn't exist, the modify function receives the zero value.
 func (s *TypedStore[T]) Update(id string, modify func(current T) T) error {
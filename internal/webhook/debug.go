@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/dokzlo13/lightd/internal/events"
+)
+
+// DebugEmitPath is the endpoint that injects synthetic events when enabled
+const DebugEmitPath = "/debug/emit"
+
+// debugEmitTypes are the event types that can be injected via DebugEmitPath
+var debugEmitTypes = map[events.EventType]bool{
+	events.EventTypeButton:       true,
+	events.EventTypeRotary:       true,
+	events.EventTypeConnectivity: true,
+	events.EventTypeLightChange:  true,
+}
+
+// debugEmitRequest is the body of a DebugEmitPath request:
+//
+//	{"type": "button", "data": {"resource_id": "...", "action": "short_release"}}
+type debugEmitRequest struct {
+	Type events.EventType `json:"type"`
+	Data map[string]any   `json:"data"`
+}
+
+// EnableDebugEmit registers DebugEmitPath on the next Run. Injected events go
+// through the same bus and handlers as real Hue events, so they can change lights.
+func (s *Server) EnableDebugEmit() {
+	s.debugEmit = true
+}
+
+// handleDebugEmit publishes a synthetic event to the bus.
+func (s *Server) handleDebugEmit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":"use POST"}`))
+		return
+	}
+
+	var req debugEmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid JSON body"}`))
+		return
+	}
+	if !debugEmitTypes[req.Type] {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error": fmt.Sprintf("unsupported event type %q (button, rotary, connectivity, light_change)", req.Type),
+		})
+		return
+	}
+	if req.Data == nil {
+		req.Data = make(map[string]any)
+	}
+
+	normalizeDebugEvent(req.Type, req.Data)
+
+	log.Info().
+		Str("event_type", string(req.Type)).
+		Interface("data", req.Data).
+		Msg("Emitting synthetic debug event")
+
+	s.bus.Publish(events.Event{Type: req.Type, Data: req.Data})
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"emitted"}`))
+}
+
+// normalizeDebugEvent converts JSON-decoded values to the types the event
+// stream produces, so handlers see the same shapes as for real events.
+func normalizeDebugEvent(eventType events.EventType, data map[string]any) {
+	switch eventType {
+	case events.EventTypeButton:
+		if _, ok := data["event_id"]; !ok {
+			data["event_id"] = fmt.Sprintf("debug-%d", time.Now().UnixNano())
+		}
+	case events.EventTypeRotary:
+		toInt(data, "steps")
+	case events.EventTypeLightChange:
+		toInt(data, "color_temp_mirek")
+		if _, ok := data["resource_type"]; !ok {
+			data["resource_type"] = "light"
+		}
+	}
+}
+
+// toInt converts a JSON number field to int in place.
+func toInt(data map[string]any, key string) {
+	if n, ok := data[key].(float64); ok {
+		data[key] = int(n)
+	}
+}
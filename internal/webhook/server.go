@@ -18,12 +18,32 @@ type PathMatcher interface {
 	HasMatch(method, path string) bool
 }
 
+// Response is an HTTP response produced by a webhook handler
+type Response struct {
+	Status      int
+	ContentType string // empty when Body is empty
+	Body        []byte
+}
+
+// Responder answers requests whose handler produces the response itself.
+// The request map has the same fields as the published webhook event.
+// ok is false if the request has no such handler.
+type Responder interface {
+	Respond(ctx context.Context, request map[string]interface{}) (resp Response, ok bool)
+}
+
+// respondTimeout bounds how long a request waits for its handler's response
+const respondTimeout = 10 * time.Second
+
 // Server is an HTTP server that receives webhooks and publishes events to the bus.
+// Requests answered by the Responder are not published.
 type Server struct {
 	addr        string
 	bus         *events.Bus
 	httpServer  *http.Server
 	pathMatcher PathMatcher
+	responder   Responder
+	debugEmit   bool // serve DebugEmitPath
 }
 
 // NewServer creates a new webhook server.
@@ -40,13 +60,31 @@ func (s *Server) SetPathMatcher(matcher PathMatcher) {
 	s.pathMatcher = matcher
 }
 
-// Run starts the webhook server. It blocks until the context is cancelled.
-func (s *Server) Run(ctx context.Context, shutdownTimeout time.Duration) error {
-	mux := http.NewServeMux()
+// SetResponder sets the responder for handlers that return a response body.
+// Must be called before Run().
+func (s *Server) SetResponder(responder Responder) {
+	s.responder = responder
+}
 
+// Register mounts the webhook handlers on mux. Webhooks are served by a
+// catch-all pattern, so more specific patterns on the same mux (e.g. health
+// endpoints) take precedence over webhook paths.
+func (s *Server) Register(mux *http.ServeMux) {
 	// Catch-all handler for all webhook requests
 	mux.HandleFunc("/", s.handleWebhook)
 
+	// Synthetic event injection for testing handlers (opt-in)
+	if s.debugEmit {
+		mux.HandleFunc(DebugEmitPath, s.handleDebugEmit)
+		log.Warn().Str("path", DebugEmitPath).Msg("Debug event injection enabled")
+	}
+}
+
+// Run starts the webhook server. It blocks until the context is cancelled.
+func (s *Server) Run(ctx context.Context, shutdownTimeout time.Duration) error {
+	mux := http.NewServeMux()
+	s.Register(mux)
+
 	s.httpServer = &http.Server{
 		Addr:    s.addr,
 		Handler: mux,
@@ -126,17 +164,32 @@ func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
 		Str("event_id", eventID).
 		Msg("Received webhook request")
 
+	request := map[string]interface{}{
+		"method":   r.Method,
+		"path":     r.URL.Path,
+		"body":     string(body),
+		"json":     jsonBody,
+		"headers":  headers,
+		"event_id": eventID,
+	}
+
+	if s.responder != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), respondTimeout)
+		defer cancel()
+		if resp, ok := s.responder.Respond(ctx, request); ok {
+			if resp.ContentType != "" {
+				w.Header().Set("Content-Type", resp.ContentType)
+			}
+			w.WriteHeader(resp.Status)
+			w.Write(resp.Body)
+			return
+		}
+	}
+
 	// Publish event to bus
 	s.bus.Publish(events.Event{
 		Type: events.EventTypeWebhook,
-		Data: map[string]interface{}{
-			"method":   r.Method,
-			"path":     r.URL.Path,
-			"body":     string(body),
-			"json":     jsonBody,
-			"headers":  headers,
-			"event_id": eventID,
-		},
+		Data: request,
 	})
 
 	// Respond with 200 OK - request accepted